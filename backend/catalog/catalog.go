@@ -0,0 +1,174 @@
+package catalog
+
+import (
+	"log"
+	"strings"
+	"time"
+
+	"gorm.io/gorm/clause"
+
+	"aimodels-prices/database"
+	"aimodels-prices/models"
+)
+
+const cacheKey = "model_catalog"
+
+// aliasKey 别名查找键，AnyChannel 表示适用于所有厂商
+type aliasKey struct {
+	channelType uint
+	alias       string
+}
+
+// AnyChannel 适用于所有厂商的别名使用的厂商ID
+const AnyChannel uint = 0
+
+// snapshot 模型目录的内存快照
+type snapshot struct {
+	models  map[string]models.Model
+	aliases map[aliasKey]string
+	byModel map[string][]models.ModelAlias
+}
+
+// load 从缓存获取模型目录快照，缓存不存在时从数据库加载
+func load() *snapshot {
+	if cachedData, found := database.GlobalCache.Get(cacheKey); found {
+		if s, ok := cachedData.(*snapshot); ok {
+			return s
+		}
+	}
+
+	s := &snapshot{
+		models:  make(map[string]models.Model),
+		aliases: make(map[aliasKey]string),
+		byModel: make(map[string][]models.ModelAlias),
+	}
+
+	var catalogModels []models.Model
+	if err := database.DB.Find(&catalogModels).Error; err != nil {
+		log.Printf("加载模型目录失败: %v", err)
+		return s
+	}
+	for _, m := range catalogModels {
+		s.models[strings.ToLower(m.ID)] = m
+	}
+
+	var aliases []models.ModelAlias
+	if err := database.DB.Find(&aliases).Error; err != nil {
+		log.Printf("加载模型别名失败: %v", err)
+		return s
+	}
+	for _, a := range aliases {
+		id := strings.ToLower(a.ModelID)
+		s.aliases[aliasKey{a.ChannelType, strings.ToLower(a.Alias)}] = id
+		s.byModel[id] = append(s.byModel[id], a)
+	}
+
+	database.GlobalCache.Set(cacheKey, s, 30*time.Minute)
+	return s
+}
+
+// Invalidate 清除模型目录缓存
+func Invalidate() {
+	database.GlobalCache.Delete(cacheKey)
+}
+
+// Normalize 去除厂商前缀并转为小写，如 anthropic/claude-3.5-sonnet -> claude-3.5-sonnet
+func Normalize(name string) string {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if idx := strings.LastIndex(name, "/"); idx >= 0 {
+		name = name[idx+1:]
+	}
+	return name
+}
+
+// lookup 依次按厂商别名、通用别名、规范ID查找
+func (s *snapshot) lookup(channelType uint, name string) (string, bool) {
+	if id, ok := s.aliases[aliasKey{channelType, name}]; ok {
+		return id, true
+	}
+	if id, ok := s.aliases[aliasKey{AnyChannel, name}]; ok {
+		return id, true
+	}
+	if _, ok := s.models[name]; ok {
+		return name, true
+	}
+	return "", false
+}
+
+// Resolve 将厂商内的模型名称解析为规范模型ID
+func Resolve(channelType uint, name string) (string, bool) {
+	s := load()
+
+	lower := strings.ToLower(strings.TrimSpace(name))
+	if id, ok := s.lookup(channelType, lower); ok {
+		return id, true
+	}
+
+	if normalized := Normalize(name); normalized != lower {
+		return s.lookup(channelType, normalized)
+	}
+	return "", false
+}
+
+// Get 获取规范模型
+func Get(id string) (models.Model, bool) {
+	m, ok := load().models[strings.ToLower(id)]
+	return m, ok
+}
+
+// Aliases 获取规范模型的所有别名
+func Aliases(id string) []models.ModelAlias {
+	return load().byModel[strings.ToLower(id)]
+}
+
+// Register 记录抓取任务遇到的模型名称
+// 名称没有精确别名、但去除厂商前缀后能匹配到规范模型时，自动为该厂商添加别名
+func Register(channelType uint, name, createdBy string) {
+	s := load()
+
+	lower := strings.ToLower(strings.TrimSpace(name))
+	if _, ok := s.lookup(channelType, lower); ok {
+		return
+	}
+
+	id, ok := Resolve(channelType, name)
+	if !ok {
+		return
+	}
+
+	modelID := id
+	if m, ok := s.models[id]; ok {
+		modelID = m.ID
+	}
+
+	alias := models.ModelAlias{
+		ChannelType: channelType,
+		Alias:       name,
+		ModelID:     modelID,
+		CreatedBy:   createdBy,
+	}
+	if err := database.DB.Clauses(clause.OnConflict{DoNothing: true}).Create(&alias).Error; err != nil {
+		log.Printf("添加模型别名失败 %s: %v", name, err)
+		return
+	}
+
+	log.Printf("添加模型别名: %s (厂商: %d) -> %s", name, channelType, alias.ModelID)
+	Invalidate()
+}
+
+// Names 获取规范模型ID及其所有别名名称，用于按名称查询价格
+func Names(id string) []string {
+	names := []string{id}
+	for _, a := range Aliases(id) {
+		names = append(names, a.Alias)
+	}
+	return names
+}
+
+// Key 获取模型在目录中的键：能解析时为规范模型ID，否则为规范化后的名称
+func Key(channelType uint, name string) string {
+	if id, ok := Resolve(channelType, name); ok {
+		return id
+	}
+	return Normalize(name)
+}
@@ -0,0 +1,134 @@
+package catalog
+
+import (
+	"log"
+
+	"gorm.io/gorm/clause"
+
+	"aimodels-prices/database"
+	"aimodels-prices/models"
+)
+
+const seedCreatedBy = "system"
+
+// 常用厂商ID
+const (
+	channelOpenAI      uint = 1
+	channelAnthropic   uint = 14
+	channelZhipu       uint = 16
+	channelAli         uint = 17
+	channelOpenRouter  uint = 20
+	channelGoogle      uint = 25
+	channelDeepSeek    uint = 28
+	channelMoonshot    uint = 29
+	channelSiliconFlow uint = 45
+)
+
+// seedModel 内置的规范模型及其别名
+type seedModel struct {
+	Model   models.Model
+	Aliases []models.ModelAlias
+}
+
+func uintPtr(v uint) *uint {
+	return &v
+}
+
+// defaultModels 内置模型目录，取代原先抓取任务中硬编码的名称修正规则
+var defaultModels = []seedModel{
+	{
+		Model: models.Model{ID: "gemini-1.5-flash", DisplayName: "Gemini 1.5 Flash", Family: "gemini-1.5", Vendor: "google", VendorChannel: uintPtr(channelGoogle)},
+		Aliases: []models.ModelAlias{
+			{ChannelType: channelOpenRouter, Alias: "google/gemini-flash-1.5"},
+		},
+	},
+	{
+		Model: models.Model{ID: "gemini-1.5-flash-8b", DisplayName: "Gemini 1.5 Flash-8B", Family: "gemini-1.5", Vendor: "google", VendorChannel: uintPtr(channelGoogle)},
+		Aliases: []models.ModelAlias{
+			{ChannelType: channelOpenRouter, Alias: "google/gemini-flash-1.5-8b"},
+		},
+	},
+	{
+		Model: models.Model{ID: "claude-3-5-sonnet", DisplayName: "Claude 3.5 Sonnet", Family: "claude-3.5", Vendor: "anthropic", VendorChannel: uintPtr(channelAnthropic)},
+		Aliases: []models.ModelAlias{
+			{ChannelType: channelAnthropic, Alias: "claude-3-5-sonnet-20241022"},
+			{ChannelType: channelOpenRouter, Alias: "anthropic/claude-3.5-sonnet"},
+		},
+	},
+	{
+		Model: models.Model{ID: "claude-3-5-haiku", DisplayName: "Claude 3.5 Haiku", Family: "claude-3.5", Vendor: "anthropic", VendorChannel: uintPtr(channelAnthropic)},
+		Aliases: []models.ModelAlias{
+			{ChannelType: channelAnthropic, Alias: "claude-3-5-haiku-20241022"},
+			{ChannelType: channelOpenRouter, Alias: "anthropic/claude-3.5-haiku"},
+		},
+	},
+	{
+		Model: models.Model{ID: "claude-3-7-sonnet", DisplayName: "Claude 3.7 Sonnet", Family: "claude-3.7", Vendor: "anthropic", VendorChannel: uintPtr(channelAnthropic)},
+		Aliases: []models.ModelAlias{
+			{ChannelType: channelAnthropic, Alias: "claude-3-7-sonnet-20250219"},
+			{ChannelType: channelOpenRouter, Alias: "anthropic/claude-3.7-sonnet"},
+		},
+	},
+	{
+		Model: models.Model{ID: "gpt-4o", DisplayName: "GPT-4o", Family: "gpt-4o", Vendor: "openai", VendorChannel: uintPtr(channelOpenAI)},
+		Aliases: []models.ModelAlias{
+			{ChannelType: channelOpenRouter, Alias: "openai/gpt-4o"},
+		},
+	},
+	{
+		Model: models.Model{ID: "deepseek-v3", DisplayName: "DeepSeek-V3", Family: "deepseek-v3", Vendor: "deepseek", VendorChannel: uintPtr(channelDeepSeek)},
+		Aliases: []models.ModelAlias{
+			{ChannelType: channelDeepSeek, Alias: "deepseek-chat"},
+			{ChannelType: channelOpenRouter, Alias: "deepseek/deepseek-chat"},
+			{ChannelType: channelSiliconFlow, Alias: "deepseek-ai/DeepSeek-V3"},
+		},
+	},
+	{
+		Model: models.Model{ID: "deepseek-r1", DisplayName: "DeepSeek-R1", Family: "deepseek-r1", Vendor: "deepseek", VendorChannel: uintPtr(channelDeepSeek)},
+		Aliases: []models.ModelAlias{
+			{ChannelType: channelDeepSeek, Alias: "deepseek-reasoner"},
+			{ChannelType: channelOpenRouter, Alias: "deepseek/deepseek-r1"},
+			{ChannelType: channelSiliconFlow, Alias: "deepseek-ai/DeepSeek-R1"},
+		},
+	},
+	{
+		Model: models.Model{ID: "qwen-max", DisplayName: "Qwen-Max", Family: "qwen-max", Vendor: "alibaba", VendorChannel: uintPtr(channelAli)},
+		Aliases: []models.ModelAlias{
+			{ChannelType: channelOpenRouter, Alias: "qwen/qwen-max"},
+		},
+	},
+	{
+		Model: models.Model{ID: "glm-4-plus", DisplayName: "GLM-4-Plus", Family: "glm-4", Vendor: "zhipu", VendorChannel: uintPtr(channelZhipu)},
+	},
+	{
+		Model: models.Model{ID: "moonshot-v1-128k", DisplayName: "Moonshot v1 128K", Family: "moonshot-v1", Vendor: "moonshot", VendorChannel: uintPtr(channelMoonshot)},
+	},
+}
+
+// Seed 写入内置模型目录，已存在的模型和别名保持不变
+func Seed() error {
+	createdCount := 0
+	for _, seed := range defaultModels {
+		m := seed.Model
+		m.CreatedBy = seedCreatedBy
+		result := database.DB.Clauses(clause.OnConflict{DoNothing: true}).Create(&m)
+		if result.Error != nil {
+			return result.Error
+		}
+		createdCount += int(result.RowsAffected)
+
+		for _, alias := range seed.Aliases {
+			alias.ModelID = m.ID
+			alias.CreatedBy = seedCreatedBy
+			if err := database.DB.Clauses(clause.OnConflict{DoNothing: true}).Create(&alias).Error; err != nil {
+				return err
+			}
+		}
+	}
+
+	if createdCount > 0 {
+		log.Printf("已写入 %d 个内置模型目录", createdCount)
+		Invalidate()
+	}
+	return nil
+}
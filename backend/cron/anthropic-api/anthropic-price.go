@@ -0,0 +1,270 @@
+package anthropic_api
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"aimodels-prices/cron/htmlutil"
+	"aimodels-prices/cron/scraper"
+	"aimodels-prices/httpclient"
+	"aimodels-prices/models"
+
+	"golang.org/x/net/html"
+)
+
+const (
+	AnthropicPricingURL  = "https://docs.claude.com/en/docs/about-claude/pricing"
+	AnthropicChannelType = 14
+	BillingType          = "tokens"
+	Currency             = "USD"
+	PriceSource          = "https://docs.claude.com/en/docs/about-claude/pricing"
+)
+
+// AnthropicModelPrice 从页面解析出的模型价格数据，价格单位均为 $/1M tokens
+type AnthropicModelPrice struct {
+	Model            string
+	InputPrice       float64
+	OutputPrice      float64
+	CacheWritePrice  float64 // 5分钟缓存写入
+	CacheReadPrice   float64 // 缓存命中和刷新
+	BatchInputPrice  float64
+	BatchOutputPrice float64
+}
+
+// Source Anthropic官网价格来源，价格自动审核通过
+type Source struct{}
+
+// Name 来源名称
+func (Source) Name() string { return "anthropic" }
+
+// ChannelType 厂商ID
+func (Source) ChannelType() uint { return AnthropicChannelType }
+
+// Approval 默认审核策略
+func (Source) Approval() scraper.ApprovalPolicy { return scraper.ApproveAll }
+
+// Fetch 抓取定价页面并转换为价格记录
+func (Source) Fetch(ctx context.Context) ([]scraper.ScrapedPrice, error) {
+	prices, err := fetchAnthropicPrices(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]scraper.ScrapedPrice, 0, len(prices))
+	for _, mp := range prices {
+		result = append(result, scraper.ScrapedPrice{Price: buildPrice(mp)})
+	}
+	return result, nil
+}
+
+// buildPrice 将解析结果转换为价格记录，批量API折扣以价格调整规则表示
+func buildPrice(mp AnthropicModelPrice) models.Price {
+	price := models.Price{
+		Model:       mp.Model,
+		BillingType: BillingType,
+		ChannelType: AnthropicChannelType,
+		Currency:    Currency,
+		InputPrice:  mp.InputPrice,
+		OutputPrice: mp.OutputPrice,
+		PriceSource: PriceSource,
+	}
+
+	if mp.CacheWritePrice > 0 {
+		cacheWrite := mp.CacheWritePrice
+		price.CachedWriteTokens = &cacheWrite
+	}
+	if mp.CacheReadPrice > 0 {
+		cacheRead := mp.CacheReadPrice
+		price.CachedReadTokens = &cacheRead
+	}
+
+	if mp.BatchInputPrice > 0 && mp.BatchOutputPrice > 0 && mp.InputPrice > 0 && mp.OutputPrice > 0 {
+		price.Modifiers = models.PriceModifiers{{
+			Kind:             models.ModifierBatch,
+			InputMultiplier:  roundPrice(mp.BatchInputPrice / mp.InputPrice),
+			OutputMultiplier: roundPrice(mp.BatchOutputPrice / mp.OutputPrice),
+		}}
+	}
+
+	return price
+}
+
+// fetchAnthropicPrices 抓取Anthropic定价页面并解析价格表格
+func fetchAnthropicPrices(ctx context.Context) ([]AnthropicModelPrice, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", AnthropicPricingURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("创建HTTP请求失败: %v", err)
+	}
+	body, err := httpclient.For("anthropic").Fetch(req)
+	if err != nil {
+		return nil, fmt.Errorf("请求Anthropic定价页面失败: %v", err)
+	}
+
+	return parseHTMLPrices(string(body))
+}
+
+// parseHTMLPrices 从HTML中解析价格表格
+// 模型价格表包含 Model/Base Input Tokens/Cache Writes/Cache Hits/Output Tokens 列，
+// 批量处理表包含 Model/Batch input/Batch output 列
+func parseHTMLPrices(htmlContent string) ([]AnthropicModelPrice, error) {
+	doc, err := html.Parse(strings.NewReader(htmlContent))
+	if err != nil {
+		return nil, fmt.Errorf("解析HTML失败: %v", err)
+	}
+
+	var prices []AnthropicModelPrice
+	index := make(map[string]int)
+	type batchPrice struct{ input, output float64 }
+	batch := make(map[string]batchPrice)
+
+	for _, table := range htmlutil.Tables(doc) {
+		modelCol := table.Column("model")
+		if modelCol != 0 {
+			continue
+		}
+
+		// 批量处理价格表
+		if batchIn, batchOut := table.Column("batch input"), table.Column("batch output"); batchIn >= 0 && batchOut >= 0 {
+			for _, row := range table.Rows {
+				if len(row) <= batchIn || len(row) <= batchOut {
+					continue
+				}
+				model := modelID(row[modelCol])
+				input, err1 := parseMTokPrice(row[batchIn])
+				output, err2 := parseMTokPrice(row[batchOut])
+				if model == "" || err1 != nil || err2 != nil {
+					continue
+				}
+				batch[model] = batchPrice{input, output}
+			}
+			continue
+		}
+
+		// 模型基础价格表
+		inputCol := table.Column("base input")
+		outputCol := table.Column("output")
+		if inputCol < 0 || outputCol < 0 {
+			continue
+		}
+		writeCol := table.Column("5m cache write", "cache write")
+		readCol := table.Column("cache hit")
+
+		for _, row := range table.Rows {
+			if len(row) <= outputCol || len(row) <= inputCol {
+				continue
+			}
+			model := modelID(row[modelCol])
+			if model == "" {
+				continue
+			}
+			if _, exists := index[model]; exists {
+				continue
+			}
+
+			input, err := parseMTokPrice(row[inputCol])
+			if err != nil {
+				log.Printf("解析输入价格失败 %s: %v", model, err)
+				continue
+			}
+			output, err := parseMTokPrice(row[outputCol])
+			if err != nil {
+				log.Printf("解析输出价格失败 %s: %v", model, err)
+				continue
+			}
+
+			mp := AnthropicModelPrice{Model: model, InputPrice: input, OutputPrice: output}
+			if writeCol >= 0 && writeCol < len(row) {
+				mp.CacheWritePrice, _ = parseMTokPrice(row[writeCol])
+			}
+			if readCol >= 0 && readCol < len(row) {
+				mp.CacheReadPrice, _ = parseMTokPrice(row[readCol])
+			}
+
+			index[model] = len(prices)
+			prices = append(prices, mp)
+		}
+	}
+
+	if len(prices) == 0 {
+		return nil, fmt.Errorf("未找到Anthropic模型价格表格")
+	}
+
+	for model, bp := range batch {
+		if i, ok := index[model]; ok {
+			prices[i].BatchInputPrice = bp.input
+			prices[i].BatchOutputPrice = bp.output
+		}
+	}
+
+	return prices, nil
+}
+
+var (
+	// 去除模型名称中的说明，如 "(deprecated)"、脚注标记
+	modelNoteRegex = regexp.MustCompile(`\([^)]*\)|\[[^\]]*\]`)
+	// 匹配 "Claude Opus 4.1"、"Claude Haiku 3.5"、"Claude Opus 3"
+	modelNameRegex = regexp.MustCompile(`(?i)^claude\s+(opus|sonnet|haiku)\s+(\d+)(?:\.(\d+))?$`)
+)
+
+// modelIDs 页面上的模型名称到API模型ID的映射，API模型ID带发布日期，无法由名称推导
+// 新模型上线时需在此添加
+var modelIDs = map[string]string{
+	"opus 4.5":   "claude-opus-4-5-20251101",
+	"opus 4.1":   "claude-opus-4-1-20250805",
+	"opus 4":     "claude-opus-4-20250514",
+	"sonnet 4.5": "claude-sonnet-4-5-20250929",
+	"sonnet 4":   "claude-sonnet-4-20250514",
+	"haiku 4.5":  "claude-haiku-4-5-20251001",
+	"sonnet 3.7": "claude-3-7-sonnet-20250219",
+	"sonnet 3.5": "claude-3-5-sonnet-20241022",
+	"haiku 3.5":  "claude-3-5-haiku-20241022",
+	"opus 3":     "claude-3-opus-20240229",
+	"sonnet 3":   "claude-3-sonnet-20240229",
+	"haiku 3":    "claude-3-haiku-20240307",
+}
+
+// modelID 将页面上的模型名称（如 "Claude Opus 4.1"）转换为API模型ID，未知的模型返回空字符串
+func modelID(name string) string {
+	name = strings.TrimSpace(modelNoteRegex.ReplaceAllString(name, ""))
+	matches := modelNameRegex.FindStringSubmatch(name)
+	if matches == nil {
+		return ""
+	}
+
+	key := strings.ToLower(matches[1]) + " " + matches[2]
+	if matches[3] != "" {
+		key += "." + matches[3]
+	}
+	id, ok := modelIDs[key]
+	if !ok {
+		log.Printf("未知的Anthropic模型 %s，需在modelIDs中添加API模型ID", name)
+	}
+	return id
+}
+
+// parseMTokPrice 解析价格字符串，如 "$3 / MTok" -> 3 (per 1M tokens)
+func parseMTokPrice(s string) (float64, error) {
+	s = strings.TrimSpace(s)
+	if i := strings.Index(s, "/"); i >= 0 {
+		s = s[:i]
+	}
+	s = strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(s), "$"))
+	s = strings.ReplaceAll(s, ",", "")
+
+	price, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, fmt.Errorf("无法解析价格: %s", s)
+	}
+	return roundPrice(price), nil
+}
+
+// roundPrice 四舍五入到6位小数
+func roundPrice(price float64) float64 {
+	return math.Round(price*1000000) / 1000000
+}
@@ -0,0 +1,80 @@
+package anthropic_api
+
+import (
+	"os"
+	"testing"
+
+	"aimodels-prices/models"
+)
+
+func TestParseHTMLPrices(t *testing.T) {
+	content, err := os.ReadFile("testdata/pricing.html")
+	if err != nil {
+		t.Fatalf("读取测试页面失败: %v", err)
+	}
+
+	prices, err := parseHTMLPrices(string(content))
+	if err != nil {
+		t.Fatalf("parseHTMLPrices 失败: %v", err)
+	}
+
+	want := map[string]AnthropicModelPrice{
+		"claude-opus-4-1-20250805":   {InputPrice: 15, OutputPrice: 75, CacheWritePrice: 18.75, CacheReadPrice: 1.5, BatchInputPrice: 7.5, BatchOutputPrice: 37.5},
+		"claude-sonnet-4-20250514":   {InputPrice: 3, OutputPrice: 15, CacheWritePrice: 3.75, CacheReadPrice: 0.3, BatchInputPrice: 1.5, BatchOutputPrice: 7.5},
+		"claude-3-7-sonnet-20250219": {InputPrice: 3, OutputPrice: 15, CacheWritePrice: 3.75, CacheReadPrice: 0.3},
+		"claude-3-5-haiku-20241022":  {InputPrice: 0.8, OutputPrice: 4, CacheWritePrice: 1, CacheReadPrice: 0.08, BatchInputPrice: 0.4, BatchOutputPrice: 2},
+		"claude-3-opus-20240229":     {InputPrice: 15, OutputPrice: 75, CacheWritePrice: 18.75, CacheReadPrice: 1.5},
+	}
+
+	if len(prices) != len(want) {
+		t.Fatalf("解析到 %d 个模型，期望 %d 个: %+v", len(prices), len(want), prices)
+	}
+	for _, p := range prices {
+		expected, ok := want[p.Model]
+		if !ok {
+			t.Errorf("解析到未预期的模型: %s", p.Model)
+			continue
+		}
+		expected.Model = p.Model
+		if p != expected {
+			t.Errorf("%s 价格错误: got %+v, want %+v", p.Model, p, expected)
+		}
+	}
+}
+
+func TestBuildPrice(t *testing.T) {
+	price := buildPrice(AnthropicModelPrice{
+		Model: "claude-sonnet-4-20250514", InputPrice: 3, OutputPrice: 15,
+		CacheWritePrice: 3.75, CacheReadPrice: 0.3, BatchInputPrice: 1.5, BatchOutputPrice: 7.5,
+	})
+
+	if price.CachedWriteTokens == nil || *price.CachedWriteTokens != 3.75 {
+		t.Errorf("缓存写入价格错误: %v", price.CachedWriteTokens)
+	}
+	if price.CachedReadTokens == nil || *price.CachedReadTokens != 0.3 {
+		t.Errorf("缓存读取价格错误: %v", price.CachedReadTokens)
+	}
+	if len(price.Modifiers) != 1 || price.Modifiers[0].Kind != models.ModifierBatch ||
+		price.Modifiers[0].InputMultiplier != 0.5 || price.Modifiers[0].OutputMultiplier != 0.5 {
+		t.Errorf("批量折扣错误: %+v", price.Modifiers)
+	}
+}
+
+func TestModelID(t *testing.T) {
+	tests := map[string]string{
+		"Claude Opus 4.1":                "claude-opus-4-1-20250805",
+		"Claude Sonnet 4":                "claude-sonnet-4-20250514",
+		"Claude Sonnet 3.7 (deprecated)": "claude-3-7-sonnet-20250219",
+		"Claude Haiku 3.5":               "claude-3-5-haiku-20241022",
+		"Claude Opus 3":                  "claude-3-opus-20240229",
+		"Claude Haiku 3":                 "claude-3-haiku-20240307",
+		// 未知的模型不能推导出错误的ID
+		"Claude Opus 9": "",
+		"Model":         "",
+	}
+	for name, want := range tests {
+		if got := modelID(name); got != want {
+			t.Errorf("modelID(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
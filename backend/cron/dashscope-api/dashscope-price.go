@@ -0,0 +1,177 @@
+package dashscope_api
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"aimodels-prices/cron/htmlutil"
+	"aimodels-prices/cron/scraper"
+	"aimodels-prices/httpclient"
+	"aimodels-prices/models"
+
+	"golang.org/x/net/html"
+)
+
+const (
+	DashScopePricingURL  = "https://help.aliyun.com/zh/model-studio/models"
+	DashScopeChannelType = 17
+	BillingType          = "tokens"
+	Currency             = "CNY"
+	PriceSource          = "https://help.aliyun.com/zh/model-studio/models"
+)
+
+// DashScopeModelPrice 从页面解析出的模型价格数据，价格单位均为 元/1M tokens
+type DashScopeModelPrice struct {
+	Model       string
+	InputPrice  float64 // 输入（缓存未命中）
+	OutputPrice float64
+	CachedPrice float64 // 输入（缓存命中）
+}
+
+// Source 阿里云百炼价格来源，已审核的模型自动更新，新模型进入待审核队列
+type Source struct{}
+
+// Name 来源名称
+func (Source) Name() string { return "dashscope" }
+
+// ChannelType 厂商ID
+func (Source) ChannelType() uint { return DashScopeChannelType }
+
+// Approval 默认审核策略
+func (Source) Approval() scraper.ApprovalPolicy { return scraper.ApproveKnown }
+
+// Fetch 抓取定价页面并转换为价格记录
+func (Source) Fetch(ctx context.Context) ([]scraper.ScrapedPrice, error) {
+	prices, err := fetchDashScopePrices(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]scraper.ScrapedPrice, 0, len(prices))
+	for _, mp := range prices {
+		result = append(result, scraper.ScrapedPrice{Price: buildPrice(mp)})
+	}
+	return result, nil
+}
+
+// fetchDashScopePrices 抓取阿里云百炼定价页面并解析价格表格
+func fetchDashScopePrices(ctx context.Context) ([]DashScopeModelPrice, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", DashScopePricingURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("创建HTTP请求失败: %v", err)
+	}
+	body, err := httpclient.For("dashscope").Fetch(req)
+	if err != nil {
+		return nil, fmt.Errorf("请求阿里云百炼定价页面失败: %v", err)
+	}
+
+	return parseHTMLPrices(string(body))
+}
+
+// buildPrice 将解析结果转换为价格记录
+func buildPrice(mp DashScopeModelPrice) models.Price {
+	price := models.Price{
+		Model:       mp.Model,
+		BillingType: BillingType,
+		ChannelType: DashScopeChannelType,
+		Currency:    Currency,
+		InputPrice:  mp.InputPrice,
+		OutputPrice: mp.OutputPrice,
+		PriceSource: PriceSource,
+	}
+
+	// 缓存命中价格
+	if mp.CachedPrice > 0 {
+		cached := mp.CachedPrice
+		price.CachedTokens = &cached
+	}
+
+	return price
+}
+
+// modelNameRegex 匹配模型名称，如 qwen-max、qwen2.5-72b-instruct
+var modelNameRegex = regexp.MustCompile(`^[a-z][a-z0-9.\-]*$`)
+
+// parseHTMLPrices 从HTML中解析价格表格
+// 价格表包含 模型名称/输入成本/输出成本 列，列头注明计价单位（每千Token或每百万Token），统一换算为每百万tokens
+func parseHTMLPrices(htmlContent string) ([]DashScopeModelPrice, error) {
+	doc, err := html.Parse(strings.NewReader(htmlContent))
+	if err != nil {
+		return nil, fmt.Errorf("解析HTML失败: %v", err)
+	}
+
+	var prices []DashScopeModelPrice
+	seen := make(map[string]bool)
+
+	for _, table := range htmlutil.Tables(doc) {
+		modelCol := table.Column("模型名称", "模型")
+		inputCol := table.Column("输入成本", "输入单价", "输入价格")
+		outputCol := table.Column("输出成本", "输出单价", "输出价格")
+		cachedCol := table.Column("缓存命中")
+		if modelCol < 0 || inputCol < 0 || outputCol < 0 {
+			continue
+		}
+
+		for _, row := range table.Rows {
+			if len(row) <= modelCol || len(row) <= inputCol || len(row) <= outputCol {
+				continue
+			}
+			fields := strings.Fields(row[modelCol])
+			if len(fields) == 0 {
+				continue
+			}
+			model := strings.ToLower(fields[0])
+			if !modelNameRegex.MatchString(model) || seen[model] {
+				continue
+			}
+
+			input, err := htmlutil.ParseAmount(row[inputCol])
+			if err != nil {
+				log.Printf("解析输入价格失败 %s: %v", model, err)
+				continue
+			}
+			output, err := htmlutil.ParseAmount(row[outputCol])
+			if err != nil {
+				log.Printf("解析输出价格失败 %s: %v", model, err)
+				continue
+			}
+
+			mp := DashScopeModelPrice{
+				Model:       model,
+				InputPrice:  roundPrice(input * unitScale(table.Headers[inputCol])),
+				OutputPrice: roundPrice(output * unitScale(table.Headers[outputCol])),
+			}
+			if cachedCol >= 0 && cachedCol < len(row) {
+				if cached, err := htmlutil.ParseAmount(row[cachedCol]); err == nil {
+					mp.CachedPrice = roundPrice(cached * unitScale(table.Headers[cachedCol]))
+				}
+			}
+
+			seen[model] = true
+			prices = append(prices, mp)
+		}
+	}
+
+	if len(prices) == 0 {
+		return nil, fmt.Errorf("未找到阿里云百炼模型价格表格")
+	}
+	return prices, nil
+}
+
+// unitScale 根据列头中的计价单位返回换算到每百万tokens的倍数
+func unitScale(header string) float64 {
+	if strings.Contains(header, "千") {
+		return 1000
+	}
+	return 1
+}
+
+// roundPrice 四舍五入到6位小数
+func roundPrice(price float64) float64 {
+	return math.Round(price*1000000) / 1000000
+}
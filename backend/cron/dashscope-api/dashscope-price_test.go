@@ -0,0 +1,32 @@
+package dashscope_api
+
+import (
+	"os"
+	"testing"
+)
+
+func TestParseHTMLPrices(t *testing.T) {
+	content, err := os.ReadFile("testdata/pricing.html")
+	if err != nil {
+		t.Fatalf("读取测试页面失败: %v", err)
+	}
+
+	prices, err := parseHTMLPrices(string(content))
+	if err != nil {
+		t.Fatalf("parseHTMLPrices 失败: %v", err)
+	}
+
+	want := []DashScopeModelPrice{
+		{Model: "qwen-max", InputPrice: 2.4, OutputPrice: 9.6},
+		{Model: "qwen-max-latest", InputPrice: 2.4, OutputPrice: 9.6},
+		{Model: "qwen-turbo", InputPrice: 0.3, OutputPrice: 0.6, CachedPrice: 0.06},
+	}
+	if len(prices) != len(want) {
+		t.Fatalf("解析到 %d 个模型，期望 %d 个: %+v", len(prices), len(want), prices)
+	}
+	for i := range want {
+		if prices[i] != want[i] {
+			t.Errorf("价格错误: got %+v, want %+v", prices[i], want[i])
+		}
+	}
+}
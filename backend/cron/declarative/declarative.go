@@ -0,0 +1,419 @@
+package declarative
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/robfig/cron/v3"
+	"golang.org/x/net/html"
+	"gorm.io/gorm"
+
+	"aimodels-prices/cron/htmlutil"
+	"aimodels-prices/cron/scraper"
+	"aimodels-prices/database"
+	"aimodels-prices/httpclient"
+	"aimodels-prices/models"
+)
+
+// ModelColumn 模型名称列，必须配置
+const ModelColumn = "model"
+
+// priceFields 可映射的价格字段，键与价格接口的JSON字段名一致
+var priceFields = map[string]func(price *models.Price, value float64){
+	"input_price":         func(p *models.Price, v float64) { p.InputPrice = v },
+	"output_price":        func(p *models.Price, v float64) { p.OutputPrice = v },
+	"input_audio_tokens":  func(p *models.Price, v float64) { p.InputAudioTokens = &v },
+	"output_audio_tokens": func(p *models.Price, v float64) { p.OutputAudioTokens = &v },
+	"cached_tokens":       func(p *models.Price, v float64) { p.CachedTokens = &v },
+	"cached_read_tokens":  func(p *models.Price, v float64) { p.CachedReadTokens = &v },
+	"cached_write_tokens": func(p *models.Price, v float64) { p.CachedWriteTokens = &v },
+	"reasoning_tokens":    func(p *models.Price, v float64) { p.ReasoningTokens = &v },
+	"input_text_tokens":   func(p *models.Price, v float64) { p.InputTextTokens = &v },
+	"output_text_tokens":  func(p *models.Price, v float64) { p.OutputTextTokens = &v },
+	"input_image_tokens":  func(p *models.Price, v float64) { p.InputImageTokens = &v },
+	"output_image_tokens": func(p *models.Price, v float64) { p.OutputImageTokens = &v },
+	"request_price":       func(p *models.Price, v float64) { p.RequestPrice = &v },
+	"image_price":         func(p *models.Price, v float64) { p.ImagePrice = &v },
+}
+
+var namePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,63}$`)
+
+var scheduleParser = cron.NewParser(cron.Second | cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow | cron.Descriptor)
+
+// Source 管理员配置的价格来源，按定义抓取页面并映射为价格记录
+type Source struct {
+	Definition models.SourceDefinition
+}
+
+// Name 来源名称
+func (s Source) Name() string { return s.Definition.Name }
+
+// ChannelType 厂商ID
+func (s Source) ChannelType() uint { return s.Definition.ChannelType }
+
+// Approval 定义中配置的审核策略，无效时全部进入审核队列
+func (s Source) Approval() scraper.ApprovalPolicy {
+	policy, err := scraper.ParseApprovalPolicy(s.Definition.Approval)
+	if err != nil {
+		return scraper.ApproveNone
+	}
+	return policy
+}
+
+// Fetch 抓取并解析价格，解析失败的行记录日志后跳过
+func (s Source) Fetch(ctx context.Context) ([]scraper.ScrapedPrice, error) {
+	result, err := Test(ctx, s.Definition)
+	if err != nil {
+		return nil, err
+	}
+	for _, msg := range result.Errors {
+		log.Printf("%s价格解析失败: %s", s.Name(), msg)
+	}
+	if len(result.Prices) == 0 {
+		return nil, fmt.Errorf("未解析到任何价格")
+	}
+
+	prices := make([]scraper.ScrapedPrice, 0, len(result.Prices))
+	for _, price := range result.Prices {
+		prices = append(prices, scraper.ScrapedPrice{Price: price})
+	}
+	return prices, nil
+}
+
+// Row 一行中各映射字段的原始文本
+type Row map[string]string
+
+// Result 按定义解析的结果
+type Result struct {
+	Rows   []Row          `json:"rows"`
+	Prices []models.Price `json:"prices"`
+	Errors []string       `json:"errors,omitempty"`
+}
+
+// Find 按名称查找已保存的来源定义
+func Find(name string) (Source, bool, error) {
+	var def models.SourceDefinition
+	err := database.DB.Where("name = ?", name).First(&def).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return Source{}, false, nil
+	}
+	if err != nil {
+		return Source{}, false, err
+	}
+	return Source{Definition: def}, true, nil
+}
+
+// Schedule 返回来源定义的执行计划，定义中的计划优先，未设置时沿用 CRON_SCHEDULES 和默认计划
+// 未启用或禁用时返回空字符串
+func Schedule(def models.SourceDefinition, schedules map[string]string) string {
+	switch {
+	case !def.Enabled:
+		return ""
+	case def.Schedule == "":
+		return scraper.Schedule(schedules, def.Name)
+	case strings.EqualFold(def.Schedule, scraper.ScheduleDisabled):
+		return ""
+	default:
+		return def.Schedule
+	}
+}
+
+// Validate 校验来源定义，名称是否与内置来源重复由调用方检查
+func Validate(def models.SourceDefinition) error {
+	if !namePattern.MatchString(def.Name) {
+		return errors.New("name must be 1-64 lowercase letters, digits, '-' or '_'")
+	}
+	if u, err := url.Parse(def.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return errors.New("url must be an absolute http(s) URL")
+	}
+
+	if _, ok := def.Columns[ModelColumn]; !ok {
+		return errors.New("columns must map model")
+	}
+	if _, ok := def.Columns["input_price"]; !ok {
+		return errors.New("columns must map input_price")
+	}
+	for field := range def.Columns {
+		if _, ok := priceFields[field]; !ok && field != ModelColumn {
+			return fmt.Errorf("unknown column field: %s", field)
+		}
+	}
+	for field, multiplier := range def.Multipliers {
+		if _, ok := priceFields[field]; !ok && field != "*" {
+			return fmt.Errorf("unknown multiplier field: %s", field)
+		}
+		if multiplier <= 0 {
+			return fmt.Errorf("multiplier for %s must be positive", field)
+		}
+	}
+
+	switch def.Format {
+	case models.SourceFormatHTML:
+		if _, err := htmlutil.Compile(def.RowSelector); err != nil {
+			return err
+		}
+		for _, selector := range def.Columns {
+			if _, err := htmlutil.Compile(selector); err != nil {
+				return err
+			}
+		}
+	case models.SourceFormatJSON:
+		if _, err := compilePath(def.RowSelector); err != nil {
+			return err
+		}
+		for _, path := range def.Columns {
+			if _, err := compilePath(path); err != nil {
+				return err
+			}
+		}
+	default:
+		return errors.New("format must be html or json")
+	}
+
+	if def.Currency != "" && def.Currency != "USD" && def.Currency != "CNY" {
+		return errors.New("currency must be USD or CNY")
+	}
+	if def.BillingUnit != "" && !models.IsBillingUnit(def.BillingUnit) {
+		return fmt.Errorf("unknown billing unit: %s", def.BillingUnit)
+	}
+	if _, err := scraper.ParseApprovalPolicy(def.Approval); err != nil {
+		return errors.New("approval must be all, known or none")
+	}
+	if def.Schedule != "" && !strings.EqualFold(def.Schedule, scraper.ScheduleDisabled) {
+		if _, err := scheduleParser.Parse(def.Schedule); err != nil {
+			return fmt.Errorf("invalid schedule: %v", err)
+		}
+	}
+	return nil
+}
+
+// Test 按定义抓取并解析页面，返回每行的原始值和转换后的价格，不写入数据库
+func Test(ctx context.Context, def models.SourceDefinition) (*Result, error) {
+	if err := Validate(def); err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", def.URL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("创建HTTP请求失败: %v", err)
+	}
+	for key, value := range def.Headers {
+		req.Header.Set(key, value)
+	}
+	body, err := httpClient(def.Name).Fetch(req)
+	if err != nil {
+		return nil, fmt.Errorf("请求%s失败: %v", def.URL, err)
+	}
+
+	return Parse(def, body)
+}
+
+var clients sync.Map
+
+// httpClient 返回来源使用的客户端，配置从环境变量读取，同一来源复用同一客户端
+// 地址由管理员填写且测试接口会返回解析结果，默认拒绝连接本机和内网地址（包括云元数据服务），
+// 设置 DECLARATIVE_SOURCE_ALLOW_PRIVATE=true 时允许
+func httpClient(name string) *httpclient.Client {
+	if c, ok := clients.Load(name); ok {
+		return c.(*httpclient.Client)
+	}
+
+	cfg := httpclient.LoadConfig(name)
+	allowPrivate, _ := strconv.ParseBool(os.Getenv("DECLARATIVE_SOURCE_ALLOW_PRIVATE"))
+	cfg.DenyPrivate = !allowPrivate
+	c, err := httpclient.New(name, cfg)
+	if err != nil {
+		log.Printf("%s HTTP客户端配置错误，忽略代理设置: %v", name, err)
+		cfg.Proxy = ""
+		c, _ = httpclient.New(name, cfg)
+	}
+
+	actual, _ := clients.LoadOrStore(name, c)
+	return actual.(*httpclient.Client)
+}
+
+// Parse 按定义从页面内容中解析价格，模型名称为空的行跳过，其余解析失败的行记录在 Errors 中
+func Parse(def models.SourceDefinition, body []byte) (*Result, error) {
+	var rows []Row
+	var err error
+	if def.Format == models.SourceFormatJSON {
+		rows, err = parseJSONRows(def, body)
+	} else {
+		rows, err = parseHTMLRows(def, body)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	result := &Result{Rows: rows, Prices: []models.Price{}}
+	for i, row := range rows {
+		if strings.TrimSpace(row[ModelColumn]) == "" {
+			continue
+		}
+		price, err := buildPrice(def, row)
+		if err != nil {
+			result.Errors = append(result.Errors, fmt.Sprintf("第%d行 %s: %v", i+1, row[ModelColumn], err))
+			continue
+		}
+		result.Prices = append(result.Prices, price)
+	}
+	return result, nil
+}
+
+func parseHTMLRows(def models.SourceDefinition, body []byte) ([]Row, error) {
+	doc, err := html.Parse(bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("解析HTML失败: %v", err)
+	}
+
+	rowSelector, err := htmlutil.Compile(def.RowSelector)
+	if err != nil {
+		return nil, err
+	}
+	columns := make(map[string]*htmlutil.Selector, len(def.Columns))
+	for field, selector := range def.Columns {
+		if columns[field], err = htmlutil.Compile(selector); err != nil {
+			return nil, err
+		}
+	}
+
+	var rows []Row
+	for _, node := range rowSelector.Select(doc) {
+		row := make(Row, len(columns))
+		for field, selector := range columns {
+			// 列选择器相对于行元素，取第一个匹配元素的文本
+			if matches := selector.Select(node); len(matches) > 0 {
+				row[field] = htmlutil.TextContent(matches[0])
+			}
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+func parseJSONRows(def models.SourceDefinition, body []byte) ([]Row, error) {
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	var data interface{}
+	if err := decoder.Decode(&data); err != nil {
+		return nil, fmt.Errorf("解析JSON失败: %v", err)
+	}
+
+	rowPath, err := compilePath(def.RowSelector)
+	if err != nil {
+		return nil, err
+	}
+	columns := make(map[string][]pathSegment, len(def.Columns))
+	for field, path := range def.Columns {
+		if columns[field], err = compilePath(path); err != nil {
+			return nil, err
+		}
+	}
+
+	nodes := evalPath(data, rowPath)
+	if len(nodes) == 1 {
+		// 行选择器指向数组时，数组元素即为各行
+		if list, ok := nodes[0].([]interface{}); ok {
+			nodes = list
+		}
+	}
+
+	rows := make([]Row, 0, len(nodes))
+	for _, node := range nodes {
+		row := make(Row, len(columns))
+		for field, path := range columns {
+			if values := evalPath(node, path); len(values) > 0 {
+				row[field] = jsonText(values[0])
+			}
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+// jsonText 将JSON值转换为文本，数字保留原始精度
+func jsonText(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case json.Number:
+		return v.String()
+	case bool:
+		return strconv.FormatBool(v)
+	default:
+		data, _ := json.Marshal(v)
+		return string(data)
+	}
+}
+
+// buildPrice 将一行原始值转换为价格记录，未映射输出价格时与输入价格相同
+func buildPrice(def models.SourceDefinition, row Row) (models.Price, error) {
+	price := models.Price{
+		Model:       strings.TrimSpace(row[ModelColumn]),
+		BillingType: models.BillingTypeForUnit(def.BillingUnit),
+		ChannelType: def.ChannelType,
+		Currency:    def.Currency,
+		PriceSource: def.URL,
+	}
+	if price.Currency == "" {
+		price.Currency = "USD"
+	}
+	if def.BillingUnit != models.BillingUnitTokens {
+		price.BillingUnit = def.BillingUnit
+	}
+
+	if strings.TrimSpace(row["input_price"]) == "" {
+		return price, fmt.Errorf("缺少输入价格")
+	}
+	for field, set := range priceFields {
+		raw := strings.TrimSpace(row[field])
+		if raw == "" || raw == "-" || raw == "—" {
+			// 页面中常用横线表示不提供该价格
+			continue
+		}
+		value, err := htmlutil.ParseAmount(raw)
+		if err != nil {
+			return price, fmt.Errorf("%s: %v", field, err)
+		}
+		value = roundPrice(value * multiplier(def, field))
+		if value == 0 && field != "input_price" && field != "output_price" {
+			// 扩展价格为0时视为未设置
+			continue
+		}
+		set(&price, value)
+	}
+	if _, ok := def.Columns["output_price"]; !ok {
+		price.OutputPrice = price.InputPrice
+	}
+	return price, nil
+}
+
+// multiplier 返回字段的单位换算倍数，如每千token价格换算为每百万token时为1000
+func multiplier(def models.SourceDefinition, field string) float64 {
+	if m, ok := def.Multipliers[field]; ok {
+		return m
+	}
+	if m, ok := def.Multipliers["*"]; ok {
+		return m
+	}
+	return 1
+}
+
+// roundPrice 四舍五入到6位小数
+func roundPrice(price float64) float64 {
+	return math.Round(price*1000000) / 1000000
+}
@@ -0,0 +1,166 @@
+package declarative
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"aimodels-prices/httpclient"
+	"aimodels-prices/models"
+)
+
+func readFixture(t *testing.T, name string) []byte {
+	t.Helper()
+	content, err := os.ReadFile("testdata/" + name)
+	if err != nil {
+		t.Fatalf("读取测试数据失败: %v", err)
+	}
+	return content
+}
+
+func TestParseHTML(t *testing.T) {
+	def := models.SourceDefinition{
+		Name:        "vendor",
+		Format:      models.SourceFormatHTML,
+		URL:         "https://vendor.example/pricing",
+		RowSelector: "table#prices > tbody tr",
+		Columns: models.StringMap{
+			"model":         "td:first-child .name",
+			"input_price":   "td:nth-child(2)",
+			"output_price":  "td:nth-child(3)",
+			"cached_tokens": "td:nth-child(4)",
+		},
+		Multipliers: models.FloatMap{"*": 1000},
+		ChannelType: 99,
+		Approval:    "known",
+	}
+	if err := Validate(def); err != nil {
+		t.Fatalf("定义校验失败: %v", err)
+	}
+
+	result, err := Parse(def, readFixture(t, "pricing.html"))
+	if err != nil {
+		t.Fatalf("Parse 失败: %v", err)
+	}
+	if len(result.Rows) != 4 {
+		t.Fatalf("解析到 %d 行，期望 4 行: %+v", len(result.Rows), result.Rows)
+	}
+	if len(result.Prices) != 2 || len(result.Errors) != 1 {
+		t.Fatalf("价格或错误数量错误: %+v / %v", result.Prices, result.Errors)
+	}
+
+	large := result.Prices[0]
+	if large.Model != "vendor-large" || large.InputPrice != 3 || large.OutputPrice != 15 || large.Currency != "USD" || large.ChannelType != 99 {
+		t.Errorf("vendor-large 价格错误: %+v", large)
+	}
+	if large.CachedTokens == nil || *large.CachedTokens != 0.3 {
+		t.Errorf("vendor-large 缓存价格错误: %v", large.CachedTokens)
+	}
+	if small := result.Prices[1]; small.InputPrice != 0.2 || small.CachedTokens != nil {
+		t.Errorf("vendor-small 价格错误: %+v", small)
+	}
+}
+
+func TestParseJSON(t *testing.T) {
+	def := models.SourceDefinition{
+		Name:        "vendor-api",
+		Format:      models.SourceFormatJSON,
+		URL:         "https://vendor.example/v1/models",
+		RowSelector: "$.data[*]",
+		Columns: models.StringMap{
+			"model":       "id",
+			"input_price": "$.pricing.input",
+		},
+		Currency: "CNY",
+		Approval: "all",
+	}
+	if err := Validate(def); err != nil {
+		t.Fatalf("定义校验失败: %v", err)
+	}
+
+	result, err := Parse(def, readFixture(t, "models.json"))
+	if err != nil {
+		t.Fatalf("Parse 失败: %v", err)
+	}
+	if len(result.Prices) != 2 || len(result.Errors) != 1 {
+		t.Fatalf("价格或错误数量错误: %+v / %v", result.Prices, result.Errors)
+	}
+	// 未映射输出价格时与输入价格相同
+	if chat := result.Prices[0]; chat.Model != "vendor-chat" || chat.InputPrice != 0.5 || chat.OutputPrice != 0.5 || chat.Currency != "CNY" {
+		t.Errorf("vendor-chat 价格错误: %+v", chat)
+	}
+
+	// 行选择器指向数组时展开为各行，按次计费
+	def.RowSelector = "$.data"
+	def.BillingUnit = models.BillingUnitRequest
+	def.Columns = models.StringMap{"model": "['id']", "input_price": "pricing.per_request"}
+	result, err = Parse(def, readFixture(t, "models.json"))
+	if err != nil {
+		t.Fatalf("Parse 失败: %v", err)
+	}
+	if len(result.Rows) != 3 || len(result.Prices) != 1 {
+		t.Fatalf("按次计费解析错误: %+v / %v", result.Prices, result.Errors)
+	}
+	if image := result.Prices[0]; image.Model != "vendor-image" || image.InputPrice != 0.04 || image.BillingUnit != models.BillingUnitRequest {
+		t.Errorf("vendor-image 价格错误: %+v", image)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	base := models.SourceDefinition{
+		Name:        "vendor",
+		Format:      models.SourceFormatHTML,
+		URL:         "https://vendor.example/pricing",
+		RowSelector: "tr",
+		Columns:     models.StringMap{"model": "td:nth-child(1)", "input_price": "td:nth-child(2)"},
+		Approval:    "known",
+	}
+
+	cases := map[string]func(def *models.SourceDefinition){
+		"名称":       func(def *models.SourceDefinition) { def.Name = "Vendor:X" },
+		"地址":       func(def *models.SourceDefinition) { def.URL = "file:///etc/passwd" },
+		"缺少模型列":    func(def *models.SourceDefinition) { def.Columns = models.StringMap{"input_price": "td"} },
+		"未知字段":     func(def *models.SourceDefinition) { def.Columns["price"] = "td" },
+		"选择器":      func(def *models.SourceDefinition) { def.RowSelector = "tr:hover" },
+		"JSONPath": func(def *models.SourceDefinition) { def.Format = models.SourceFormatJSON; def.RowSelector = "$..data" },
+		"倍数":       func(def *models.SourceDefinition) { def.Multipliers = models.FloatMap{"input_price": 0} },
+		"审核策略":     func(def *models.SourceDefinition) { def.Approval = "auto" },
+		"执行计划":     func(def *models.SourceDefinition) { def.Schedule = "every hour" },
+	}
+	for name, mutate := range cases {
+		def := base
+		def.Columns = models.StringMap{"model": "td:nth-child(1)", "input_price": "td:nth-child(2)"}
+		mutate(&def)
+		if err := Validate(def); err == nil {
+			t.Errorf("%s: 期望校验失败", name)
+		}
+	}
+}
+
+func TestDeniesPrivateAddress(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("不应请求内网地址")
+	}))
+	defer server.Close()
+
+	def := models.SourceDefinition{
+		Name:        "private-test",
+		Format:      models.SourceFormatJSON,
+		URL:         server.URL,
+		RowSelector: "$.data[*]",
+		Columns:     models.StringMap{"model": "id", "input_price": "price"},
+		Approval:    "known",
+	}
+	start := time.Now()
+	_, err := Test(context.Background(), def)
+	if err == nil || !strings.Contains(err.Error(), httpclient.ErrPrivateAddress.Error()) {
+		t.Fatalf("应拒绝访问内网地址: %v", err)
+	}
+	if time.Since(start) > time.Second {
+		t.Errorf("拒绝的内网地址不应重试")
+	}
+}
@@ -0,0 +1,115 @@
+package declarative
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// pathSegment JSONPath中的一段：对象键、数组下标或通配符
+type pathSegment struct {
+	key      string
+	index    int
+	isIndex  bool
+	wildcard bool
+}
+
+// compilePath 解析JSONPath子集：$、@、.key、['key']、[n]、[*]、.*
+// 不以 $ 或 @ 开头时视为相对路径，如 "pricing.input"
+func compilePath(path string) ([]pathSegment, error) {
+	s := strings.TrimSpace(path)
+	if strings.HasPrefix(s, "$") || strings.HasPrefix(s, "@") {
+		s = s[1:]
+	} else if s != "" && s[0] != '.' && s[0] != '[' {
+		s = "." + s
+	}
+
+	var segments []pathSegment
+	for pos := 0; pos < len(s); {
+		switch s[pos] {
+		case '.':
+			pos++
+			if pos < len(s) && s[pos] == '.' {
+				return nil, fmt.Errorf("JSONPath %q 无效: 不支持递归查找 ..", path)
+			}
+			if pos < len(s) && s[pos] == '*' {
+				segments = append(segments, pathSegment{wildcard: true})
+				pos++
+				continue
+			}
+			end := pos
+			for end < len(s) && s[end] != '.' && s[end] != '[' {
+				end++
+			}
+			if end == pos {
+				return nil, fmt.Errorf("JSONPath %q 无效: 位置%d处缺少键名", path, pos)
+			}
+			segments = append(segments, pathSegment{key: s[pos:end]})
+			pos = end
+		case '[':
+			end := strings.IndexByte(s[pos:], ']')
+			if end < 0 {
+				return nil, fmt.Errorf("JSONPath %q 无效: 缺少 ]", path)
+			}
+			inner := strings.TrimSpace(s[pos+1 : pos+end])
+			pos += end + 1
+
+			switch {
+			case inner == "*":
+				segments = append(segments, pathSegment{wildcard: true})
+			case len(inner) >= 2 && (inner[0] == '\'' || inner[0] == '"') && inner[len(inner)-1] == inner[0]:
+				segments = append(segments, pathSegment{key: inner[1 : len(inner)-1]})
+			default:
+				index, err := strconv.Atoi(inner)
+				if err != nil {
+					return nil, fmt.Errorf("JSONPath %q 无效: 不支持的下标 %s", path, inner)
+				}
+				segments = append(segments, pathSegment{index: index, isIndex: true})
+			}
+		default:
+			return nil, fmt.Errorf("JSONPath %q 无效: 位置%d处字符 %q 无效", path, pos, s[pos])
+		}
+	}
+	return segments, nil
+}
+
+// evalPath 对JSON数据执行JSONPath，通配符会展开为多个结果
+func evalPath(data interface{}, segments []pathSegment) []interface{} {
+	current := []interface{}{data}
+	for _, seg := range segments {
+		var next []interface{}
+		for _, node := range current {
+			switch v := node.(type) {
+			case map[string]interface{}:
+				if seg.wildcard {
+					keys := make([]string, 0, len(v))
+					for key := range v {
+						keys = append(keys, key)
+					}
+					sort.Strings(keys)
+					for _, key := range keys {
+						next = append(next, v[key])
+					}
+				} else if value, ok := v[seg.key]; ok && !seg.isIndex {
+					next = append(next, value)
+				}
+			case []interface{}:
+				if seg.wildcard {
+					next = append(next, v...)
+				} else if seg.isIndex {
+					index := seg.index
+					if index < 0 {
+						// 负数下标从末尾开始计算
+						index += len(v)
+					}
+					if index >= 0 && index < len(v) {
+						next = append(next, v[index])
+					}
+				}
+			}
+		}
+		current = next
+	}
+	return current
+}
@@ -0,0 +1,192 @@
+package deepseek_api
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"aimodels-prices/cron/htmlutil"
+	"aimodels-prices/cron/scraper"
+	"aimodels-prices/httpclient"
+	"aimodels-prices/models"
+
+	"golang.org/x/net/html"
+)
+
+const (
+	DeepSeekPricingURL  = "https://api-docs.deepseek.com/zh-cn/quick_start/pricing"
+	DeepSeekChannelType = 28
+	BillingType         = "tokens"
+	Currency            = "CNY"
+	PriceSource         = "https://api-docs.deepseek.com/zh-cn/quick_start/pricing"
+)
+
+// DeepSeekModelPrice 从页面解析出的模型价格数据，价格单位均为 元/1M tokens
+type DeepSeekModelPrice struct {
+	Model       string
+	InputPrice  float64 // 输入（缓存未命中）
+	OutputPrice float64
+	CachedPrice float64 // 输入（缓存命中）
+}
+
+// Source DeepSeek价格来源，已审核的模型自动更新，新模型进入待审核队列
+type Source struct{}
+
+// Name 来源名称
+func (Source) Name() string { return "deepseek" }
+
+// ChannelType 厂商ID
+func (Source) ChannelType() uint { return DeepSeekChannelType }
+
+// Approval 默认审核策略
+func (Source) Approval() scraper.ApprovalPolicy { return scraper.ApproveKnown }
+
+// Fetch 抓取定价页面并转换为价格记录
+func (Source) Fetch(ctx context.Context) ([]scraper.ScrapedPrice, error) {
+	prices, err := fetchDeepSeekPrices(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]scraper.ScrapedPrice, 0, len(prices))
+	for _, mp := range prices {
+		result = append(result, scraper.ScrapedPrice{Price: buildPrice(mp)})
+	}
+	return result, nil
+}
+
+// fetchDeepSeekPrices 抓取DeepSeek定价页面并解析价格表格
+func fetchDeepSeekPrices(ctx context.Context) ([]DeepSeekModelPrice, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", DeepSeekPricingURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("创建HTTP请求失败: %v", err)
+	}
+	body, err := httpclient.For("deepseek").Fetch(req)
+	if err != nil {
+		return nil, fmt.Errorf("请求DeepSeek定价页面失败: %v", err)
+	}
+
+	return parseHTMLPrices(string(body))
+}
+
+// buildPrice 将解析结果转换为价格记录
+func buildPrice(mp DeepSeekModelPrice) models.Price {
+	price := models.Price{
+		Model:       mp.Model,
+		BillingType: BillingType,
+		ChannelType: DeepSeekChannelType,
+		Currency:    Currency,
+		InputPrice:  mp.InputPrice,
+		OutputPrice: mp.OutputPrice,
+		PriceSource: PriceSource,
+	}
+
+	// 缓存命中价格
+	if mp.CachedPrice > 0 {
+		cached := mp.CachedPrice
+		price.CachedTokens = &cached
+	}
+
+	return price
+}
+
+// parseHTMLPrices 从HTML中解析价格表格
+// DeepSeek的价格表是转置的：表头为各模型ID，每行是一个价格项，
+// 多个模型价格相同时使用colspan合并单元格
+func parseHTMLPrices(htmlContent string) ([]DeepSeekModelPrice, error) {
+	doc, err := html.Parse(strings.NewReader(htmlContent))
+	if err != nil {
+		return nil, fmt.Errorf("解析HTML失败: %v", err)
+	}
+
+	for _, table := range htmlutil.FindElements(doc, "table") {
+		var modelNames []string
+		for _, th := range htmlutil.FindElements(table, "th") {
+			if name := htmlutil.TextContent(th); strings.HasPrefix(name, "deepseek-") {
+				modelNames = append(modelNames, name)
+			}
+		}
+		if len(modelNames) == 0 {
+			continue
+		}
+
+		prices := make([]DeepSeekModelPrice, len(modelNames))
+		for i, name := range modelNames {
+			prices[i].Model = name
+		}
+
+		var hasInput, hasOutput bool
+		for _, tr := range htmlutil.TableRows(table) {
+			label, values := splitPriceRow(tr, len(modelNames))
+			if len(values) == 0 {
+				continue
+			}
+
+			for i, value := range values {
+				amount, err := htmlutil.ParseAmount(value)
+				if err != nil {
+					log.Printf("解析价格失败 %s %s: %v", modelNames[i], label, err)
+					continue
+				}
+				amount = roundPrice(amount)
+
+				switch {
+				case strings.Contains(label, "缓存命中"):
+					prices[i].CachedPrice = amount
+				case strings.Contains(label, "输入"):
+					prices[i].InputPrice = amount
+					hasInput = true
+				case strings.Contains(label, "输出"):
+					prices[i].OutputPrice = amount
+					hasOutput = true
+				}
+			}
+		}
+
+		if hasInput && hasOutput {
+			return prices, nil
+		}
+	}
+
+	return nil, fmt.Errorf("未找到DeepSeek模型价格表格")
+}
+
+// splitPriceRow 提取价格行的标签和按模型展开后的价格
+// 只处理标签包含"百万tokens"的行，colspan合并的价格展开到对应的每个模型
+func splitPriceRow(tr *html.Node, modelCount int) (string, []string) {
+	tds := htmlutil.FindElements(tr, "td")
+	for i, td := range tds {
+		label := htmlutil.TextContent(td)
+		if !strings.Contains(strings.ToLower(label), "百万tokens") {
+			continue
+		}
+
+		var values []string
+		for _, cell := range tds[i+1:] {
+			span := 1
+			if v, ok := htmlutil.Attr(cell, "colspan"); ok {
+				if n, err := strconv.Atoi(v); err == nil && n > 1 {
+					span = n
+				}
+			}
+			text := htmlutil.TextContent(cell)
+			for j := 0; j < span; j++ {
+				values = append(values, text)
+			}
+		}
+		if len(values) < modelCount {
+			return label, nil
+		}
+		return label, values[:modelCount]
+	}
+	return "", nil
+}
+
+// roundPrice 四舍五入到6位小数
+func roundPrice(price float64) float64 {
+	return math.Round(price*1000000) / 1000000
+}
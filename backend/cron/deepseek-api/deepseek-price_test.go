@@ -0,0 +1,37 @@
+package deepseek_api
+
+import (
+	"os"
+	"testing"
+)
+
+func TestParseHTMLPrices(t *testing.T) {
+	content, err := os.ReadFile("testdata/pricing.html")
+	if err != nil {
+		t.Fatalf("读取测试页面失败: %v", err)
+	}
+
+	prices, err := parseHTMLPrices(string(content))
+	if err != nil {
+		t.Fatalf("parseHTMLPrices 失败: %v", err)
+	}
+
+	want := []DeepSeekModelPrice{
+		{Model: "deepseek-chat", InputPrice: 2, OutputPrice: 3, CachedPrice: 0.2},
+		{Model: "deepseek-reasoner", InputPrice: 2, OutputPrice: 3, CachedPrice: 0.2},
+	}
+	if len(prices) != len(want) {
+		t.Fatalf("解析到 %d 个模型，期望 %d 个: %+v", len(prices), len(want), prices)
+	}
+	for i := range want {
+		if prices[i] != want[i] {
+			t.Errorf("价格错误: got %+v, want %+v", prices[i], want[i])
+		}
+	}
+
+	price := buildPrice(prices[0])
+	if price.Currency != "CNY" || price.ChannelType != DeepSeekChannelType ||
+		price.CachedTokens == nil || *price.CachedTokens != 0.2 {
+		t.Errorf("价格记录错误: %+v", price)
+	}
+}
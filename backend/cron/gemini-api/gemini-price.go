@@ -0,0 +1,333 @@
+package gemini_api
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"aimodels-prices/cron/htmlutil"
+	"aimodels-prices/cron/scraper"
+	"aimodels-prices/httpclient"
+	"aimodels-prices/models"
+
+	"golang.org/x/net/html"
+)
+
+const (
+	GeminiPricingURL  = "https://ai.google.dev/gemini-api/docs/pricing"
+	GeminiChannelType = 25
+	BillingType       = "tokens"
+	Currency          = "USD"
+	PriceSource       = "https://ai.google.dev/gemini-api/docs/pricing"
+)
+
+// GeminiModelPrice 从页面解析出的模型价格数据，价格单位均为 $/1M tokens
+type GeminiModelPrice struct {
+	Model            string
+	InputPrice       float64
+	OutputPrice      float64
+	AudioInputPrice  float64
+	CachedPrice      float64
+	Tiers            models.PriceTiers // 长上下文阶梯价格
+	BatchInputPrice  float64
+	BatchOutputPrice float64
+}
+
+// Source Gemini官网价格来源，价格自动审核通过
+type Source struct{}
+
+// Name 来源名称
+func (Source) Name() string { return "gemini" }
+
+// ChannelType 厂商ID
+func (Source) ChannelType() uint { return GeminiChannelType }
+
+// Approval 默认审核策略
+func (Source) Approval() scraper.ApprovalPolicy { return scraper.ApproveAll }
+
+// Fetch 抓取定价页面并转换为价格记录
+func (Source) Fetch(ctx context.Context) ([]scraper.ScrapedPrice, error) {
+	prices, err := fetchGeminiPrices(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]scraper.ScrapedPrice, 0, len(prices))
+	for _, mp := range prices {
+		result = append(result, scraper.ScrapedPrice{Price: buildPrice(mp)})
+	}
+	return result, nil
+}
+
+// buildPrice 将解析结果转换为价格记录
+func buildPrice(mp GeminiModelPrice) models.Price {
+	price := models.Price{
+		Model:       mp.Model,
+		BillingType: BillingType,
+		ChannelType: GeminiChannelType,
+		Currency:    Currency,
+		InputPrice:  mp.InputPrice,
+		OutputPrice: mp.OutputPrice,
+		Tiers:       mp.Tiers,
+		PriceSource: PriceSource,
+	}
+
+	if mp.AudioInputPrice > 0 {
+		audio := mp.AudioInputPrice
+		price.InputAudioTokens = &audio
+	}
+	if mp.CachedPrice > 0 {
+		cached := mp.CachedPrice
+		price.CachedTokens = &cached
+	}
+
+	if mp.BatchInputPrice > 0 && mp.BatchOutputPrice > 0 && mp.InputPrice > 0 && mp.OutputPrice > 0 {
+		price.Modifiers = models.PriceModifiers{{
+			Kind:             models.ModifierBatch,
+			InputMultiplier:  roundPrice(mp.BatchInputPrice / mp.InputPrice),
+			OutputMultiplier: roundPrice(mp.BatchOutputPrice / mp.OutputPrice),
+		}}
+	}
+
+	return price
+}
+
+// fetchGeminiPrices 抓取Gemini定价页面并解析价格表格
+func fetchGeminiPrices(ctx context.Context) ([]GeminiModelPrice, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", GeminiPricingURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("创建HTTP请求失败: %v", err)
+	}
+	body, err := httpclient.For("gemini").Fetch(req)
+	if err != nil {
+		return nil, fmt.Errorf("请求Gemini定价页面失败: %v", err)
+	}
+
+	return parseHTMLPrices(string(body))
+}
+
+// pricingTable 页面中某个模型的价格表
+type pricingTable struct {
+	model string
+	batch bool
+	table *html.Node
+}
+
+// parseHTMLPrices 从HTML中解析价格表格
+// 页面按模型分节：<h2>模型名称</h2> 后跟 <code>模型ID</code>，再跟 Standard/Batch 价格表，
+// 价格表的 Paid Tier 列中包含 "$1.25, prompts <= 200k tokens" 之类的分档说明
+func parseHTMLPrices(htmlContent string) ([]GeminiModelPrice, error) {
+	doc, err := html.Parse(strings.NewReader(htmlContent))
+	if err != nil {
+		return nil, fmt.Errorf("解析HTML失败: %v", err)
+	}
+
+	var prices []GeminiModelPrice
+	index := make(map[string]int)
+
+	for _, pt := range collectTables(doc) {
+		mp, ok := parseTable(pt.table)
+		if !ok {
+			continue
+		}
+
+		i, exists := index[pt.model]
+		if pt.batch {
+			if exists {
+				prices[i].BatchInputPrice = mp.InputPrice
+				prices[i].BatchOutputPrice = mp.OutputPrice
+			}
+			continue
+		}
+		if exists {
+			continue
+		}
+
+		mp.Model = pt.model
+		index[pt.model] = len(prices)
+		prices = append(prices, mp)
+	}
+
+	if len(prices) == 0 {
+		return nil, fmt.Errorf("未找到Gemini模型价格表格")
+	}
+	return prices, nil
+}
+
+// collectTables 按文档顺序遍历，将每个价格表关联到其所属的模型ID和Standard/Batch分组
+func collectTables(doc *html.Node) []pricingTable {
+	var tables []pricingTable
+	var model string
+	var batch bool
+
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.ElementNode {
+			switch n.Data {
+			case "h2":
+				// 新的模型分节
+				model = ""
+				batch = false
+			case "h3", "h4":
+				text := strings.ToLower(htmlutil.TextContent(n))
+				batch = strings.Contains(text, "batch")
+			case "code":
+				text := strings.TrimSpace(htmlutil.TextContent(n))
+				if model == "" && strings.HasPrefix(text, "gemini-") && !strings.Contains(text, " ") {
+					model = text
+				}
+			case "table":
+				if model != "" {
+					tables = append(tables, pricingTable{model: model, batch: batch, table: n})
+				}
+				return
+			}
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(doc)
+
+	return tables
+}
+
+// parseTable 解析单个价格表的 Paid Tier 列
+func parseTable(table *html.Node) (GeminiModelPrice, bool) {
+	var mp GeminiModelPrice
+	headers := htmlutil.TableHeaders(table)
+	paidCol := -1
+	for i, h := range headers {
+		if strings.Contains(strings.ToLower(h), "paid") {
+			paidCol = i
+			break
+		}
+	}
+	if paidCol < 0 {
+		return mp, false
+	}
+
+	var hasInput bool
+	tiers := make(map[int64]*models.PriceTier)
+	tier := func(threshold int64) *models.PriceTier {
+		if tiers[threshold] == nil {
+			tiers[threshold] = &models.PriceTier{AbovePromptTokens: threshold}
+		}
+		return tiers[threshold]
+	}
+
+	for _, tr := range htmlutil.TableRows(table) {
+		cells := htmlutil.RowCells(tr)
+		if len(cells) <= paidCol {
+			continue
+		}
+		label := strings.ToLower(cells[0])
+		segments := parsePriceSegments(cells[paidCol])
+		if len(segments) == 0 {
+			continue
+		}
+
+		for _, seg := range segments {
+			switch {
+			case strings.HasPrefix(label, "input price"):
+				switch {
+				case seg.aboveTokens > 0:
+					tier(seg.aboveTokens).InputPrice = seg.price
+				case seg.audio:
+					mp.AudioInputPrice = seg.price
+				default:
+					mp.InputPrice = seg.price
+					hasInput = true
+				}
+			case strings.HasPrefix(label, "output price"):
+				if seg.aboveTokens > 0 {
+					tier(seg.aboveTokens).OutputPrice = seg.price
+				} else if !seg.audio {
+					mp.OutputPrice = seg.price
+				}
+			case strings.HasPrefix(label, "context caching price"):
+				if seg.aboveTokens > 0 {
+					cached := seg.price
+					tier(seg.aboveTokens).CachedTokens = &cached
+				} else if !seg.audio {
+					mp.CachedPrice = seg.price
+				}
+			}
+		}
+	}
+
+	if !hasInput {
+		return mp, false
+	}
+
+	// 只列出部分价格的档位，未列出的价格与基础档位相同，不能留为0
+	for _, t := range tiers {
+		if t.InputPrice == 0 {
+			t.InputPrice = mp.InputPrice
+		}
+		if t.OutputPrice == 0 {
+			t.OutputPrice = mp.OutputPrice
+		}
+		mp.Tiers = append(mp.Tiers, *t)
+	}
+	if err := mp.Tiers.Validate(); err != nil {
+		log.Printf("阶梯价格无效: %v", err)
+		mp.Tiers = nil
+	}
+
+	return mp, true
+}
+
+// priceSegment 单元格中的一个价格及其适用条件
+type priceSegment struct {
+	price       float64
+	audio       bool  // 仅适用于音频输入
+	aboveTokens int64 // 提示词超过该token数时适用，0表示基础档位
+}
+
+var (
+	// 匹配 "$1.25, prompts <= 200k tokens" 中的价格及其说明
+	segmentRegex = regexp.MustCompile(`\$\s*([\d,]*\.?\d+)([^$]*)`)
+	// 匹配 "prompts > 200k tokens"
+	aboveRegex = regexp.MustCompile(`>\s*([\d,]+)\s*(k?)`)
+)
+
+// parsePriceSegments 解析单元格中的所有价格，忽略存储费用等按小时计费的价格
+func parsePriceSegments(cell string) []priceSegment {
+	var segments []priceSegment
+	for _, m := range segmentRegex.FindAllStringSubmatch(cell, -1) {
+		note := strings.ToLower(m[2])
+		if strings.Contains(note, "per hour") || strings.Contains(note, "storage") {
+			continue
+		}
+
+		price, err := strconv.ParseFloat(strings.ReplaceAll(m[1], ",", ""), 64)
+		if err != nil {
+			continue
+		}
+
+		seg := priceSegment{
+			price: roundPrice(price),
+			audio: strings.Contains(note, "audio") && !strings.Contains(note, "text"),
+		}
+		if above := aboveRegex.FindStringSubmatch(note); above != nil {
+			n, _ := strconv.ParseInt(strings.ReplaceAll(above[1], ",", ""), 10, 64)
+			if above[2] == "k" {
+				n *= 1000
+			}
+			seg.aboveTokens = n
+		}
+		segments = append(segments, seg)
+	}
+	return segments
+}
+
+// roundPrice 四舍五入到6位小数
+func roundPrice(price float64) float64 {
+	return math.Round(price*1000000) / 1000000
+}
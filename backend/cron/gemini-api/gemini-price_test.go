@@ -0,0 +1,73 @@
+package gemini_api
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"golang.org/x/net/html"
+
+	"aimodels-prices/cron/htmlutil"
+)
+
+func TestParseHTMLPrices(t *testing.T) {
+	content, err := os.ReadFile("testdata/pricing.html")
+	if err != nil {
+		t.Fatalf("读取测试页面失败: %v", err)
+	}
+
+	prices, err := parseHTMLPrices(string(content))
+	if err != nil {
+		t.Fatalf("parseHTMLPrices 失败: %v", err)
+	}
+	if len(prices) != 2 {
+		t.Fatalf("解析到 %d 个模型，期望 2 个: %+v", len(prices), prices)
+	}
+
+	pro := prices[0]
+	if pro.Model != "gemini-2.5-pro" || pro.InputPrice != 1.25 || pro.OutputPrice != 10 || pro.CachedPrice != 0.125 {
+		t.Errorf("gemini-2.5-pro 基础价格错误: %+v", pro)
+	}
+	if len(pro.Tiers) != 1 {
+		t.Fatalf("gemini-2.5-pro 阶梯价格错误: %+v", pro.Tiers)
+	}
+	tier := pro.Tiers[0]
+	if tier.AbovePromptTokens != 200000 || tier.InputPrice != 2.5 || tier.OutputPrice != 15 ||
+		tier.CachedTokens == nil || *tier.CachedTokens != 0.25 {
+		t.Errorf("gemini-2.5-pro 长上下文价格错误: %+v", tier)
+	}
+	if pro.BatchInputPrice != 0.625 || pro.BatchOutputPrice != 5 {
+		t.Errorf("gemini-2.5-pro 批量价格错误: %+v", pro)
+	}
+
+	flash := prices[1]
+	if flash.Model != "gemini-2.5-flash" || flash.InputPrice != 0.3 || flash.AudioInputPrice != 1 ||
+		flash.OutputPrice != 2.5 || flash.CachedPrice != 0.03 || len(flash.Tiers) != 0 {
+		t.Errorf("gemini-2.5-flash 价格错误: %+v", flash)
+	}
+
+	price := buildPrice(pro)
+	if len(price.Modifiers) != 1 || price.Modifiers[0].InputMultiplier != 0.5 {
+		t.Errorf("批量折扣错误: %+v", price.Modifiers)
+	}
+}
+
+func TestParseTablePartialTier(t *testing.T) {
+	// 长上下文档位只列出输入价格，输出价格与基础档位相同
+	doc, err := html.Parse(strings.NewReader(`<table>
+<thead><tr><th></th><th>Free Tier</th><th>Paid Tier, per 1M tokens in USD</th></tr></thead>
+<tbody>
+<tr><td>Input price</td><td>Free of charge</td><td>$1.25, prompts &lt;= 200k tokens<br>$2.50, prompts &gt; 200k tokens</td></tr>
+<tr><td>Output price</td><td>Free of charge</td><td>$10.00</td></tr>
+</tbody></table>`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	mp, ok := parseTable(htmlutil.FindElements(doc, "table")[0])
+	if !ok || len(mp.Tiers) != 1 {
+		t.Fatalf("解析失败: %+v", mp)
+	}
+	if tier := mp.Tiers[0]; tier.InputPrice != 2.5 || tier.OutputPrice != 10 {
+		t.Errorf("未列出的档位价格应沿用基础价格: %+v", tier)
+	}
+}
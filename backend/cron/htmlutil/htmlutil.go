@@ -0,0 +1,136 @@
+package htmlutil
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// FindElements 递归查找指定标签名的所有元素
+func FindElements(n *html.Node, tag string) []*html.Node {
+	var result []*html.Node
+	if n.Type == html.ElementNode && n.Data == tag {
+		result = append(result, n)
+	}
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		result = append(result, FindElements(c, tag)...)
+	}
+	return result
+}
+
+// TextContent 递归获取节点的文本内容，并合并连续空白
+func TextContent(n *html.Node) string {
+	return strings.Join(strings.Fields(rawText(n)), " ")
+}
+
+func rawText(n *html.Node) string {
+	if n.Type == html.TextNode {
+		return n.Data
+	}
+	var sb strings.Builder
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		sb.WriteString(rawText(c))
+		// 块级元素之间补充空格，避免文本粘连
+		if c.Type == html.ElementNode && (c.Data == "br" || c.Data == "p" || c.Data == "div") {
+			sb.WriteString(" ")
+		}
+	}
+	return sb.String()
+}
+
+// Attr 获取元素属性值
+func Attr(n *html.Node, key string) (string, bool) {
+	for _, attr := range n.Attr {
+		if attr.Key == key {
+			return attr.Val, true
+		}
+	}
+	return "", false
+}
+
+// TableHeaders 获取表格列头文本，优先使用thead，否则使用第一行的th
+func TableHeaders(table *html.Node) []string {
+	var ths []*html.Node
+	if theads := FindElements(table, "thead"); len(theads) > 0 {
+		ths = FindElements(theads[0], "th")
+	} else if rows := FindElements(table, "tr"); len(rows) > 0 {
+		ths = FindElements(rows[0], "th")
+	}
+
+	headers := make([]string, 0, len(ths))
+	for _, th := range ths {
+		headers = append(headers, TextContent(th))
+	}
+	return headers
+}
+
+// TableRows 获取表格中包含td的数据行
+func TableRows(table *html.Node) []*html.Node {
+	var rows []*html.Node
+	for _, tr := range FindElements(table, "tr") {
+		if len(FindElements(tr, "td")) > 0 {
+			rows = append(rows, tr)
+		}
+	}
+	return rows
+}
+
+// RowCells 获取一行中所有td的文本
+func RowCells(tr *html.Node) []string {
+	tds := FindElements(tr, "td")
+	cells := make([]string, 0, len(tds))
+	for _, td := range tds {
+		cells = append(cells, TextContent(td))
+	}
+	return cells
+}
+
+// Table 解析后的表格
+type Table struct {
+	Headers []string
+	Rows    [][]string
+}
+
+// Column 返回第一个包含任一关键字的列下标（不区分大小写），未找到时返回-1
+func (t Table) Column(keywords ...string) int {
+	for i, h := range t.Headers {
+		h = strings.ToLower(h)
+		for _, keyword := range keywords {
+			if strings.Contains(h, strings.ToLower(keyword)) {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// Tables 解析文档中的所有表格
+func Tables(doc *html.Node) []Table {
+	var tables []Table
+	for _, node := range FindElements(doc, "table") {
+		table := Table{Headers: TableHeaders(node)}
+		for _, tr := range TableRows(node) {
+			table.Rows = append(table.Rows, RowCells(tr))
+		}
+		tables = append(tables, table)
+	}
+	return tables
+}
+
+var amountRegex = regexp.MustCompile(`\d[\d,]*(?:\.\d+)?|\.\d+`)
+
+// ParseAmount 提取字符串中的第一个数字，如 "￥4.00 / 百万tokens" -> 4
+// "免费" 视为0
+func ParseAmount(s string) (float64, error) {
+	if strings.Contains(s, "免费") || strings.EqualFold(strings.TrimSpace(s), "free") {
+		return 0, nil
+	}
+	match := amountRegex.FindString(s)
+	if match == "" {
+		return 0, fmt.Errorf("无法解析价格: %s", s)
+	}
+	return strconv.ParseFloat(strings.ReplaceAll(match, ",", ""), 64)
+}
@@ -0,0 +1,335 @@
+package htmlutil
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// Selector 编译后的CSS选择器，支持常用子集：
+// 标签、*、#id、.class、[attr]、[attr=value]、:first-child、:last-child、:nth-child(n)，
+// 后代（空格）和子元素（>）组合，以及逗号分隔的多个选择器
+type Selector struct {
+	groups [][]selectorStep
+}
+
+type selectorStep struct {
+	combinator byte // 与前一步的关系：' ' 后代，'>' 子元素，第一步为0
+	tag        string
+	id         string
+	classes    []string
+	attrs      []attrMatcher
+	nth        int  // :nth-child(n)，0表示不限制
+	last       bool // :last-child
+}
+
+type attrMatcher struct {
+	key      string
+	value    string
+	hasValue bool
+}
+
+// Compile 编译CSS选择器
+func Compile(selector string) (*Selector, error) {
+	p := &selectorParser{s: strings.TrimSpace(selector)}
+	if p.s == "" {
+		return nil, fmt.Errorf("选择器为空")
+	}
+
+	sel := &Selector{}
+	for {
+		steps, err := p.complex()
+		if err != nil {
+			return nil, fmt.Errorf("选择器 %q 无效: %v", selector, err)
+		}
+		sel.groups = append(sel.groups, steps)
+		if p.pos >= len(p.s) {
+			return sel, nil
+		}
+		// complex 只会停在逗号或结尾
+		p.pos++
+	}
+}
+
+// Select 按文档顺序返回root后代中匹配选择器的元素，祖先匹配范围不超出root
+func (s *Selector) Select(root *html.Node) []*html.Node {
+	var result []*html.Node
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			if c.Type == html.ElementNode && s.matches(c, root) {
+				result = append(result, c)
+			}
+			walk(c)
+		}
+	}
+	walk(root)
+	return result
+}
+
+// Select 编译并执行CSS选择器
+func Select(root *html.Node, selector string) ([]*html.Node, error) {
+	sel, err := Compile(selector)
+	if err != nil {
+		return nil, err
+	}
+	return sel.Select(root), nil
+}
+
+func (s *Selector) matches(n, root *html.Node) bool {
+	for _, steps := range s.groups {
+		if matchSteps(n, steps, len(steps)-1, root) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchSteps 从右向左匹配组合选择器
+func matchSteps(n *html.Node, steps []selectorStep, i int, root *html.Node) bool {
+	if !steps[i].matches(n) {
+		return false
+	}
+	if i == 0 {
+		return true
+	}
+
+	if steps[i].combinator == '>' {
+		if n == root || n.Parent == nil || n.Parent.Type != html.ElementNode {
+			return false
+		}
+		return matchSteps(n.Parent, steps, i-1, root)
+	}
+	for a := n; a != root && a.Parent != nil; {
+		a = a.Parent
+		if a.Type == html.ElementNode && matchSteps(a, steps, i-1, root) {
+			return true
+		}
+	}
+	return false
+}
+
+func (step selectorStep) matches(n *html.Node) bool {
+	if step.tag != "" && n.Data != step.tag {
+		return false
+	}
+	if step.id != "" {
+		if id, _ := Attr(n, "id"); id != step.id {
+			return false
+		}
+	}
+	if len(step.classes) > 0 {
+		class, _ := Attr(n, "class")
+		names := strings.Fields(class)
+		for _, want := range step.classes {
+			if !containsString(names, want) {
+				return false
+			}
+		}
+	}
+	for _, attr := range step.attrs {
+		value, ok := Attr(n, attr.key)
+		if !ok || (attr.hasValue && value != attr.value) {
+			return false
+		}
+	}
+	if step.nth > 0 && siblingIndex(n) != step.nth {
+		return false
+	}
+	if step.last {
+		for s := n.NextSibling; s != nil; s = s.NextSibling {
+			if s.Type == html.ElementNode {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// siblingIndex 返回元素在兄弟元素中的位置，从1开始
+func siblingIndex(n *html.Node) int {
+	index := 1
+	for s := n.PrevSibling; s != nil; s = s.PrevSibling {
+		if s.Type == html.ElementNode {
+			index++
+		}
+	}
+	return index
+}
+
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+type selectorParser struct {
+	s   string
+	pos int
+}
+
+// complex 解析一个组合选择器，停在逗号或结尾
+func (p *selectorParser) complex() ([]selectorStep, error) {
+	var steps []selectorStep
+	var combinator byte
+	for {
+		p.skipSpace()
+		step, err := p.compound()
+		if err != nil {
+			return nil, err
+		}
+		step.combinator = combinator
+		steps = append(steps, step)
+
+		hadSpace := p.skipSpace()
+		switch {
+		case p.pos >= len(p.s) || p.s[p.pos] == ',':
+			return steps, nil
+		case p.s[p.pos] == '>':
+			combinator = '>'
+			p.pos++
+		case hadSpace:
+			combinator = ' '
+		default:
+			return nil, fmt.Errorf("位置%d处字符 %q 无效", p.pos, p.s[p.pos])
+		}
+	}
+}
+
+// compound 解析标签和紧随其后的 #id、.class、[attr]、伪类
+func (p *selectorParser) compound() (selectorStep, error) {
+	var step selectorStep
+	start := p.pos
+	if p.peek() == '*' {
+		p.pos++
+	} else if name := p.ident(); name != "" {
+		step.tag = strings.ToLower(name)
+	}
+
+	for p.pos < len(p.s) {
+		switch p.s[p.pos] {
+		case '#':
+			p.pos++
+			if step.id = p.ident(); step.id == "" {
+				return step, fmt.Errorf("位置%d处缺少id", p.pos)
+			}
+		case '.':
+			p.pos++
+			class := p.ident()
+			if class == "" {
+				return step, fmt.Errorf("位置%d处缺少class", p.pos)
+			}
+			step.classes = append(step.classes, class)
+		case '[':
+			attr, err := p.attr()
+			if err != nil {
+				return step, err
+			}
+			step.attrs = append(step.attrs, attr)
+		case ':':
+			if err := p.pseudo(&step); err != nil {
+				return step, err
+			}
+		default:
+			if p.pos == start {
+				return step, fmt.Errorf("位置%d处缺少选择器", p.pos)
+			}
+			return step, nil
+		}
+	}
+	if p.pos == start {
+		return step, fmt.Errorf("位置%d处缺少选择器", p.pos)
+	}
+	return step, nil
+}
+
+func (p *selectorParser) attr() (attrMatcher, error) {
+	var attr attrMatcher
+	p.pos++ // [
+	p.skipSpace()
+	if attr.key = p.ident(); attr.key == "" {
+		return attr, fmt.Errorf("位置%d处缺少属性名", p.pos)
+	}
+	p.skipSpace()
+	if p.peek() == '=' {
+		p.pos++
+		p.skipSpace()
+		attr.hasValue = true
+		if quote := p.peek(); quote == '"' || quote == '\'' {
+			end := strings.IndexByte(p.s[p.pos+1:], quote)
+			if end < 0 {
+				return attr, fmt.Errorf("属性值缺少结束引号")
+			}
+			attr.value = p.s[p.pos+1 : p.pos+1+end]
+			p.pos += end + 2
+		} else {
+			attr.value = p.ident()
+		}
+		p.skipSpace()
+	}
+	if p.peek() != ']' {
+		return attr, fmt.Errorf("位置%d处缺少 ]", p.pos)
+	}
+	p.pos++
+	return attr, nil
+}
+
+func (p *selectorParser) pseudo(step *selectorStep) error {
+	p.pos++ // :
+	switch name := p.ident(); name {
+	case "first-child":
+		step.nth = 1
+	case "last-child":
+		step.last = true
+	case "nth-child":
+		if p.peek() != '(' {
+			return fmt.Errorf("nth-child 缺少参数")
+		}
+		end := strings.IndexByte(p.s[p.pos:], ')')
+		if end < 0 {
+			return fmt.Errorf("nth-child 缺少 )")
+		}
+		n, err := strconv.Atoi(strings.TrimSpace(p.s[p.pos+1 : p.pos+end]))
+		if err != nil || n < 1 {
+			return fmt.Errorf("nth-child 只支持正整数参数")
+		}
+		step.nth = n
+		p.pos += end + 1
+	default:
+		return fmt.Errorf("不支持的伪类: %s", name)
+	}
+	return nil
+}
+
+func (p *selectorParser) ident() string {
+	start := p.pos
+	for p.pos < len(p.s) {
+		c := p.s[p.pos]
+		if c == '-' || c == '_' || c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' {
+			p.pos++
+			continue
+		}
+		break
+	}
+	return p.s[start:p.pos]
+}
+
+func (p *selectorParser) peek() byte {
+	if p.pos >= len(p.s) {
+		return 0
+	}
+	return p.s[p.pos]
+}
+
+func (p *selectorParser) skipSpace() bool {
+	start := p.pos
+	for p.pos < len(p.s) && (p.s[p.pos] == ' ' || p.s[p.pos] == '\t' || p.s[p.pos] == '\n') {
+		p.pos++
+	}
+	return p.pos > start
+}
@@ -0,0 +1,70 @@
+package lease
+
+import (
+	"log"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// LeaderLease 定时任务主实例的租约名称
+const LeaderLease = "cron-leader"
+
+var (
+	leader   atomic.Bool
+	stopOnce sync.Once
+	stopCh   = make(chan struct{})
+)
+
+// IsLeader 返回当前实例是否为执行定时任务的主实例
+func IsLeader() bool {
+	return leader.Load()
+}
+
+// StartElection 竞选主实例并在后台定期续期，主实例崩溃后租约过期由其他实例接管
+// 首次竞选同步完成，返回当前实例是否为主实例
+func StartElection(ttl time.Duration) bool {
+	elect(ttl)
+
+	go func() {
+		ticker := time.NewTicker(ttl / 3)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stopCh:
+				return
+			case <-ticker.C:
+				elect(ttl)
+			}
+		}
+	}()
+
+	return IsLeader()
+}
+
+// StopElection 停止竞选并释放主实例租约
+func StopElection() {
+	stopOnce.Do(func() {
+		close(stopCh)
+		if leader.Swap(false) {
+			if err := Release(LeaderLease); err != nil {
+				log.Printf("释放主实例租约失败: %v", err)
+			}
+		}
+	})
+}
+
+func elect(ttl time.Duration) {
+	ok, err := Acquire(LeaderLease, ttl)
+	if err != nil {
+		log.Printf("竞选定时任务主实例失败: %v", err)
+		ok = false
+	}
+	if was := leader.Swap(ok); was != ok {
+		if ok {
+			log.Printf("当前实例 %s 成为定时任务主实例", holder)
+		} else {
+			log.Printf("当前实例 %s 不再是定时任务主实例", holder)
+		}
+	}
+}
@@ -0,0 +1,139 @@
+package lease
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"os"
+	"sync"
+	"time"
+
+	"aimodels-prices/database"
+	"aimodels-prices/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// holder 当前实例的ID，进程内唯一
+var holder = newHolderID()
+
+func newHolderID() string {
+	host, err := os.Hostname()
+	if err != nil || host == "" {
+		host = "unknown"
+	}
+	b := make([]byte, 4)
+	_, _ = rand.Read(b)
+	return fmt.Sprintf("%s-%d-%s", host, os.Getpid(), hex.EncodeToString(b))
+}
+
+// Holder 返回当前实例的ID
+func Holder() string {
+	return holder
+}
+
+// Acquire 尝试获取租约，当前实例已持有时续期
+// 过期时间使用数据库时间计算，避免各实例时钟不一致
+func Acquire(name string, ttl time.Duration) (bool, error) {
+	db := database.DB
+	if db == nil {
+		return false, fmt.Errorf("获取数据库连接失败")
+	}
+
+	// 确保租约记录存在，新记录立即过期以便竞争
+	if err := db.Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&models.JobLease{Name: name, ExpiresAt: time.Unix(0, 0)}).Error; err != nil {
+		return false, err
+	}
+
+	if err := db.Model(&models.JobLease{}).
+		Where("name = ? AND (holder = ? OR expires_at < NOW())", name, holder).
+		Updates(map[string]interface{}{
+			"holder":     holder,
+			"expires_at": expiresAt(ttl),
+		}).Error; err != nil {
+		return false, err
+	}
+
+	// MySQL默认返回实际修改的行数，续期时值未变化会返回0，因此重新读取持有者判断
+	var count int64
+	if err := db.Model(&models.JobLease{}).
+		Where("name = ? AND holder = ? AND expires_at > NOW()", name, holder).
+		Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// Held 返回租约当前是否被任一实例持有
+func Held(name string) (bool, error) {
+	db := database.DB
+	if db == nil {
+		return false, nil
+	}
+	var count int64
+	err := db.Model(&models.JobLease{}).Where("name = ? AND expires_at > NOW()", name).Count(&count).Error
+	return count > 0, err
+}
+
+// Release 释放当前实例持有的租约
+func Release(name string) error {
+	db := database.DB
+	if db == nil {
+		return nil
+	}
+	return db.Model(&models.JobLease{}).
+		Where("name = ? AND holder = ?", name, holder).
+		Update("expires_at", gorm.Expr("NOW()")).Error
+}
+
+func expiresAt(ttl time.Duration) clause.Expr {
+	return gorm.Expr("DATE_ADD(NOW(), INTERVAL ? SECOND)", int(ttl/time.Second))
+}
+
+// Hold 获取租约并在后台定期续期，直到调用返回的释放函数
+// 返回的 ctx 在续期失败（租约被其他实例接管或数据库出错）或释放时取消，任务应在取消后停止
+// 未获取到租约时返回 false
+func Hold(parent context.Context, name string, ttl time.Duration) (ctx context.Context, release func(), ok bool, err error) {
+	ok, err = Acquire(name, ttl)
+	if err != nil || !ok {
+		return parent, func() {}, ok, err
+	}
+
+	ctx, cancel := context.WithCancel(parent)
+	go func() {
+		ticker := time.NewTicker(ttl / 3)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				held, err := Acquire(name, ttl)
+				if err != nil {
+					log.Printf("租约 %s 续期失败，停止任务: %v", name, err)
+					cancel()
+					return
+				}
+				if !held {
+					log.Printf("租约 %s 已被其他实例接管，停止任务", name)
+					cancel()
+					return
+				}
+			}
+		}
+	}()
+
+	var once sync.Once
+	return ctx, func() {
+		once.Do(func() {
+			cancel()
+			if err := Release(name); err != nil {
+				log.Printf("释放租约 %s 失败: %v", name, err)
+			}
+		})
+	}, true, nil
+}
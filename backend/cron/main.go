@@ -1,59 +1,157 @@
 package cron
 
 import (
+	"context"
+	"fmt"
 	"log"
+	"sync"
 	"time"
 
 	"github.com/robfig/cron/v3"
 
+	anthropic_api "aimodels-prices/cron/anthropic-api"
+	dashscope_api "aimodels-prices/cron/dashscope-api"
+	"aimodels-prices/cron/declarative"
+	deepseek_api "aimodels-prices/cron/deepseek-api"
+	gemini_api "aimodels-prices/cron/gemini-api"
+	"aimodels-prices/cron/lease"
+	moonshot_api "aimodels-prices/cron/moonshot-api"
 	openai_api "aimodels-prices/cron/openai-api"
 	openrouter_api "aimodels-prices/cron/openrouter-api"
 	price_audit "aimodels-prices/cron/price-audit"
+	"aimodels-prices/cron/scraper"
 	siliconflow_api "aimodels-prices/cron/siliconflow-api"
+	zhipu_api "aimodels-prices/cron/zhipu-api"
+	"aimodels-prices/database"
+	"aimodels-prices/events"
+	"aimodels-prices/models"
+	"aimodels-prices/webhooks"
 )
 
-var cronScheduler *cron.Cron
+var (
+	cronScheduler *cron.Cron
+	registerOnce  sync.Once
+
+	definitionsMu     sync.Mutex
+	definitionEntries = make(map[uint]definitionEntry) // 来源定义ID -> 已注册的定时任务
+)
+
+// definitionEntry 管理员配置的来源已注册的定时任务，定义修改后重新注册
+type definitionEntry struct {
+	id        cron.EntryID
+	spec      string
+	updatedAt time.Time
+}
+
+// RegisterSources 注册所有价格来源，注册顺序即启动时的执行顺序，可重复调用
+func RegisterSources() {
+	registerOnce.Do(registerSources)
+}
+
+func registerSources() {
+	scraper.Register(openrouter_api.Source{})
+	scraper.Register(siliconflow_api.Source{})
+	scraper.Register(openai_api.Source{})
+	scraper.Register(anthropic_api.Source{})
+	scraper.Register(gemini_api.Source{})
+	scraper.Register(deepseek_api.Source{})
+	scraper.Register(moonshot_api.Source{})
+	scraper.Register(zhipu_api.Source{})
+	scraper.Register(dashscope_api.Source{})
+}
+
+// LeaderTTL 主实例租约有效期
+const LeaderTTL = time.Minute
+
+// runSource 执行单个价格来源的抓取任务，只在主实例上执行
+func runSource(source scraper.PriceSource, trigger string) {
+	if !lease.IsLeader() {
+		return
+	}
+	if _, err := scraper.Run(context.Background(), source, trigger, ""); err != nil {
+		log.Printf("%s价格获取任务执行失败: %v", source.Name(), err)
+	}
+}
 
 // Init 初始化并启动所有定时任务
 func Init() {
 	log.Println("初始化定时任务...")
 
+	// 多实例部署时只有主实例执行定时任务，主实例崩溃后租约过期由其他实例接管
+	if lease.StartElection(LeaderTTL) {
+		log.Println("当前实例为定时任务主实例")
+	} else {
+		log.Println("当前实例不是定时任务主实例，定时任务将由主实例执行")
+	}
+
 	// 创建一个新的cron调度器，使用秒级精度
 	cronScheduler = cron.New(cron.WithSeconds())
 
-	// 注册价格获取任务
-	// 每4小时执行一次
-	_, err := cronScheduler.AddFunc("0 0 */4 * * *", func() {
-		if err := openrouter_api.FetchAndSavePrices(); err != nil {
-			log.Printf("OpenRouter价格获取任务执行失败: %v", err)
+	// 注册价格获取任务，执行计划可通过 CRON_SCHEDULES 按来源配置
+	RegisterSources()
+	schedules := scraper.Schedules()
+	for _, source := range scraper.Sources() {
+		source := source
+		spec := scraper.Schedule(schedules, source.Name())
+		if spec == "" {
+			log.Printf("%s价格获取任务已禁用", source.Name())
+			continue
 		}
 
-		// time.Sleep(3 * time.Second)
-
-		// if err := openrouter_api.UpdateOtherPrices(); err != nil {
-		// 	log.Printf("其他厂商价格更新任务执行失败: %v", err)
-		// }
-
-		time.Sleep(3 * time.Second)
+		if _, err := cronScheduler.AddFunc(spec, func() { runSource(source, models.ScrapeTriggerCron) }); err != nil {
+			log.Printf("注册%s价格获取定时任务失败: %v", source.Name(), err)
+		}
+	}
 
-		if err := siliconflow_api.UpdateSiliconFlowPrices(); err != nil {
-			log.Printf("SiliconFlow价格更新任务执行失败: %v", err)
+	// OpenRouter按上游供应商拆分价格，需通过 OPENROUTER_PROVIDER_PRICES 启用
+	if openrouter_api.ProviderPricesEnabled() {
+		if spec := scraper.Schedule(schedules, openrouter_api.ProviderJobName); spec != "" {
+			if _, err := cronScheduler.AddFunc(spec, func() {
+				if !lease.IsLeader() {
+					return
+				}
+				if err := openrouter_api.UpdateProviderPrices(context.Background(), models.ScrapeTriggerCron); err != nil {
+					log.Printf("OpenRouter供应商价格更新任务执行失败: %v", err)
+				}
+			}); err != nil {
+				log.Printf("注册OpenRouter供应商价格定时任务失败: %v", err)
+			}
 		}
+	}
 
-		time.Sleep(3 * time.Second)
+	// 管理员配置的来源保存在数据库中，每分钟同步一次，多实例部署时其他实例的修改也能生效
+	SyncDefinitions()
+	if _, err := cronScheduler.AddFunc("0 * * * * *", SyncDefinitions); err != nil {
+		log.Printf("注册来源定义同步任务失败: %v", err)
+	}
 
-		if err := openai_api.FetchAndSavePrices(); err != nil {
-			log.Printf("OpenAI官网价格获取任务执行失败: %v", err)
+	// 重试投递失败的Webhook，每分钟执行一次
+	if _, err := cronScheduler.AddFunc("30 * * * * *", func() {
+		if lease.IsLeader() {
+			webhooks.RetryDue()
 		}
-	})
+	}); err != nil {
+		log.Printf("注册Webhook重试定时任务失败: %v", err)
+	}
 
-	if err != nil {
-		log.Printf("注册价格更新定时任务失败: %v", err)
+	// 清理超过保留期的价格变更事件，每天凌晨3点执行
+	if _, err := cronScheduler.AddFunc("0 0 3 * * *", func() {
+		if !lease.IsLeader() {
+			return
+		}
+		if err := events.Prune(); err != nil {
+			log.Printf("清理价格变更事件失败: %v", err)
+		}
+	}); err != nil {
+		log.Printf("注册价格变更事件清理任务失败: %v", err)
 	}
 
 	// 注册价格审核检查任务
 	// 每5分钟执行一次
-	_, err = cronScheduler.AddFunc("0 */5 * * * *", func() {
+	_, err := cronScheduler.AddFunc("0 */5 * * * *", func() {
+		if !lease.IsLeader() {
+			return
+		}
 		if err := price_audit.CheckPendingPrices(); err != nil {
 			log.Printf("价格审核检查任务执行失败: %v", err)
 		}
@@ -71,38 +169,114 @@ func Init() {
 	go func() {
 		// 等待几秒钟，确保应用程序和数据库已完全初始化
 		time.Sleep(5 * time.Second)
-		log.Println("立即执行OpenRouter价格获取任务...")
-		if err := openrouter_api.FetchAndSavePrices(); err != nil {
-			log.Printf("初始OpenRouter价格获取任务执行失败: %v", err)
+		if !lease.IsLeader() {
+			return
 		}
 
-		// 等待几秒后执行其他厂商价格更新任务
-		// time.Sleep(3 * time.Second)
-		// log.Println("立即执行其他厂商价格更新任务...")
-		// if err := openrouter_api.UpdateOtherPrices(); err != nil {
-		// 	log.Printf("初始其他厂商价格更新任务执行失败: %v", err)
-		// }
+		for i, source := range scraper.Sources() {
+			if scraper.Schedule(schedules, source.Name()) == "" {
+				continue
+			}
+			// 各来源之间间隔几秒执行
+			if i > 0 {
+				time.Sleep(3 * time.Second)
+			}
+			log.Printf("立即执行%s价格获取任务...", source.Name())
+			runSource(source, models.ScrapeTriggerStartup)
+		}
+	}()
+}
 
-		// 等待几秒后执行SiliconFlow价格更新任务
-		time.Sleep(3 * time.Second)
-		log.Println("立即执行SiliconFlow价格更新任务...")
-		if err := siliconflow_api.UpdateSiliconFlowPrices(); err != nil {
-			log.Printf("初始SiliconFlow价格更新任务执行失败: %v", err)
+// SyncDefinitions 按数据库中的来源定义注册、更新和移除定时任务，定义修改后立即调用可使其生效
+func SyncDefinitions() {
+	if cronScheduler == nil || database.DB == nil {
+		return
+	}
+
+	var defs []models.SourceDefinition
+	if err := database.DB.Find(&defs).Error; err != nil {
+		log.Printf("加载来源定义失败: %v", err)
+		return
+	}
+
+	schedules := scraper.Schedules()
+	definitionsMu.Lock()
+	defer definitionsMu.Unlock()
+
+	active := make(map[uint]bool, len(defs))
+	for _, def := range defs {
+		spec := declarative.Schedule(def, schedules)
+		if spec == "" {
+			continue
 		}
+		active[def.ID] = true
 
-		// 等待几秒后执行OpenAI官网价格获取任务
-		time.Sleep(3 * time.Second)
-		log.Println("立即执行OpenAI官网价格获取任务...")
-		if err := openai_api.FetchAndSavePrices(); err != nil {
-			log.Printf("初始OpenAI官网价格获取任务执行失败: %v", err)
+		entry, exists := definitionEntries[def.ID]
+		if exists && entry.spec == spec && entry.updatedAt.Equal(def.UpdatedAt) {
+			continue
 		}
-	}()
+		if exists {
+			cronScheduler.Remove(entry.id)
+			delete(definitionEntries, def.ID)
+		}
+
+		source := declarative.Source{Definition: def}
+		id, err := cronScheduler.AddFunc(spec, func() { runSource(source, models.ScrapeTriggerCron) })
+		if err != nil {
+			log.Printf("注册%s价格获取定时任务失败: %v", def.Name, err)
+			continue
+		}
+		definitionEntries[def.ID] = definitionEntry{id: id, spec: spec, updatedAt: def.UpdatedAt}
+	}
+
+	// 移除已删除或禁用的来源
+	for defID, entry := range definitionEntries {
+		if !active[defID] {
+			cronScheduler.Remove(entry.id)
+			delete(definitionEntries, defID)
+		}
+	}
+}
+
+// DryRun 试运行指定来源（all 表示全部来源），返回将要写入的变化，不修改数据库
+func DryRun(name string) ([]*scraper.Preview, error) {
+	RegisterSources()
+
+	var sources []scraper.PriceSource
+	if name == "all" {
+		sources = scraper.Sources()
+	} else {
+		source, ok := scraper.Get(name)
+		if !ok {
+			// 管理员配置的来源
+			custom, found, err := declarative.Find(name)
+			if err != nil {
+				return nil, fmt.Errorf("查询来源定义失败: %v", err)
+			}
+			if !found {
+				return nil, fmt.Errorf("未知的价格来源: %s", name)
+			}
+			source = custom
+		}
+		sources = append(sources, source)
+	}
+
+	previews := make([]*scraper.Preview, 0, len(sources))
+	for _, source := range sources {
+		preview, err := scraper.DryRun(context.Background(), source)
+		if err != nil {
+			return previews, err
+		}
+		previews = append(previews, preview)
+	}
+	return previews, nil
 }
 
 // StopCronJobs 停止所有定时任务
 func StopCronJobs() {
 	if cronScheduler != nil {
 		cronScheduler.Stop()
+		lease.StopElection()
 		log.Println("定时任务已停止")
 	}
 }
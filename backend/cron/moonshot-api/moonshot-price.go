@@ -0,0 +1,163 @@
+package moonshot_api
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"strings"
+
+	"aimodels-prices/cron/htmlutil"
+	"aimodels-prices/cron/scraper"
+	"aimodels-prices/httpclient"
+	"aimodels-prices/models"
+
+	"golang.org/x/net/html"
+)
+
+const (
+	MoonshotPricingURL  = "https://platform.moonshot.cn/docs/pricing/chat"
+	MoonshotChannelType = 29
+	BillingType         = "tokens"
+	Currency            = "CNY"
+	PriceSource         = "https://platform.moonshot.cn/docs/pricing/chat"
+)
+
+// MoonshotModelPrice 从页面解析出的模型价格数据，价格单位均为 元/1M tokens
+type MoonshotModelPrice struct {
+	Model       string
+	InputPrice  float64 // 输入（缓存未命中）
+	OutputPrice float64
+	CachedPrice float64 // 输入（缓存命中）
+}
+
+// Source Moonshot价格来源，已审核的模型自动更新，新模型进入待审核队列
+type Source struct{}
+
+// Name 来源名称
+func (Source) Name() string { return "moonshot" }
+
+// ChannelType 厂商ID
+func (Source) ChannelType() uint { return MoonshotChannelType }
+
+// Approval 默认审核策略
+func (Source) Approval() scraper.ApprovalPolicy { return scraper.ApproveKnown }
+
+// Fetch 抓取定价页面并转换为价格记录
+func (Source) Fetch(ctx context.Context) ([]scraper.ScrapedPrice, error) {
+	prices, err := fetchMoonshotPrices(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]scraper.ScrapedPrice, 0, len(prices))
+	for _, mp := range prices {
+		result = append(result, scraper.ScrapedPrice{Price: buildPrice(mp)})
+	}
+	return result, nil
+}
+
+// fetchMoonshotPrices 抓取Moonshot定价页面并解析价格表格
+func fetchMoonshotPrices(ctx context.Context) ([]MoonshotModelPrice, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", MoonshotPricingURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("创建HTTP请求失败: %v", err)
+	}
+	body, err := httpclient.For("moonshot").Fetch(req)
+	if err != nil {
+		return nil, fmt.Errorf("请求Moonshot定价页面失败: %v", err)
+	}
+
+	return parseHTMLPrices(string(body))
+}
+
+// buildPrice 将解析结果转换为价格记录
+func buildPrice(mp MoonshotModelPrice) models.Price {
+	price := models.Price{
+		Model:       mp.Model,
+		BillingType: BillingType,
+		ChannelType: MoonshotChannelType,
+		Currency:    Currency,
+		InputPrice:  mp.InputPrice,
+		OutputPrice: mp.OutputPrice,
+		PriceSource: PriceSource,
+	}
+
+	// 缓存命中价格
+	if mp.CachedPrice > 0 {
+		cached := mp.CachedPrice
+		price.CachedTokens = &cached
+	}
+
+	return price
+}
+
+// parseHTMLPrices 从HTML中解析价格表格
+// 价格表包含 模型/计费单位/输入价格（缓存命中）/输入价格（缓存未命中）/输出价格 列，价格为每百万tokens
+func parseHTMLPrices(htmlContent string) ([]MoonshotModelPrice, error) {
+	doc, err := html.Parse(strings.NewReader(htmlContent))
+	if err != nil {
+		return nil, fmt.Errorf("解析HTML失败: %v", err)
+	}
+
+	var prices []MoonshotModelPrice
+	seen := make(map[string]bool)
+
+	for _, table := range htmlutil.Tables(doc) {
+		modelCol := table.Column("模型")
+		cachedCol := table.Column("缓存命中")
+		inputCol := table.Column("缓存未命中")
+		if inputCol < 0 {
+			inputCol = table.Column("输入")
+			if inputCol == cachedCol {
+				inputCol = -1
+			}
+		}
+		outputCol := table.Column("输出")
+		if modelCol < 0 || inputCol < 0 || outputCol < 0 {
+			continue
+		}
+
+		for _, row := range table.Rows {
+			if len(row) <= modelCol || len(row) <= inputCol || len(row) <= outputCol {
+				continue
+			}
+			model := strings.ToLower(strings.TrimSpace(row[modelCol]))
+			if model == "" || strings.Contains(model, " ") || seen[model] {
+				continue
+			}
+
+			input, err := htmlutil.ParseAmount(row[inputCol])
+			if err != nil {
+				log.Printf("解析输入价格失败 %s: %v", model, err)
+				continue
+			}
+			output, err := htmlutil.ParseAmount(row[outputCol])
+			if err != nil {
+				log.Printf("解析输出价格失败 %s: %v", model, err)
+				continue
+			}
+
+			mp := MoonshotModelPrice{Model: model, InputPrice: roundPrice(input), OutputPrice: roundPrice(output)}
+			if cachedCol >= 0 && cachedCol < len(row) {
+				if cached, err := htmlutil.ParseAmount(row[cachedCol]); err == nil {
+					mp.CachedPrice = roundPrice(cached)
+				}
+			}
+
+			seen[model] = true
+			prices = append(prices, mp)
+		}
+	}
+
+	if len(prices) == 0 {
+		return nil, fmt.Errorf("未找到Moonshot模型价格表格")
+	}
+	return prices, nil
+}
+
+// roundPrice 四舍五入到6位小数
+func roundPrice(price float64) float64 {
+	return math.Round(price*1000000) / 1000000
+}
@@ -0,0 +1,33 @@
+package moonshot_api
+
+import (
+	"os"
+	"testing"
+)
+
+func TestParseHTMLPrices(t *testing.T) {
+	content, err := os.ReadFile("testdata/pricing.html")
+	if err != nil {
+		t.Fatalf("读取测试页面失败: %v", err)
+	}
+
+	prices, err := parseHTMLPrices(string(content))
+	if err != nil {
+		t.Fatalf("parseHTMLPrices 失败: %v", err)
+	}
+
+	want := []MoonshotModelPrice{
+		{Model: "kimi-k2-0905-preview", InputPrice: 4, OutputPrice: 16, CachedPrice: 1},
+		{Model: "kimi-k2-turbo-preview", InputPrice: 8, OutputPrice: 58, CachedPrice: 1},
+		{Model: "moonshot-v1-8k", InputPrice: 2, OutputPrice: 10},
+		{Model: "moonshot-v1-128k", InputPrice: 10, OutputPrice: 30},
+	}
+	if len(prices) != len(want) {
+		t.Fatalf("解析到 %d 个模型，期望 %d 个: %+v", len(prices), len(want), prices)
+	}
+	for i := range want {
+		if prices[i] != want[i] {
+			t.Errorf("价格错误: got %+v, want %+v", prices[i], want[i])
+		}
+	}
+}
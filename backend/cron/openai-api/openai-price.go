@@ -1,22 +1,19 @@
 package openai_api
 
 import (
-	"errors"
+	"context"
 	"fmt"
-	"io"
 	"log"
 	"math"
 	"net/http"
-	"strconv"
 	"strings"
 
-	"aimodels-prices/database"
-	"aimodels-prices/handlers"
-	"aimodels-prices/handlers/one_hub"
+	"aimodels-prices/cron/htmlutil"
+	"aimodels-prices/cron/scraper"
+	"aimodels-prices/httpclient"
 	"aimodels-prices/models"
 
 	"golang.org/x/net/html"
-	"gorm.io/gorm"
 )
 
 const (
@@ -25,293 +22,374 @@ const (
 	BillingType        = "tokens"
 	Currency           = "USD"
 	PriceSource        = "https://developers.openai.com/api/docs/pricing"
-	Status             = "approved"
-	CreatedBy          = "cron自动任务"
 )
 
-// OpenAIModelPrice 从页面解析出的模型价格数据
+// OpenAIModelPrice 从页面解析出的模型价格数据，价格均为 $/1M tokens，图片生成为 $/张
 type OpenAIModelPrice struct {
-	Model       string
-	InputPrice  float64 // $/1M tokens
-	CachedPrice float64 // $/1M tokens
-	OutputPrice float64 // $/1M tokens
+	Model            string
+	InputPrice       float64
+	CachedPrice      float64
+	OutputPrice      float64
+	InputAudioPrice  float64
+	OutputAudioPrice float64
+	InputImagePrice  float64
+	OutputImagePrice float64
+	ImagePrice       float64              // 图片生成每张价格，仅在没有token价格时使用
+	ImageResolution  string               // 图片生成价格对应的分辨率
+	Tiers            map[string]TierPrice // batch、flex、priority 服务等级的文本价格
 }
 
-// FetchAndSavePrices 抓取OpenAI官网价格页面并保存到数据库
-func FetchAndSavePrices() error {
-	log.Println("开始获取OpenAI官网价格数据...")
+// TierPrice 非Standard服务等级的文本价格
+type TierPrice struct {
+	InputPrice  float64
+	OutputPrice float64
+}
 
-	// 抓取页面
-	prices, err := fetchOpenAIPrices()
-	if err != nil {
-		return fmt.Errorf("获取OpenAI价格数据失败: %v", err)
-	}
+// 价格表格类型，按表格前的标题判断
+const (
+	tableText            = "text"
+	tableAudio           = "audio"
+	tableImage           = "image"
+	tableImageGeneration = "image_generation"
+	tableFineTuning      = "fine_tuning"
+)
 
-	if len(prices) == 0 {
-		return fmt.Errorf("未解析到任何OpenAI价格数据")
-	}
+// tierModifiers 服务等级面板对应的价格调整类型
+var tierModifiers = map[string]string{
+	"batch":    models.ModifierBatch,
+	"flex":     models.ModifierFlex,
+	"priority": models.ModifierPriority,
+}
 
-	log.Printf("成功解析到 %d 个OpenAI模型价格", len(prices))
+// Source OpenAI官网价格来源，价格自动审核通过
+type Source struct{}
 
-	// 获取数据库连接
-	db := database.DB
-	if db == nil {
-		return fmt.Errorf("获取数据库连接失败")
-	}
+// Name 来源名称
+func (Source) Name() string { return "openai" }
 
-	processedCount := 0
-	skippedCount := 0
+// ChannelType 厂商ID
+func (Source) ChannelType() uint { return OpenAIChannelType }
 
-	for _, mp := range prices {
-		// 构建Price对象
-		price := models.Price{
-			Model:       mp.Model,
-			BillingType: BillingType,
-			ChannelType: OpenAIChannelType,
-			Currency:    Currency,
-			InputPrice:  mp.InputPrice,
-			OutputPrice: mp.OutputPrice,
-			PriceSource: PriceSource,
-			Status:      Status,
-			CreatedBy:   CreatedBy,
-		}
+// Approval 默认审核策略
+func (Source) Approval() scraper.ApprovalPolicy { return scraper.ApproveAll }
 
-		// 设置缓存价格（如果有）
-		if mp.CachedPrice > 0 {
-			cachedPrice := mp.CachedPrice
-			price.CachedTokens = &cachedPrice
-		}
+// Fetch 抓取OpenAI官网价格页面并转换为价格记录
+func (Source) Fetch(ctx context.Context) ([]scraper.ScrapedPrice, error) {
+	prices, err := fetchOpenAIPrices(ctx)
+	if err != nil {
+		return nil, err
+	}
 
-		// 检查是否已存在相同模型的价格记录
-		var existingPrice models.Price
-		result := db.Where("model = ? AND channel_type = ?", mp.Model, OpenAIChannelType).First(&existingPrice)
+	result := make([]scraper.ScrapedPrice, 0, len(prices))
+	for _, mp := range prices {
+		result = append(result, scraper.ScrapedPrice{Price: buildPrice(mp)})
+	}
+	return result, nil
+}
 
-		if result.Error == nil {
-			// 记录存在，执行更新
-			_, changed, err := handlers.ProcessPrice(price, &existingPrice, true, CreatedBy)
-			if err != nil {
-				log.Printf("更新价格记录失败 %s: %v", mp.Model, err)
-				skippedCount++
-				continue
-			}
-			if changed {
-				processedCount++
-			} else {
-				skippedCount++
-			}
-		} else if errors.Is(result.Error, gorm.ErrRecordNotFound) {
-			// 记录不存在，创建新记录
-			var pendingCount int64
-			if err := db.Model(&models.Price{}).Where("model = ? AND channel_type = ? AND status = 'pending'",
-				mp.Model, OpenAIChannelType).Count(&pendingCount).Error; err != nil {
-				log.Printf("检查待审核记录失败 %s: %v", mp.Model, err)
-			}
+// buildPrice 将解析结果转换为价格记录
+// 音频、图片token价格写入扩展价格字段，batch/flex/priority 价格以价格调整规则表示
+// 只有图片生成价格的模型按张计费
+func buildPrice(mp OpenAIModelPrice) models.Price {
+	price := models.Price{
+		Model:       mp.Model,
+		BillingType: BillingType,
+		ChannelType: OpenAIChannelType,
+		Currency:    Currency,
+		InputPrice:  mp.InputPrice,
+		OutputPrice: mp.OutputPrice,
+		PriceSource: PriceSource,
+	}
 
-			if pendingCount > 0 {
-				log.Printf("已存在待审核的相同模型记录，跳过创建: %s", mp.Model)
-				skippedCount++
-				continue
-			}
+	if !mp.hasTextPrice() {
+		switch {
+		case mp.InputAudioPrice > 0 || mp.OutputAudioPrice > 0:
+			// 只有音频价格的模型，基础价格使用音频价格
+			price.InputPrice, price.OutputPrice = mp.InputAudioPrice, mp.OutputAudioPrice
+		case mp.InputImagePrice > 0 || mp.OutputImagePrice > 0:
+			price.InputPrice, price.OutputPrice = mp.InputImagePrice, mp.OutputImagePrice
+		case mp.ImagePrice > 0:
+			price.BillingUnit = models.BillingUnitImage
+			price.BillingType = models.BillingTypeForUnit(models.BillingUnitImage)
+			price.UnitMeta = &models.UnitMeta{Resolution: mp.ImageResolution}
+			price.InputPrice, price.OutputPrice = mp.ImagePrice, mp.ImagePrice
+			return price
+		}
+	}
 
-			_, changed, err := handlers.ProcessPrice(price, nil, true, CreatedBy)
-			if err != nil {
-				log.Printf("创建价格记录失败 %s: %v", mp.Model, err)
-				skippedCount++
-				continue
-			}
-			if changed {
-				processedCount++
-			} else {
-				log.Printf("价格创建失败: %s", mp.Model)
-				skippedCount++
-			}
-		} else {
-			log.Printf("查询价格记录时发生错误 %s: %v", mp.Model, result.Error)
-			skippedCount++
+	// 设置缓存价格（如果有）
+	if mp.CachedPrice > 0 {
+		price.CachedTokens = optional(mp.CachedPrice)
+	}
+	price.InputAudioTokens = optional(mp.InputAudioPrice)
+	price.OutputAudioTokens = optional(mp.OutputAudioPrice)
+	price.InputImageTokens = optional(mp.InputImagePrice)
+	price.OutputImageTokens = optional(mp.OutputImagePrice)
+
+	// 按固定顺序生成调整规则，避免每次抓取顺序不同被当作变化
+	for _, tier := range []string{"batch", "flex", "priority"} {
+		tp, ok := mp.Tiers[tier]
+		if !ok || tp.InputPrice <= 0 || tp.OutputPrice <= 0 || price.InputPrice <= 0 || price.OutputPrice <= 0 {
+			continue
 		}
+		price.Modifiers = append(price.Modifiers, models.PriceModifier{
+			Kind:             tierModifiers[tier],
+			InputMultiplier:  roundPrice(tp.InputPrice / price.InputPrice),
+			OutputMultiplier: roundPrice(tp.OutputPrice / price.OutputPrice),
+		})
 	}
 
-	log.Printf("OpenAI官网价格数据处理完成，成功处理: %d, 跳过: %d", processedCount, skippedCount)
+	return price
+}
+
+func (mp OpenAIModelPrice) hasTextPrice() bool {
+	return mp.InputPrice > 0 || mp.OutputPrice > 0
+}
 
-	// 清除倍率缓存
-	one_hub.ClearRatesCache()
-	log.Println("倍率缓存已清除")
-	return nil
+// optional 价格大于0时返回指针，否则返回nil
+func optional(v float64) *float64 {
+	if v <= 0 {
+		return nil
+	}
+	return &v
 }
 
 // fetchOpenAIPrices 抓取OpenAI定价页面并解析价格表格
-func fetchOpenAIPrices() ([]OpenAIModelPrice, error) {
-	req, err := http.NewRequest("GET", OpenAIPricingURL, nil)
+func fetchOpenAIPrices(ctx context.Context) ([]OpenAIModelPrice, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", OpenAIPricingURL, nil)
 	if err != nil {
 		return nil, fmt.Errorf("创建HTTP请求失败: %v", err)
 	}
-	req.Header.Set("User-Agent", "Mozilla/5.0 (compatible; PriceBot/1.0)")
-
-	client := &http.Client{}
-	resp, err := client.Do(req)
+	body, err := httpclient.For("openai").Fetch(req)
 	if err != nil {
 		return nil, fmt.Errorf("请求OpenAI定价页面失败: %v", err)
 	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("OpenAI定价页面返回状态码: %d", resp.StatusCode)
-	}
-
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, fmt.Errorf("读取响应内容失败: %v", err)
-	}
 
 	return parseHTMLPrices(string(body))
 }
 
+// pricedTable 页面中的价格表格及其所在的标题和服务等级面板
+type pricedTable struct {
+	kind  string
+	tier  string // standard、batch、flex、priority，不在面板中的表格视为standard
+	table htmlutil.Table
+}
+
 // parseHTMLPrices 从HTML中解析价格表格
-// 只提取 Standard tier 的 Text tokens 表格（data-value="standard" 且列头为 Model/Input/Cached input/Output 的第一个表格）
+// 按表格前最近的标题区分文本、音频、图片token、图片生成和微调表格，
+// 按所在的 data-content-switcher-pane 面板区分 Standard/Batch/Flex/Priority 服务等级
 func parseHTMLPrices(htmlContent string) ([]OpenAIModelPrice, error) {
 	doc, err := html.Parse(strings.NewReader(htmlContent))
 	if err != nil {
 		return nil, fmt.Errorf("解析HTML失败: %v", err)
 	}
 
-	// 查找 data-content-switcher-pane + data-value="standard" 的容器
-	standardPanes := findStandardPanes(doc)
-	if len(standardPanes) == 0 {
-		return nil, fmt.Errorf("未找到 Standard tier 的价格面板")
+	var tables []pricedTable
+	section := ""
+	collectTables(doc, "standard", &section, &tables)
+
+	var order []string
+	byModel := make(map[string]*OpenAIModelPrice)
+	get := func(model string) *OpenAIModelPrice {
+		mp, ok := byModel[model]
+		if !ok {
+			mp = &OpenAIModelPrice{Model: model, Tiers: make(map[string]TierPrice)}
+			byModel[model] = mp
+			order = append(order, model)
+		}
+		return mp
 	}
 
-	// 在 standard pane 中查找第一个4列（Model/Input/Cached input/Output）的表格
-	for _, pane := range standardPanes {
-		tables := findElements(pane, "table")
-		for _, table := range tables {
-			headers := getTableHeaders(table)
-			if len(headers) != 4 {
+	// 先解析Standard价格，服务等级价格需要与之对应
+	for _, standard := range []bool{true, false} {
+		for _, pt := range tables {
+			if (pt.tier == "standard") != standard {
 				continue
 			}
-			// 确认是 Model/Input/Cached*/Output 结构（排除带 Training 列的 Fine-tuning 表格）
-			h0 := strings.ToLower(strings.TrimSpace(headers[0]))
-			h1 := strings.ToLower(strings.TrimSpace(headers[1]))
-			h3 := strings.ToLower(strings.TrimSpace(headers[3]))
-			if h0 == "model" && h1 == "input" && h3 == "output" {
-				prices := parseTable(table)
-				if len(prices) > 0 {
-					return prices, nil
-				}
-			}
+			parseTable(pt, get)
+		}
+	}
+
+	prices := make([]OpenAIModelPrice, 0, len(order))
+	hasText := false
+	for _, model := range order {
+		mp := byModel[model]
+		if !mp.hasTextPrice() && mp.InputAudioPrice == 0 && mp.OutputAudioPrice == 0 &&
+			mp.InputImagePrice == 0 && mp.OutputImagePrice == 0 && mp.ImagePrice == 0 {
+			// 只出现在服务等级面板中的模型没有基础价格
+			log.Printf("跳过没有Standard价格的模型: %s", model)
+			continue
 		}
+		hasText = hasText || mp.hasTextPrice()
+		prices = append(prices, *mp)
 	}
 
-	return nil, fmt.Errorf("未找到 Standard tier 的 Text tokens 价格表格")
+	if !hasText {
+		return nil, fmt.Errorf("未找到 Standard tier 的 Text tokens 价格表格")
+	}
+	return prices, nil
 }
 
-// findStandardPanes 查找所有 data-content-switcher-pane + data-value="standard" 的元素
-func findStandardPanes(n *html.Node) []*html.Node {
-	var result []*html.Node
+// collectTables 按文档顺序收集表格，记录每个表格前最近的标题和所在的服务等级面板
+func collectTables(n *html.Node, tier string, section *string, out *[]pricedTable) {
 	if n.Type == html.ElementNode {
-		isPane := false
-		isStandard := false
-		for _, attr := range n.Attr {
-			if attr.Key == "data-content-switcher-pane" {
-				isPane = true
-			}
-			if attr.Key == "data-value" && attr.Val == "standard" {
-				isStandard = true
+		switch n.Data {
+		case "h1", "h2", "h3", "h4":
+			*section = htmlutil.TextContent(n)
+		case "table":
+			table := htmlutil.Table{Headers: htmlutil.TableHeaders(n)}
+			for _, tr := range htmlutil.TableRows(n) {
+				table.Rows = append(table.Rows, htmlutil.RowCells(tr))
 			}
+			*out = append(*out, pricedTable{kind: tableKind(*section, table), tier: tier, table: table})
+			return
 		}
-		if isPane && isStandard {
-			result = append(result, n)
+		if _, isPane := htmlutil.Attr(n, "data-content-switcher-pane"); isPane {
+			if value, ok := htmlutil.Attr(n, "data-value"); ok {
+				tier = strings.ToLower(value)
+			}
 		}
 	}
 	for c := n.FirstChild; c != nil; c = c.NextSibling {
-		result = append(result, findStandardPanes(c)...)
+		collectTables(c, tier, section, out)
 	}
-	return result
 }
 
-// parseTable 解析单个表格，提取价格数据
-func parseTable(table *html.Node) []OpenAIModelPrice {
-	// 获取thead中的列头
-	headers := getTableHeaders(table)
-	if len(headers) == 0 {
-		return nil
+// tableKind 根据标题和列头判断表格类型
+func tableKind(section string, table htmlutil.Table) string {
+	section = strings.ToLower(section)
+	switch {
+	case strings.Contains(section, "fine-tuning") || table.Column("training") >= 0:
+		return tableFineTuning
+	case strings.Contains(section, "image generation"):
+		return tableImageGeneration
+	case strings.Contains(section, "audio"):
+		return tableAudio
+	case strings.Contains(section, "image"):
+		return tableImage
+	default:
+		return tableText
 	}
+}
 
-	// 检查是否为价格表格（需要有Model、Input、Output列）
-	colMap := make(map[string]int)
-	for i, h := range headers {
-		hLower := strings.ToLower(strings.TrimSpace(h))
-		switch {
-		case hLower == "model":
-			colMap["model"] = i
-		case hLower == "input":
-			colMap["input"] = i
-		case strings.Contains(hLower, "cached"):
-			colMap["cached"] = i
-		case hLower == "output":
-			colMap["output"] = i
+// parseTable 解析单个表格，将价格写入对应模型
+func parseTable(pt pricedTable, get func(model string) *OpenAIModelPrice) {
+	table := pt.table
+	if pt.kind == tableImageGeneration {
+		if pt.tier == "standard" {
+			parseImageGenerationTable(table, get)
 		}
+		return
 	}
 
-	// 必须同时有model、input、output列
-	if _, ok := colMap["model"]; !ok {
-		return nil
-	}
-	if _, ok := colMap["input"]; !ok {
-		return nil
-	}
-	if _, ok := colMap["output"]; !ok {
-		return nil
+	modelCol := table.Column("model")
+	inputCol := exactColumn(table, "input")
+	outputCol := exactColumn(table, "output")
+	cachedCol := table.Column("cached")
+	if modelCol < 0 || inputCol < 0 || outputCol < 0 {
+		return
 	}
 
-	// 获取tbody中的数据行
-	rows := getTableRows(table)
-	var prices []OpenAIModelPrice
+	// 微调模型和音频、图片token只解析Standard价格
+	if pt.tier != "standard" && pt.kind != tableText {
+		return
+	}
 
-	for _, row := range rows {
-		cells := getRowCells(row)
-		if len(cells) <= colMap["output"] {
+	for _, cells := range table.Rows {
+		if len(cells) <= outputCol || len(cells) <= inputCol || len(cells) <= modelCol {
 			continue
 		}
-
-		modelName := strings.TrimSpace(cells[colMap["model"]])
-		if modelName == "" {
+		model := strings.TrimSpace(cells[modelCol])
+		if model == "" {
 			continue
 		}
+		if pt.kind == tableFineTuning {
+			// 微调后的模型以 ft: 前缀区分，价格为推理价格，不含训练费用
+			model = "ft:" + model
+		}
 
-		inputStr := strings.TrimSpace(cells[colMap["input"]])
-		outputStr := strings.TrimSpace(cells[colMap["output"]])
-
-		inputPrice, err := parseDollarPrice(inputStr)
+		input, err := parseDollarPrice(cells[inputCol])
 		if err != nil {
-			log.Printf("解析输入价格失败 %s: %v", modelName, err)
+			log.Printf("解析输入价格失败 %s: %v", model, err)
 			continue
 		}
-
-		outputPrice, err := parseDollarPrice(outputStr)
+		output, err := parseDollarPrice(cells[outputCol])
 		if err != nil {
-			log.Printf("解析输出价格失败 %s: %v", modelName, err)
+			log.Printf("解析输出价格失败 %s: %v", model, err)
 			continue
 		}
 
-		mp := OpenAIModelPrice{
-			Model:       modelName,
-			InputPrice:  inputPrice,
-			OutputPrice: outputPrice,
+		if pt.tier != "standard" {
+			// 服务等级价格只记录已有Standard价格的模型
+			if _, ok := tierModifiers[pt.tier]; !ok {
+				continue
+			}
+			get(model).Tiers[pt.tier] = TierPrice{InputPrice: input, OutputPrice: output}
+			continue
 		}
 
-		// 解析缓存价格（可选）
-		if cachedIdx, ok := colMap["cached"]; ok && cachedIdx < len(cells) {
-			cachedStr := strings.TrimSpace(cells[cachedIdx])
-			cachedPrice, err := parseDollarPrice(cachedStr)
-			if err == nil {
-				mp.CachedPrice = cachedPrice
+		mp := get(model)
+		switch pt.kind {
+		case tableAudio:
+			mp.InputAudioPrice, mp.OutputAudioPrice = input, output
+		case tableImage:
+			mp.InputImagePrice, mp.OutputImagePrice = input, output
+		default:
+			if mp.hasTextPrice() {
+				// 同一模型在多个文本表格中出现时使用第一个
+				continue
+			}
+			mp.InputPrice, mp.OutputPrice = input, output
+			if cachedCol >= 0 && cachedCol < len(cells) {
+				if cached, err := parseDollarPrice(cells[cachedCol]); err == nil {
+					mp.CachedPrice = cached
+				}
 			}
 		}
+	}
+}
+
+// parseImageGenerationTable 解析按张计费的图片生成价格表
+// 表格列为 Model/Quality/各分辨率，同一模型的多个质量档位合并单元格，取第一个档位第一个分辨率的价格
+func parseImageGenerationTable(table htmlutil.Table, get func(model string) *OpenAIModelPrice) {
+	modelCol := table.Column("model")
+	qualityCol := table.Column("quality")
+	if modelCol < 0 || qualityCol < 0 || qualityCol+1 >= len(table.Headers) {
+		return
+	}
+	priceCol := qualityCol + 1
+	resolution := strings.ReplaceAll(table.Headers[priceCol], " ", "")
+
+	seen := make(map[string]bool)
+	for _, cells := range table.Rows {
+		if len(cells) < len(table.Headers) {
+			// 合并单元格的后续档位
+			continue
+		}
+		model := strings.TrimSpace(cells[modelCol])
+		if model == "" || seen[model] {
+			continue
+		}
+		price, err := parseDollarPrice(cells[priceCol])
+		if err != nil || price <= 0 {
+			continue
+		}
+		seen[model] = true
 
-		prices = append(prices, mp)
+		mp := get(model)
+		mp.ImagePrice = price
+		mp.ImageResolution = resolution
 	}
+}
 
-	return prices
+// exactColumn 返回列头与名称完全相同的列下标（不区分大小写），避免 input 匹配到 Cached input
+func exactColumn(table htmlutil.Table, name string) int {
+	for i, h := range table.Headers {
+		if strings.EqualFold(strings.TrimSpace(h), name) {
+			return i
+		}
+	}
+	return -1
 }
 
 // parseDollarPrice 解析美元价格字符串，如 "$2.50" -> 2.5 (per 1M tokens)
@@ -322,75 +400,14 @@ func parseDollarPrice(s string) (float64, error) {
 		return 0, nil
 	}
 
-	// 移除 $ 符号
-	s = strings.TrimPrefix(s, "$")
-	s = strings.TrimSpace(s)
-
-	price, err := strconv.ParseFloat(s, 64)
+	price, err := htmlutil.ParseAmount(s)
 	if err != nil {
 		return 0, fmt.Errorf("无法解析价格: %s", s)
 	}
-
-	// 四舍五入到6位小数
-	result := math.Round(price*1000000) / 1000000
-	return result, nil
-}
-
-// findElements 递归查找指定标签名的所有元素
-func findElements(n *html.Node, tag string) []*html.Node {
-	var result []*html.Node
-	if n.Type == html.ElementNode && n.Data == tag {
-		result = append(result, n)
-	}
-	for c := n.FirstChild; c != nil; c = c.NextSibling {
-		result = append(result, findElements(c, tag)...)
-	}
-	return result
-}
-
-// getTextContent 递归获取节点的文本内容
-func getTextContent(n *html.Node) string {
-	if n.Type == html.TextNode {
-		return n.Data
-	}
-	var sb strings.Builder
-	for c := n.FirstChild; c != nil; c = c.NextSibling {
-		sb.WriteString(getTextContent(c))
-	}
-	return sb.String()
-}
-
-// getTableHeaders 获取表格thead中的列头文本
-func getTableHeaders(table *html.Node) []string {
-	var headers []string
-	// 先找thead
-	theads := findElements(table, "thead")
-	if len(theads) == 0 {
-		return nil
-	}
-	// 在thead中找th
-	ths := findElements(theads[0], "th")
-	for _, th := range ths {
-		headers = append(headers, getTextContent(th))
-	}
-	return headers
+	return roundPrice(price), nil
 }
 
-// getTableRows 获取表格tbody中的所有tr
-func getTableRows(table *html.Node) []*html.Node {
-	tbodies := findElements(table, "tbody")
-	if len(tbodies) == 0 {
-		return nil
-	}
-	return findElements(tbodies[0], "tr")
-}
-
-// getRowCells 获取一行中所有td的文本
-func getRowCells(tr *html.Node) []string {
-	var cells []string
-	tds := findElements(tr, "td")
-	for _, td := range tds {
-		cells = append(cells, getTextContent(td))
-	}
-	return cells
+// roundPrice 四舍五入到6位小数
+func roundPrice(price float64) float64 {
+	return math.Round(price*1000000) / 1000000
 }
@@ -1,34 +1,129 @@
 package openai_api
 
 import (
-	"fmt"
+	"os"
 	"testing"
+
+	"aimodels-prices/models"
 )
 
-func TestFetchOpenAIPrices(t *testing.T) {
-	prices, err := fetchOpenAIPrices()
+func loadPrices(t *testing.T) map[string]OpenAIModelPrice {
+	t.Helper()
+	content, err := os.ReadFile("testdata/pricing.html")
 	if err != nil {
-		t.Fatalf("fetchOpenAIPrices 失败: %v", err)
+		t.Fatalf("读取测试页面失败: %v", err)
 	}
 
-	if len(prices) == 0 {
-		t.Fatal("未解析到任何价格数据")
+	prices, err := parseHTMLPrices(string(content))
+	if err != nil {
+		t.Fatalf("parseHTMLPrices 失败: %v", err)
 	}
 
-	fmt.Printf("共解析到 %d 个模型价格:\n\n", len(prices))
-	fmt.Printf("%-35s %12s %12s %12s\n", "Model", "Input", "Cached", "Output")
-	fmt.Printf("%-35s %12s %12s %12s\n", "-----", "-----", "------", "------")
-
+	byModel := make(map[string]OpenAIModelPrice, len(prices))
 	for _, p := range prices {
-		cachedStr := "-"
-		if p.CachedPrice > 0 {
-			cachedStr = fmt.Sprintf("$%.3f", p.CachedPrice)
+		byModel[p.Model] = p
+	}
+	return byModel
+}
+
+func TestParseTextPrices(t *testing.T) {
+	prices := loadPrices(t)
+
+	gpt41 := prices["gpt-4.1"]
+	if gpt41.InputPrice != 2 || gpt41.CachedPrice != 0.5 || gpt41.OutputPrice != 8 {
+		t.Errorf("gpt-4.1 文本价格错误: %+v", gpt41)
+	}
+	if o1 := prices["o1-pro"]; o1.InputPrice != 150 || o1.CachedPrice != 0 || o1.OutputPrice != 600 {
+		t.Errorf("o1-pro 文本价格错误: %+v", o1)
+	}
+	if _, ok := prices["batch-only-model"]; ok {
+		t.Error("只出现在Batch面板中的模型不应解析")
+	}
+
+	want := map[string]TierPrice{
+		"batch":    {InputPrice: 1, OutputPrice: 4},
+		"flex":     {InputPrice: 1, OutputPrice: 4},
+		"priority": {InputPrice: 3.5, OutputPrice: 14},
+	}
+	for tier, tp := range want {
+		if gpt41.Tiers[tier] != tp {
+			t.Errorf("gpt-4.1 %s 价格错误: %+v", tier, gpt41.Tiers[tier])
 		}
-		fmt.Printf("%-35s %12s %12s %12s\n",
-			p.Model,
-			fmt.Sprintf("$%.3f", p.InputPrice),
-			cachedStr,
-			fmt.Sprintf("$%.3f", p.OutputPrice),
-		)
+	}
+
+	price := buildPrice(gpt41)
+	if len(price.Modifiers) != 3 {
+		t.Fatalf("服务等级调整规则数量错误: %+v", price.Modifiers)
+	}
+	if m := price.Modifiers[0]; m.Kind != models.ModifierBatch || m.InputMultiplier != 0.5 || m.OutputMultiplier != 0.5 {
+		t.Errorf("批量折扣错误: %+v", m)
+	}
+	if m := price.Modifiers[2]; m.Kind != models.ModifierPriority || m.InputMultiplier != 1.75 || m.OutputMultiplier != 1.75 {
+		t.Errorf("优先处理倍率错误: %+v", m)
+	}
+}
+
+func TestParseAudioPrices(t *testing.T) {
+	prices := loadPrices(t)
+
+	// 同时有文本和音频价格的模型，音频价格写入扩展字段
+	price := buildPrice(prices["gpt-4o"])
+	if price.InputPrice != 2.5 || price.OutputPrice != 10 {
+		t.Errorf("gpt-4o 基础价格错误: %v / %v", price.InputPrice, price.OutputPrice)
+	}
+	if price.InputAudioTokens == nil || *price.InputAudioTokens != 40 ||
+		price.OutputAudioTokens == nil || *price.OutputAudioTokens != 80 {
+		t.Errorf("gpt-4o 音频价格错误: %v / %v", price.InputAudioTokens, price.OutputAudioTokens)
+	}
+
+	// 只有音频价格的模型，基础价格使用音频价格
+	price = buildPrice(prices["gpt-4o-audio-preview"])
+	if price.InputPrice != 40 || price.OutputPrice != 80 || price.InputAudioTokens == nil {
+		t.Errorf("gpt-4o-audio-preview 价格错误: %+v", price)
+	}
+}
+
+func TestParseImageTokenPrices(t *testing.T) {
+	prices := loadPrices(t)
+
+	mp := prices["gpt-image-1"]
+	if mp.InputImagePrice != 10 || mp.OutputImagePrice != 40 {
+		t.Errorf("gpt-image-1 图片token价格错误: %+v", mp)
+	}
+
+	// 有图片token价格时不按张计费
+	price := buildPrice(mp)
+	if price.BillingUnit == models.BillingUnitImage || price.InputPrice != 10 || price.OutputPrice != 40 {
+		t.Errorf("gpt-image-1 价格错误: %+v", price)
+	}
+	if price.InputImageTokens == nil || *price.InputImageTokens != 10 {
+		t.Errorf("gpt-image-1 图片输入价格错误: %v", price.InputImageTokens)
+	}
+}
+
+func TestParseImageGenerationPrices(t *testing.T) {
+	prices := loadPrices(t)
+
+	price := buildPrice(prices["dall-e-3"])
+	if price.BillingUnit != models.BillingUnitImage || price.InputPrice != 0.04 || price.OutputPrice != 0.04 {
+		t.Errorf("dall-e-3 价格错误: %+v", price)
+	}
+	if price.UnitMeta == nil || price.UnitMeta.Resolution != "1024x1024" {
+		t.Errorf("dall-e-3 分辨率错误: %+v", price.UnitMeta)
+	}
+}
+
+func TestParseFineTuningPrices(t *testing.T) {
+	prices := loadPrices(t)
+
+	mp, ok := prices["ft:gpt-4.1-2025-04-14"]
+	if !ok {
+		t.Fatal("未解析到微调模型价格")
+	}
+	if mp.InputPrice != 3 || mp.CachedPrice != 0.75 || mp.OutputPrice != 12 {
+		t.Errorf("微调模型价格错误: %+v", mp)
+	}
+	if _, ok := prices["gpt-4.1-2025-04-14"]; ok {
+		t.Error("微调模型不应使用原始模型名称")
 	}
 }
@@ -1,17 +1,17 @@
 package openrouter_api
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
-	"io"
 	"log"
 	"math"
 	"net/http"
 	"strconv"
 	"strings"
 
-	"aimodels-prices/database"
-	"aimodels-prices/handlers"
+	"aimodels-prices/cron/scraper"
+	"aimodels-prices/httpclient"
 	"aimodels-prices/models"
 )
 
@@ -21,7 +21,6 @@ const (
 	BillingType      = "tokens"
 	Currency         = "USD"
 	PriceSource      = "https://openrouter.ai/models"
-	Status           = "approved"
 	CreatedBy        = "cron自动任务"
 )
 
@@ -30,169 +29,170 @@ type OpenRouterResponse struct {
 }
 
 type ModelData struct {
-	Slug     string   `json:"slug"`
-	Modality string   `json:"modality"`
-	Pricing  Pricing  `json:"pricing"`
-	Endpoint Endpoint `json:"endpoint"`
+	Slug          string     `json:"slug"`
+	Modality      string     `json:"modality"`
+	ContextLength int        `json:"context_length"`
+	Pricing       Pricing    `json:"pricing"`
+	Endpoint      Endpoint   `json:"endpoint"`
+	Endpoints     []Endpoint `json:"endpoints"` // 所有上游供应商的endpoint
 }
 
+// Pricing OpenRouter价格，均为每token（request为每次请求、image为每张图片）的美元价格字符串
 type Pricing struct {
-	Prompt     string `json:"prompt"`
-	Completion string `json:"completion"`
+	Prompt            string `json:"prompt"`
+	Completion        string `json:"completion"`
+	InputCacheRead    string `json:"input_cache_read"`
+	InputCacheWrite   string `json:"input_cache_write"`
+	InternalReasoning string `json:"internal_reasoning"`
+	Image             string `json:"image"`
+	Request           string `json:"request"`
 }
 
 type Endpoint struct {
-	Pricing Pricing `json:"pricing"`
+	ProviderName string  `json:"provider_name"` // 上游供应商名称，如 Together
+	Pricing      Pricing `json:"pricing"`
 }
 
-// FetchAndSavePrices 获取OpenRouter API的价格并保存到数据库
-func FetchAndSavePrices() error {
-	log.Println("开始获取OpenRouter价格数据...")
+// Source OpenRouter价格来源，价格自动审核通过
+type Source struct{}
 
-	// 发送GET请求获取数据
-	resp, err := http.Get(OpenRouterAPIURL)
+// Name 来源名称
+func (Source) Name() string { return "openrouter" }
+
+// ChannelType 厂商ID
+func (Source) ChannelType() uint { return ChannelType }
+
+// Approval 默认审核策略
+func (Source) Approval() scraper.ApprovalPolicy { return scraper.ApproveAll }
+
+// Fetch 获取OpenRouter API的价格和模型元数据
+func (Source) Fetch(ctx context.Context) ([]scraper.ScrapedPrice, error) {
+	openRouterResp, err := fetchOpenRouterData(ctx)
 	if err != nil {
-		return fmt.Errorf("请求OpenRouter API失败: %v", err)
+		return nil, err
+	}
+
+	var prices []scraper.ScrapedPrice
+	for _, modelData := range openRouterResp.Data {
+		price, ok := buildPrice(modelData)
+		if !ok {
+			continue
+		}
+
+		sp := scraper.ScrapedPrice{Price: price}
+		if meta, ok := buildModelMetadata(modelData); ok {
+			sp.Metadata = &meta
+		}
+		prices = append(prices, sp)
 	}
-	defer resp.Body.Close()
 
-	// 读取响应内容
-	body, err := io.ReadAll(resp.Body)
+	return prices, nil
+}
+
+// fetchOpenRouterData 获取OpenRouter API数据
+func fetchOpenRouterData(ctx context.Context) (*OpenRouterResponse, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", OpenRouterAPIURL, nil)
 	if err != nil {
-		return fmt.Errorf("读取响应内容失败: %v", err)
+		return nil, fmt.Errorf("创建HTTP请求失败: %v", err)
+	}
+	body, err := httpclient.For("openrouter").Fetch(req)
+	if err != nil {
+		return nil, fmt.Errorf("请求OpenRouter API失败: %v", err)
 	}
 
 	// 解析JSON数据
 	var openRouterResp OpenRouterResponse
 	if err := json.Unmarshal(body, &openRouterResp); err != nil {
-		return fmt.Errorf("解析JSON数据失败: %v", err)
+		return nil, fmt.Errorf("解析JSON数据失败: %v", err)
 	}
 
-	// 获取数据库连接
-	db := database.DB
-	if db == nil {
-		return fmt.Errorf("获取数据库连接失败")
+	return &openRouterResp, nil
+}
+
+// buildPrice 将模型数据转换为价格记录，没有价格或价格无法解析时返回false
+func buildPrice(modelData ModelData) (models.Price, bool) {
+	// 1. 检查API返回的模型是否有价格字段，如果没有价格则跳过
+	if (modelData.Endpoint.Pricing.Prompt == "" || modelData.Endpoint.Pricing.Completion == "") &&
+		(modelData.Pricing.Prompt == "" || modelData.Pricing.Completion == "") {
+		log.Printf("跳过无价格模型: %s", modelData.Slug)
+		return models.Price{}, false
 	}
 
-	// 处理每个模型的价格数据
-	processedCount := 0
-	skippedCount := 0
-	for _, modelData := range openRouterResp.Data {
-		// 1. 检查API返回的模型是否有价格字段，如果没有价格则跳过
-		hasPricing := false
-		if (modelData.Endpoint.Pricing.Prompt != "" && modelData.Endpoint.Pricing.Completion != "") ||
-			(modelData.Pricing.Prompt != "" && modelData.Pricing.Completion != "") {
-			hasPricing = true
+	// 2. 检查模型名称是否包含":free"，如果是免费模型则设置价格为0
+	var inputPrice, outputPrice float64
+	var err error
+
+	if strings.Contains(modelData.Slug, ":free") {
+		log.Printf("处理免费模型，价格设为0: %s", modelData.Slug)
+	} else {
+		// 优先使用endpoint中的pricing，没有则使用顶层pricing
+		prompt, completion := modelData.Endpoint.Pricing.Prompt, modelData.Endpoint.Pricing.Completion
+		if prompt == "" {
+			prompt = modelData.Pricing.Prompt
 		}
-
-		if !hasPricing {
-			log.Printf("跳过无价格模型: %s", modelData.Slug)
-			skippedCount++
-			continue
+		if completion == "" {
+			completion = modelData.Pricing.Completion
 		}
 
-		// 2. 检查模型名称是否包含":free"，如果是免费模型则设置价格为0
-		isFreeModel := strings.Contains(modelData.Slug, ":free")
-
-		// 使用endpoint中的pricing
-		var inputPrice, outputPrice float64
-		var err error
-
-		if isFreeModel {
-			// 免费模型价格设置为0
-			inputPrice = 0
-			outputPrice = 0
-			log.Printf("处理免费模型，价格设为0: %s", modelData.Slug)
-		} else {
-			// 优先使用endpoint中的pricing
-			if modelData.Endpoint.Pricing.Prompt != "" {
-				inputPrice, err = parsePrice(modelData.Endpoint.Pricing.Prompt)
-				if err != nil {
-					log.Printf("解析endpoint输入价格失败 %s: %v", modelData.Slug, err)
-					skippedCount++
-					continue
-				}
-			} else if modelData.Pricing.Prompt != "" {
-				// 如果endpoint中没有，则使用顶层pricing
-				inputPrice, err = parsePrice(modelData.Pricing.Prompt)
-				if err != nil {
-					log.Printf("解析输入价格失败 %s: %v", modelData.Slug, err)
-					skippedCount++
-					continue
-				}
-			}
-
-			if modelData.Endpoint.Pricing.Completion != "" {
-				outputPrice, err = parsePrice(modelData.Endpoint.Pricing.Completion)
-				if err != nil {
-					log.Printf("解析endpoint输出价格失败 %s: %v", modelData.Slug, err)
-					skippedCount++
-					continue
-				}
-			} else if modelData.Pricing.Completion != "" {
-				outputPrice, err = parsePrice(modelData.Pricing.Completion)
-				if err != nil {
-					log.Printf("解析输出价格失败 %s: %v", modelData.Slug, err)
-					skippedCount++
-					continue
-				}
-			}
+		if inputPrice, err = parsePrice(prompt); err != nil {
+			log.Printf("解析输入价格失败 %s: %v", modelData.Slug, err)
+			return models.Price{}, false
 		}
-
-		// 创建价格对象
-		price := models.Price{
-			Model:       modelData.Slug,
-			BillingType: BillingType,
-			ChannelType: ChannelType,
-			Currency:    Currency,
-			InputPrice:  inputPrice,
-			OutputPrice: outputPrice,
-			PriceSource: PriceSource,
-			Status:      Status,
-			CreatedBy:   CreatedBy,
+		if outputPrice, err = parsePrice(completion); err != nil {
+			log.Printf("解析输出价格失败 %s: %v", modelData.Slug, err)
+			return models.Price{}, false
 		}
+	}
+
+	price := models.Price{
+		Model:       modelData.Slug,
+		BillingType: BillingType,
+		ChannelType: ChannelType,
+		Currency:    Currency,
+		InputPrice:  inputPrice,
+		OutputPrice: outputPrice,
+		PriceSource: PriceSource,
+	}
+	if !strings.Contains(modelData.Slug, ":free") {
+		applyExtraPrices(&price, modelData)
+	}
+	return price, true
+}
 
-		// 检查是否已存在相同模型的价格记录
-		var existingPrice models.Price
-		result := db.Where("model = ? AND channel_type = ?", modelData.Slug, ChannelType).First(&existingPrice)
-
-		if result.Error == nil {
-			// 使用processPrice函数处理更新
-			_, changed, err := handlers.ProcessPrice(price, &existingPrice, true, CreatedBy)
-			if err != nil {
-				log.Printf("更新价格记录失败 %s: %v", modelData.Slug, err)
-				skippedCount++
-				continue
-			}
-
-			if changed {
-				log.Printf("更新价格记录: %s", modelData.Slug)
-				processedCount++
-			} else {
-				// log.Printf("价格无变化，跳过更新: %s", modelData.Slug)
-				skippedCount++
-			}
-		} else {
-			// 使用processPrice函数处理创建
-			_, changed, err := handlers.ProcessPrice(price, nil, true, CreatedBy)
-			if err != nil {
-				log.Printf("创建价格记录失败 %s: %v", modelData.Slug, err)
-				skippedCount++
-				continue
-			}
-
-			if changed {
-				// log.Printf("创建新价格记录: %s", modelData.Slug)
-				processedCount++
-			} else {
-				log.Printf("价格创建失败: %s", modelData.Slug)
-				skippedCount++
-			}
+// applyExtraPrices 解析缓存读写、推理和每次请求价格，优先使用endpoint中的pricing
+// 价格为空、为0或无法解析时不设置
+func applyExtraPrices(price *models.Price, modelData ModelData) {
+	pick := func(get func(Pricing) string) string {
+		if value := get(modelData.Endpoint.Pricing); value != "" {
+			return value
 		}
+		return get(modelData.Pricing)
 	}
 
-	log.Printf("OpenRouter价格数据处理完成，成功处理: %d, 跳过: %d", processedCount, skippedCount)
-	return nil
+	price.CachedReadTokens = parseExtraPrice(pick(func(p Pricing) string { return p.InputCacheRead }), 1000000)
+	price.CachedWriteTokens = parseExtraPrice(pick(func(p Pricing) string { return p.InputCacheWrite }), 1000000)
+	price.ReasoningTokens = parseExtraPrice(pick(func(p Pricing) string { return p.InternalReasoning }), 1000000)
+	// image为每张输入图片的价格，与按百万token计价的InputImageTokens单位不同，单独写入ImagePrice
+	// image和request均为每次计费的固定费用，不换算
+	price.ImagePrice = parseExtraPrice(pick(func(p Pricing) string { return p.Image }), 1)
+	price.RequestPrice = parseExtraPrice(pick(func(p Pricing) string { return p.Request }), 1)
+}
+
+// parseExtraPrice 解析扩展价格并乘以scale，为空、为0或无法解析时返回nil
+func parseExtraPrice(priceStr string, scale float64) *float64 {
+	if priceStr == "" {
+		return nil
+	}
+	price, err := strconv.ParseFloat(priceStr, 64)
+	if err != nil {
+		log.Printf("扩展价格解析失败: %s, 错误: %v", priceStr, err)
+		return nil
+	}
+	if price <= 0 {
+		return nil
+	}
+	result := math.Round(price*scale*1000000) / 1000000
+	return &result
 }
 
 // parsePrice 解析价格字符串为浮点数并乘以1000000
@@ -211,3 +211,26 @@ func parsePrice(priceStr string) (float64, error) {
 	result := math.Round(price*1000000*1000000) / 1000000
 	return result, nil
 }
+
+// buildModelMetadata 从模型的modality（如 text+image->text）和context_length构建模型元数据
+func buildModelMetadata(modelData ModelData) (models.ModelMetadata, bool) {
+	meta := models.ModelMetadata{Source: PriceSource}
+	if modelData.ContextLength > 0 {
+		contextLength := modelData.ContextLength
+		meta.ContextLength = &contextLength
+	}
+
+	parts := strings.Split(modelData.Modality, "->")
+	if len(parts) != 2 {
+		return meta, meta.ContextLength != nil
+	}
+
+	inputModalities := strings.ReplaceAll(strings.TrimSpace(parts[0]), "+", ",")
+	outputModalities := strings.ReplaceAll(strings.TrimSpace(parts[1]), "+", ",")
+	vision := strings.Contains(inputModalities, "image")
+
+	meta.InputModalities = &inputModalities
+	meta.OutputModalities = &outputModalities
+	meta.SupportsVision = &vision
+	return meta, true
+}
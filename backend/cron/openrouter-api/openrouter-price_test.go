@@ -0,0 +1,99 @@
+package openrouter_api
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+const sampleModel = `{
+	"slug": "anthropic/claude-sonnet-4",
+	"modality": "text+image->text",
+	"context_length": 200000,
+	"pricing": {"prompt": "0.000001", "completion": "0.000002"},
+	"endpoint": {
+		"pricing": {
+			"prompt": "0.000003",
+			"completion": "0.000015",
+			"input_cache_read": "0.0000003",
+			"input_cache_write": "0.00000375",
+			"internal_reasoning": "0",
+			"image": "0.0048",
+			"request": "0.005"
+		}
+	}
+}`
+
+func TestBuildPrice(t *testing.T) {
+	var modelData ModelData
+	if err := json.Unmarshal([]byte(sampleModel), &modelData); err != nil {
+		t.Fatal(err)
+	}
+
+	price, ok := buildPrice(modelData)
+	if !ok {
+		t.Fatal("应解析出价格")
+	}
+	if price.InputPrice != 3 || price.OutputPrice != 15 {
+		t.Errorf("基础价格错误: %v / %v", price.InputPrice, price.OutputPrice)
+	}
+	if price.CachedReadTokens == nil || *price.CachedReadTokens != 0.3 {
+		t.Errorf("缓存读取价格错误: %v", price.CachedReadTokens)
+	}
+	if price.CachedWriteTokens == nil || *price.CachedWriteTokens != 3.75 {
+		t.Errorf("缓存写入价格错误: %v", price.CachedWriteTokens)
+	}
+	if price.ReasoningTokens != nil {
+		t.Errorf("推理价格为0时不应设置: %v", *price.ReasoningTokens)
+	}
+	// 每张图片的价格不能按token价格记录
+	if price.InputImageTokens != nil {
+		t.Errorf("不应设置图片输入token价格: %v", *price.InputImageTokens)
+	}
+	if price.ImagePrice == nil || *price.ImagePrice != 0.0048 {
+		t.Errorf("每张图片价格错误: %v", price.ImagePrice)
+	}
+	if price.RequestPrice == nil || *price.RequestPrice != 0.005 {
+		t.Errorf("每次请求费用错误: %v", price.RequestPrice)
+	}
+
+	meta, ok := buildModelMetadata(modelData)
+	if !ok || meta.ContextLength == nil || *meta.ContextLength != 200000 {
+		t.Errorf("上下文长度错误: %+v", meta)
+	}
+	if meta.InputModalities == nil || *meta.InputModalities != "text,image" || meta.SupportsVision == nil || !*meta.SupportsVision {
+		t.Errorf("模态信息错误: %+v", meta)
+	}
+
+	// 免费模型不设置扩展价格
+	modelData.Slug += ":free"
+	if price, ok := buildPrice(modelData); !ok || price.InputPrice != 0 || price.RequestPrice != nil || price.ImagePrice != nil {
+		t.Errorf("免费模型价格错误: %+v", price)
+	}
+}
+
+func TestGroupByProvider(t *testing.T) {
+	data := []ModelData{
+		{
+			Slug: "meta-llama/llama-3.1-70b-instruct",
+			Endpoints: []Endpoint{
+				{ProviderName: "Together", Pricing: Pricing{Prompt: "0.00000088", Completion: "0.00000088"}},
+				{ProviderName: "DeepInfra", Pricing: Pricing{Prompt: "0.00000023", Completion: "0.0000004"}},
+				{Pricing: Pricing{Prompt: "0.000001", Completion: "0.000001"}},
+			},
+		},
+		{
+			Slug:      "meta-llama/llama-3.1-8b-instruct:free",
+			Endpoints: []Endpoint{{ProviderName: "Together", Pricing: Pricing{Prompt: "0", Completion: "0"}}},
+		},
+	}
+
+	grouped := groupByProvider(data)
+	if len(grouped) != 2 || len(grouped["Together"]) != 1 || len(grouped["DeepInfra"]) != 1 {
+		t.Fatalf("分组错误: %+v", grouped)
+	}
+
+	price, ok := buildPrice(grouped["DeepInfra"][0])
+	if !ok || price.InputPrice != 0.23 || price.OutputPrice != 0.4 {
+		t.Errorf("供应商价格错误: %+v", price)
+	}
+}
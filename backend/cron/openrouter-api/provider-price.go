@@ -0,0 +1,150 @@
+package openrouter_api
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"aimodels-prices/catalog"
+	"aimodels-prices/cron/scraper"
+	"aimodels-prices/database"
+	"aimodels-prices/models"
+
+	"gorm.io/gorm/clause"
+)
+
+// ProviderJobName 按上游供应商拆分价格的任务名称，也用于 CRON_SCHEDULES 配置
+const ProviderJobName = "openrouter-providers"
+
+// MappingSource 供应商映射表中OpenRouter的来源名称
+const MappingSource = "openrouter"
+
+// ProviderPricesEnabled 是否按上游供应商拆分价格，由环境变量 OPENROUTER_PROVIDER_PRICES 控制，默认关闭
+func ProviderPricesEnabled() bool {
+	enabled, _ := strconv.ParseBool(os.Getenv("OPENROUTER_PROVIDER_PRICES"))
+	return enabled
+}
+
+// providerSource 单个上游供应商的价格来源，价格由 UpdateProviderPrices 统一抓取后传入
+// 已审核的模型自动更新，新模型进入审核队列
+type providerSource struct {
+	provider    string
+	channelType uint
+	prices      []scraper.ScrapedPrice
+}
+
+func (s providerSource) Name() string {
+	return MappingSource + ":" + strings.ToLower(s.provider)
+}
+
+func (s providerSource) ChannelType() uint { return s.channelType }
+
+func (s providerSource) Approval() scraper.ApprovalPolicy { return scraper.ApproveKnown }
+
+func (s providerSource) Fetch(ctx context.Context) ([]scraper.ScrapedPrice, error) {
+	return s.prices, nil
+}
+
+// UpdateProviderPrices 将OpenRouter各上游供应商endpoint的价格写入映射的厂商
+// 未知供应商登记为待映射，由管理员在供应商映射表中指定厂商ID后生效
+func UpdateProviderPrices(ctx context.Context, trigger string) error {
+	resp, err := fetchOpenRouterData(ctx)
+	if err != nil {
+		return err
+	}
+
+	db := database.DB
+	if db == nil {
+		return fmt.Errorf("获取数据库连接失败")
+	}
+
+	var mappings []models.ProviderMapping
+	if err := db.Where("source = ?", MappingSource).Find(&mappings).Error; err != nil {
+		return fmt.Errorf("查询供应商映射失败: %v", err)
+	}
+	byName := make(map[string]models.ProviderMapping, len(mappings))
+	for _, m := range mappings {
+		byName[strings.ToLower(m.Name)] = m
+	}
+
+	grouped := groupByProvider(resp.Data)
+	names := make([]string, 0, len(grouped))
+	for name := range grouped {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		mapping, ok := byName[strings.ToLower(name)]
+		if !ok {
+			log.Printf("发现未映射的OpenRouter供应商: %s", name)
+			if err := db.Clauses(clause.OnConflict{DoNothing: true}).Create(&models.ProviderMapping{
+				Source:    MappingSource,
+				Name:      name,
+				Status:    models.ProviderMappingPending,
+				CreatedBy: scraper.CreatedBy,
+			}).Error; err != nil {
+				log.Printf("登记供应商映射失败 %s: %v", name, err)
+			}
+			continue
+		}
+		if mapping.Status != models.ProviderMappingApproved || mapping.ProviderID == nil {
+			continue
+		}
+
+		prices := buildProviderPrices(grouped[name])
+		source := providerSource{provider: name, channelType: *mapping.ProviderID, prices: prices}
+		if _, err := scraper.Run(ctx, source, trigger, ""); err != nil {
+			log.Printf("%s价格更新失败: %v", source.Name(), err)
+		}
+	}
+
+	return nil
+}
+
+// groupByProvider 按上游供应商拆分各模型的endpoint，每条记录的Endpoint为该供应商的endpoint
+// 免费模型跳过
+func groupByProvider(data []ModelData) map[string][]ModelData {
+	grouped := make(map[string][]ModelData)
+	for _, modelData := range data {
+		if strings.Contains(modelData.Slug, ":free") {
+			continue
+		}
+		for _, endpoint := range modelData.Endpoints {
+			if endpoint.ProviderName == "" {
+				continue
+			}
+			grouped[endpoint.ProviderName] = append(grouped[endpoint.ProviderName], ModelData{
+				Slug:     modelData.Slug,
+				Endpoint: endpoint,
+			})
+		}
+	}
+	return grouped
+}
+
+// buildProviderPrices 将供应商的endpoint转换为价格记录，模型名称为slug中/后面的部分，能在模型目录中解析时使用规范名称
+func buildProviderPrices(data []ModelData) []scraper.ScrapedPrice {
+	prices := make([]scraper.ScrapedPrice, 0, len(data))
+	for _, modelData := range data {
+		_, modelName, ok := strings.Cut(modelData.Slug, "/")
+		if !ok || modelName == "" {
+			continue
+		}
+		price, ok := buildPrice(modelData)
+		if !ok {
+			continue
+		}
+
+		price.Model = modelName
+		if canonicalID, found := catalog.Resolve(ChannelType, modelData.Slug); found {
+			price.Model = canonicalID
+		}
+		prices = append(prices, scraper.ScrapedPrice{Price: price})
+	}
+	return prices
+}
@@ -1,14 +1,12 @@
 package openrouter_api
 
 import (
+	"context"
 	"fmt"
-	"io"
 	"log"
-	"net/http"
-
-	"encoding/json"
 	"strings"
 
+	"aimodels-prices/catalog"
 	"aimodels-prices/database"
 	"aimodels-prices/handlers"
 	"aimodels-prices/handlers/one_hub"
@@ -36,7 +34,7 @@ func UpdateOtherPrices() error {
 	log.Println("开始更新其他厂商价格数据...")
 
 	// 复用已有的API请求获取数据
-	resp, err := fetchOpenRouterData()
+	resp, err := fetchOpenRouterData(context.Background())
 	if err != nil {
 		return fmt.Errorf("获取OpenRouter数据失败: %v", err)
 	}
@@ -82,28 +80,12 @@ func UpdateOtherPrices() error {
 			continue
 		}
 
-		// 处理特殊模型名称
-		if author == "google" {
-			// 处理gemini-flash-1.5系列模型名称
-			if strings.HasPrefix(modelName, "gemini-flash-1.5") {
-				suffix := strings.TrimPrefix(modelName, "gemini-flash-1.5")
-				modelName = "gemini-1.5-flash" + suffix
-				log.Printf("修正Google模型名称: %s -> %s", parts[1], modelName)
-			}
-		}
-		if author == "anthropic" {
-			// 处理claude-3.5-sonnet系列模型名称
-			if strings.HasPrefix(modelName, "claude-3.5") {
-				suffix := strings.TrimPrefix(modelName, "claude-3.5")
-				modelName = "claude-3-5" + suffix
-				log.Printf("修正Claude模型名称: %s -> %s", parts[1], modelName)
-			}
-			// 处理claude-3.7-sonnet系列模型名称
-			if strings.HasPrefix(modelName, "claude-3.7") {
-				suffix := strings.TrimPrefix(modelName, "claude-3.7")
-				modelName = "claude-3-7" + suffix
-				log.Printf("修正Claude模型名称: %s -> %s", parts[1], modelName)
+		// 通过模型目录解析规范名称（如 gemini-flash-1.5 -> gemini-1.5-flash）
+		if canonicalID, found := catalog.Resolve(ChannelType, modelData.Slug); found {
+			if canonicalID != modelName {
+				log.Printf("修正模型名称: %s -> %s", parts[1], canonicalID)
 			}
+			modelName = canonicalID
 		}
 
 		// 创建唯一标识符，用于避免同厂商同模型重复处理
@@ -161,6 +143,7 @@ func UpdateOtherPrices() error {
 			Status:      OtherStatus,
 			CreatedBy:   CreatedBy,
 		}
+		applyExtraPrices(&price, modelData)
 
 		// 检查是否已存在相同模型的价格记录
 		var existingPrice models.Price
@@ -183,20 +166,7 @@ func UpdateOtherPrices() error {
 				skippedCount++
 			}
 		} else {
-			// 检查是否存在相同模型名称的待审核记录
-			var pendingCount int64
-			if err := db.Model(&models.Price{}).Where("model = ? AND channel_type = ? AND status = 'pending'",
-				modelName, channelType).Count(&pendingCount).Error; err != nil {
-				log.Printf("检查待审核记录失败 %s: %v", modelName, err)
-			}
-
-			if pendingCount > 0 {
-				// log.Printf("已存在待审核的相同模型记录，跳过创建: %s (厂商: %s)", modelName, author)
-				skippedCount++
-				continue
-			}
-
-			// 使用processPrice函数处理创建
+			// 使用processPrice函数处理创建，同名记录由数据库唯一索引拒绝
 			_, changed, err := handlers.ProcessPrice(price, nil, false, CreatedBy)
 			if err != nil {
 				log.Printf("创建价格记录失败 %s: %v", modelName, err)
@@ -222,30 +192,6 @@ func UpdateOtherPrices() error {
 	return nil
 }
 
-// fetchOpenRouterData 获取OpenRouter API数据
-func fetchOpenRouterData() (*OpenRouterResponse, error) {
-	// 复用已有的HTTP请求逻辑
-	resp, err := http.Get(OpenRouterAPIURL)
-	if err != nil {
-		return nil, fmt.Errorf("请求OpenRouter API失败: %v", err)
-	}
-	defer resp.Body.Close()
-
-	// 读取响应内容
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, fmt.Errorf("读取响应内容失败: %v", err)
-	}
-
-	// 解析JSON数据
-	var openRouterResp OpenRouterResponse
-	if err := json.Unmarshal(body, &openRouterResp); err != nil {
-		return nil, fmt.Errorf("解析JSON数据失败: %v", err)
-	}
-
-	return &openRouterResp, nil
-}
-
 // isInBlacklist 检查模型名称是否在黑名单中
 func isInBlacklist(modelName string) bool {
 	modelNameLower := strings.ToLower(modelName)
@@ -0,0 +1,211 @@
+package scraper
+
+import (
+	"errors"
+	"log"
+	"os"
+	"strconv"
+	"time"
+
+	"aimodels-prices/events"
+	"aimodels-prices/models"
+
+	"gorm.io/gorm"
+)
+
+// DefaultDelistAfter 默认连续缺失多少次成功运行后标记为下架
+const DefaultDelistAfter = 3
+
+// DelistAfter 从环境变量 DELIST_AFTER_MISSED_RUNS 读取下架阈值，0 表示不自动下架
+func DelistAfter() int {
+	value := os.Getenv("DELIST_AFTER_MISSED_RUNS")
+	if value == "" {
+		return DefaultDelistAfter
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 0 {
+		log.Printf("无效的下架阈值 DELIST_AFTER_MISSED_RUNS=%s，使用默认值 %d", value, DefaultDelistAfter)
+		return DefaultDelistAfter
+	}
+	return n
+}
+
+// listingStaleAfter 超过该时间未出现的模型记录不再视为来源仍在提供，避免已停用来源的记录阻止下架
+const listingStaleAfter = 7 * 24 * time.Hour
+
+// listedElsewhere 判断同一厂商的其他来源是否仍在提供该模型
+// 多个来源可能写入同一厂商（供应商映射、管理员配置的来源），只有所有来源都不再提供时才下架
+func listedElsewhere(db *gorm.DB, l models.SourceListing, threshold int) (bool, error) {
+	var count int64
+	err := db.Model(&models.SourceListing{}).
+		Where("channel_type = ? AND model = ? AND source <> ?", l.ChannelType, l.Model, l.Source).
+		Where("delisted_at IS NULL AND missed_runs < ? AND last_seen_at > ?", threshold, time.Now().Add(-listingStaleAfter)).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// relist 恢复重新出现在来源中的已下架价格
+func relist(db *gorm.DB, channelType uint, seen []string) error {
+	if len(seen) == 0 {
+		return nil
+	}
+
+	var prices []models.Price
+	if err := db.Where("channel_type = ? AND model IN ? AND status = 'delisted'", channelType, seen).
+		Find(&prices).Error; err != nil {
+		return err
+	}
+	for _, price := range prices {
+		if err := db.Model(&price).Updates(map[string]interface{}{"status": "approved", "delisted_at": nil}).Error; err != nil {
+			return err
+		}
+		previous := price
+		price.Status = "approved"
+		price.DelistedAt = nil
+		events.Publish(models.PriceEventRelisted, price, &previous, CreatedBy)
+	}
+	if len(prices) > 0 {
+		log.Printf("%d 个已下架模型重新上架", len(prices))
+	}
+	return nil
+}
+
+// trackListings 记录本次成功运行中出现的模型，并将连续缺失达到阈值、且同一厂商的其他来源也不再提供的模型价格标记为下架
+// 返回本次下架的模型名称
+func trackListings(db *gorm.DB, source PriceSource, seen []string) ([]string, error) {
+	name := source.Name()
+	now := time.Now()
+
+	var listings []models.SourceListing
+	if err := db.Where("source = ?", name).Find(&listings).Error; err != nil {
+		return nil, err
+	}
+	known := make(map[string]bool, len(listings))
+	for _, l := range listings {
+		known[l.Model] = true
+	}
+
+	// 出现的模型重置缺失次数
+	if len(seen) > 0 {
+		if err := db.Model(&models.SourceListing{}).
+			Where("source = ? AND model IN ?", name, seen).
+			Updates(map[string]interface{}{"missed_runs": 0, "last_seen_at": now, "delisted_at": nil}).Error; err != nil {
+			return nil, err
+		}
+	}
+
+	var created []models.SourceListing
+	for _, model := range seen {
+		if !known[model] {
+			created = append(created, models.SourceListing{
+				Source:      name,
+				Model:       model,
+				ChannelType: source.ChannelType(),
+				LastSeenAt:  now,
+			})
+		}
+	}
+	if len(created) > 0 {
+		if err := db.CreateInBatches(created, 100).Error; err != nil {
+			return nil, err
+		}
+	}
+
+	// 未出现的模型累加缺失次数
+	missing := db.Model(&models.SourceListing{}).Where("source = ? AND delisted_at IS NULL", name)
+	if len(seen) > 0 {
+		missing = missing.Where("model NOT IN ?", seen)
+	}
+	if err := missing.Update("missed_runs", gorm.Expr("missed_runs + 1")).Error; err != nil {
+		return nil, err
+	}
+
+	threshold := DelistAfter()
+	if threshold == 0 {
+		return nil, nil
+	}
+
+	var expired []models.SourceListing
+	if err := db.Where("source = ? AND delisted_at IS NULL AND missed_runs >= ?", name, threshold).
+		Find(&expired).Error; err != nil {
+		return nil, err
+	}
+
+	var delisted []string
+	for _, l := range expired {
+		elsewhere, err := listedElsewhere(db, l, threshold)
+		if err != nil {
+			log.Printf("标记下架失败 %s: %v", l.Model, err)
+			continue
+		}
+
+		// 只下架已审核的价格，待审核的修改仍交由审核人员处理；其他来源仍在提供时只更新本来源的记录
+		var price models.Price
+		found := false
+		if !elsewhere {
+			err = db.Where("channel_type = ? AND model = ? AND status = 'approved'", l.ChannelType, l.Model).First(&price).Error
+			found = err == nil
+			if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+				log.Printf("标记下架失败 %s: %v", l.Model, err)
+				continue
+			}
+		}
+		previous := price
+		if found {
+			if err := db.Model(&price).Updates(map[string]interface{}{"status": "delisted", "delisted_at": now}).Error; err != nil {
+				log.Printf("标记下架失败 %s: %v", l.Model, err)
+				continue
+			}
+			price.Status = "delisted"
+			price.DelistedAt = &now
+		}
+		if err := db.Model(&l).Update("delisted_at", now).Error; err != nil {
+			log.Printf("更新来源模型记录失败 %s: %v", l.Model, err)
+			continue
+		}
+		if found {
+			delisted = append(delisted, l.Model)
+			events.Publish(models.PriceEventDelisted, price, &previous, CreatedBy)
+		}
+	}
+
+	return delisted, nil
+}
+
+// wouldDelist 返回本次运行后将被标记下架的模型，用于试运行
+func wouldDelist(db *gorm.DB, source PriceSource, seen map[string]bool) ([]string, error) {
+	threshold := DelistAfter()
+	if threshold == 0 {
+		return nil, nil
+	}
+
+	var listings []models.SourceListing
+	if err := db.Where("source = ? AND delisted_at IS NULL AND missed_runs >= ?", source.Name(), threshold-1).
+		Find(&listings).Error; err != nil {
+		return nil, err
+	}
+
+	var delisted []string
+	for _, l := range listings {
+		if seen[l.Model] {
+			continue
+		}
+		elsewhere, err := listedElsewhere(db, l, threshold)
+		if err != nil {
+			return nil, err
+		}
+		if elsewhere {
+			continue
+		}
+		var count int64
+		if err := db.Model(&models.Price{}).
+			Where("channel_type = ? AND model = ? AND status = 'approved'", l.ChannelType, l.Model).
+			Count(&count).Error; err != nil {
+			return nil, err
+		}
+		if count > 0 {
+			delisted = append(delisted, l.Model)
+		}
+	}
+	return delisted, nil
+}
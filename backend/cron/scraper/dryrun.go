@@ -0,0 +1,289 @@
+package scraper
+
+import (
+	"context"
+	"fmt"
+	"math"
+
+	"aimodels-prices/database"
+	"aimodels-prices/models"
+)
+
+// 预览中的操作类型
+const (
+	ActionCreate = "create"
+	ActionUpdate = "update"
+	ActionSkip   = "skip"
+	ActionDelist = "delist"
+)
+
+// FieldChange 单个字段的变化
+type FieldChange struct {
+	Field string      `json:"field"`
+	Old   interface{} `json:"old"`
+	New   interface{} `json:"new"`
+}
+
+// Change 单个模型的预期操作
+type Change struct {
+	Model   string        `json:"model"`
+	Action  string        `json:"action"`
+	Status  string        `json:"status,omitempty"` // 写入后的状态：approved、pending 或 delisted
+	Reason  string        `json:"reason,omitempty"` // 跳过原因
+	Changes []FieldChange `json:"changes,omitempty"`
+}
+
+// Preview 试运行结果
+type Preview struct {
+	Job     string   `json:"job"`
+	Result  Result   `json:"result"`
+	Changes []Change `json:"changes"`
+}
+
+// DryRun 抓取并解析价格，与当前价格表比较得出将要新建、更新和跳过的记录，不写入数据库
+func DryRun(ctx context.Context, source PriceSource) (*Preview, error) {
+	prices, err := source.Fetch(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("获取%s价格数据失败: %v", source.Name(), err)
+	}
+
+	db := database.DB
+	if db == nil {
+		return nil, fmt.Errorf("获取数据库连接失败")
+	}
+
+	preview := &Preview{
+		Job:     source.Name(),
+		Result:  Result{Fetched: len(prices)},
+		Changes: make([]Change, 0, len(prices)),
+	}
+	skip := func(model, reason string) {
+		preview.Result.Skipped++
+		preview.Changes = append(preview.Changes, Change{Model: model, Action: ActionSkip, Reason: reason})
+	}
+
+	channelType := source.ChannelType()
+	policy := source.Approval()
+	seen := make(map[string]bool, len(prices))
+
+	for _, sp := range prices {
+		price := sp.Price
+		price.ChannelType = channelType
+
+		if price.Model == "" {
+			skip(price.Model, "模型名称为空")
+			continue
+		}
+		if seen[price.Model] {
+			skip(price.Model, "重复的模型")
+			continue
+		}
+		seen[price.Model] = true
+
+		if sp.Incomplete != "" {
+			skip(price.Model, "价格不完整，保留已有价格: "+sp.Incomplete)
+			continue
+		}
+
+		if err := validate(&price); err != nil {
+			skip(price.Model, err.Error())
+			continue
+		}
+
+		existing, isAdmin, err := lookup(db, policy, price)
+		if err != nil {
+			skip(price.Model, fmt.Sprintf("查询价格记录失败: %v", err))
+			continue
+		}
+
+		status := "pending"
+		if isAdmin {
+			status = "approved"
+		}
+
+		if existing == nil {
+			preview.Result.Created++
+			if !isAdmin {
+				preview.Result.Pending++
+			}
+			preview.Changes = append(preview.Changes, Change{
+				Model:   price.Model,
+				Action:  ActionCreate,
+				Status:  status,
+				Changes: diffPrice(models.Price{}, price),
+			})
+			continue
+		}
+
+		changes := diffPrice(*existing, price)
+		// 重新出现的已下架模型会先恢复上架
+		if existing.Status == "delisted" {
+			changes = append(changes, FieldChange{Field: "status", Old: existing.Status, New: "approved"})
+		}
+		// 以修改建议提交时，与已提交的待审核值相同也不会更新
+		if len(changes) > 0 && !isAdmin && existing.TempModel != nil && len(diffPrice(pendingPrice(*existing), price)) == 0 {
+			changes = nil
+		}
+		if len(changes) == 0 {
+			skip(price.Model, "价格无变化")
+			continue
+		}
+
+		preview.Result.Updated++
+		if !isAdmin {
+			preview.Result.Pending++
+		}
+		preview.Changes = append(preview.Changes, Change{
+			Model:   price.Model,
+			Action:  ActionUpdate,
+			Status:  status,
+			Changes: changes,
+		})
+	}
+
+	delisted, err := wouldDelist(db, source, seen)
+	if err != nil {
+		return nil, fmt.Errorf("查询%s模型上架记录失败: %v", source.Name(), err)
+	}
+	for _, model := range delisted {
+		preview.Result.Delisted++
+		preview.Changes = append(preview.Changes, Change{Model: model, Action: ActionDelist, Status: "delisted"})
+	}
+
+	return preview, nil
+}
+
+// validate 与 handlers.ProcessPrice 相同的校验和计费类型推导
+func validate(price *models.Price) error {
+	if err := price.Tiers.Validate(); err != nil {
+		return err
+	}
+	if err := price.Modifiers.Validate(); err != nil {
+		return err
+	}
+	if err := models.ValidateBillingUnit(price.BillingUnit, price.UnitMeta); err != nil {
+		return err
+	}
+	if price.BillingUnit != "" {
+		price.BillingType = models.BillingTypeForUnit(price.BillingUnit)
+	}
+	return nil
+}
+
+// pendingPrice 返回已提交待审核的价格，未提交的字段使用当前值
+func pendingPrice(p models.Price) models.Price {
+	pending := p
+	if p.TempModel != nil {
+		pending.Model = *p.TempModel
+	}
+	if p.TempBillingType != nil {
+		pending.BillingType = *p.TempBillingType
+	}
+	if p.TempBillingUnit != nil {
+		pending.BillingUnit = *p.TempBillingUnit
+	}
+	if p.TempUnitMeta != nil {
+		pending.UnitMeta = p.TempUnitMeta
+	}
+	if p.TempCurrency != nil {
+		pending.Currency = *p.TempCurrency
+	}
+	if p.TempInputPrice != nil {
+		pending.InputPrice = *p.TempInputPrice
+	}
+	if p.TempOutputPrice != nil {
+		pending.OutputPrice = *p.TempOutputPrice
+	}
+	if p.TempInputAudioTokens != nil {
+		pending.InputAudioTokens = p.TempInputAudioTokens
+	}
+	if p.TempOutputAudioTokens != nil {
+		pending.OutputAudioTokens = p.TempOutputAudioTokens
+	}
+	if p.TempCachedTokens != nil {
+		pending.CachedTokens = p.TempCachedTokens
+	}
+	if p.TempCachedReadTokens != nil {
+		pending.CachedReadTokens = p.TempCachedReadTokens
+	}
+	if p.TempCachedWriteTokens != nil {
+		pending.CachedWriteTokens = p.TempCachedWriteTokens
+	}
+	if p.TempReasoningTokens != nil {
+		pending.ReasoningTokens = p.TempReasoningTokens
+	}
+	if p.TempInputTextTokens != nil {
+		pending.InputTextTokens = p.TempInputTextTokens
+	}
+	if p.TempOutputTextTokens != nil {
+		pending.OutputTextTokens = p.TempOutputTextTokens
+	}
+	if p.TempInputImageTokens != nil {
+		pending.InputImageTokens = p.TempInputImageTokens
+	}
+	if p.TempOutputImageTokens != nil {
+		pending.OutputImageTokens = p.TempOutputImageTokens
+	}
+	if p.TempRequestPrice != nil {
+		pending.RequestPrice = p.TempRequestPrice
+	}
+	if p.TempImagePrice != nil {
+		pending.ImagePrice = p.TempImagePrice
+	}
+	if p.TempTiers != nil {
+		pending.Tiers = p.TempTiers
+	}
+	if p.TempModifiers != nil {
+		pending.Modifiers = p.TempModifiers
+	}
+	if p.TempPriceSource != nil {
+		pending.PriceSource = *p.TempPriceSource
+	}
+	return pending
+}
+
+// diffPrice 列出两个价格之间有变化的字段，比较规则与 handlers.ProcessPrice 一致
+func diffPrice(old, new models.Price) []FieldChange {
+	var changes []FieldChange
+	add := func(field string, changed bool, o, n interface{}) {
+		if changed {
+			changes = append(changes, FieldChange{Field: field, Old: o, New: n})
+		}
+	}
+	num := func(field string, o, n float64) {
+		add(field, math.Abs(o-n) >= 0.00001, o, n)
+	}
+	ptr := func(field string, o, n *float64) {
+		switch {
+		case o == nil && n == nil:
+		case o == nil || n == nil:
+			add(field, true, o, n)
+		default:
+			num(field, *o, *n)
+		}
+	}
+
+	add("billing_type", old.BillingType != new.BillingType, old.BillingType, new.BillingType)
+	add("billing_unit", old.BillingUnit != new.BillingUnit, old.BillingUnit, new.BillingUnit)
+	add("unit_meta", !old.UnitMeta.Equal(new.UnitMeta), old.UnitMeta, new.UnitMeta)
+	add("currency", old.Currency != new.Currency, old.Currency, new.Currency)
+	num("input_price", old.InputPrice, new.InputPrice)
+	num("output_price", old.OutputPrice, new.OutputPrice)
+	ptr("input_audio_tokens", old.InputAudioTokens, new.InputAudioTokens)
+	ptr("output_audio_tokens", old.OutputAudioTokens, new.OutputAudioTokens)
+	ptr("cached_tokens", old.CachedTokens, new.CachedTokens)
+	ptr("cached_read_tokens", old.CachedReadTokens, new.CachedReadTokens)
+	ptr("cached_write_tokens", old.CachedWriteTokens, new.CachedWriteTokens)
+	ptr("reasoning_tokens", old.ReasoningTokens, new.ReasoningTokens)
+	ptr("input_text_tokens", old.InputTextTokens, new.InputTextTokens)
+	ptr("output_text_tokens", old.OutputTextTokens, new.OutputTextTokens)
+	ptr("input_image_tokens", old.InputImageTokens, new.InputImageTokens)
+	ptr("output_image_tokens", old.OutputImageTokens, new.OutputImageTokens)
+	ptr("request_price", old.RequestPrice, new.RequestPrice)
+	ptr("image_price", old.ImagePrice, new.ImagePrice)
+	add("tiers", !old.Tiers.Equal(new.Tiers), old.Tiers, new.Tiers)
+	add("modifiers", !old.Modifiers.Equal(new.Modifiers), old.Modifiers, new.Modifiers)
+	add("price_source", old.PriceSource != new.PriceSource, old.PriceSource, new.PriceSource)
+
+	return changes
+}
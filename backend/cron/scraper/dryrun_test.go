@@ -0,0 +1,33 @@
+package scraper
+
+import (
+	"testing"
+
+	"aimodels-prices/models"
+)
+
+func TestDiffPrice(t *testing.T) {
+	cached := 0.5
+	old := models.Price{Model: "gpt-4o", BillingType: "tokens", Currency: "USD", InputPrice: 2.5, OutputPrice: 10}
+	new := old
+	new.InputPrice = 2.500001 // 精度范围内视为相同
+	new.OutputPrice = 8
+	new.CachedTokens = &cached
+
+	changes := diffPrice(old, new)
+	if len(changes) != 2 {
+		t.Fatalf("变化字段数量错误: %+v", changes)
+	}
+	if changes[0].Field != "output_price" || changes[1].Field != "cached_tokens" {
+		t.Errorf("变化字段错误: %+v", changes)
+	}
+
+	// 与已提交的待审核值相同时不再更新
+	tempOutput := 8.0
+	old.TempModel = &old.Model
+	old.TempOutputPrice = &tempOutput
+	old.TempCachedTokens = &cached
+	if changes := diffPrice(pendingPrice(old), new); len(changes) != 0 {
+		t.Errorf("待审核值相同时不应有变化: %+v", changes)
+	}
+}
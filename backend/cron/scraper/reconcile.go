@@ -0,0 +1,307 @@
+package scraper
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"aimodels-prices/catalog"
+	"aimodels-prices/cron/lease"
+	"aimodels-prices/database"
+	"aimodels-prices/handlers"
+	"aimodels-prices/handlers/one_hub"
+	"aimodels-prices/models"
+	"aimodels-prices/notification"
+
+	"gorm.io/gorm"
+)
+
+// Result 一次抓取的处理结果
+type Result struct {
+	Fetched  int `json:"fetched"`  // 抓取到的价格数
+	Created  int `json:"created"`  // 新建的价格数
+	Updated  int `json:"updated"`  // 更新的价格数
+	Pending  int `json:"pending"`  // 新建和更新中进入待审核的价格数
+	Skipped  int `json:"skipped"`  // 无变化或处理失败的价格数
+	Delisted int `json:"delisted"` // 上游已不再提供而标记下架的价格数
+}
+
+// ErrRunning 同一来源的任务正在执行
+var ErrRunning = errors.New("价格任务正在执行")
+
+// JobLeaseTTL 任务租约有效期，执行期间定期续期，实例崩溃后过期由其他实例接管
+const JobLeaseTTL = 2 * time.Minute
+
+// 同一来源同时只允许执行一次，进程内用 running 判断，多实例间用数据库租约判断
+var running sync.Map
+
+// IsRunning 返回来源的任务是否正在当前实例或其他实例上执行
+func IsRunning(name string) bool {
+	if _, busy := running.Load(name); busy {
+		return true
+	}
+	held, err := lease.Held("scrape:" + name)
+	if err != nil {
+		log.Printf("查询%s任务租约失败: %v", name, err)
+	}
+	return held
+}
+
+// Run 抓取并调和指定来源的价格，记录执行历史，完成后清除倍率缓存
+// trigger 为触发方式（cron/startup/manual），triggeredBy 为手动触发的用户名
+func Run(ctx context.Context, source PriceSource, trigger, triggeredBy string) (Result, error) {
+	ctx, run, release, err := begin(ctx, source, trigger, triggeredBy)
+	if err != nil {
+		return Result{}, err
+	}
+	defer release()
+	return execute(ctx, source, run)
+}
+
+// Start 在后台执行任务，返回已创建的执行记录
+func Start(source PriceSource, trigger, triggeredBy string) (*models.ScrapeRun, error) {
+	ctx, run, release, err := begin(context.Background(), source, trigger, triggeredBy)
+	if err != nil {
+		return nil, err
+	}
+	snapshot := *run
+	go func() {
+		defer release()
+		execute(ctx, source, run)
+	}()
+	return &snapshot, nil
+}
+
+// begin 获取任务租约并创建执行记录，返回的 release 在任务结束后调用
+// 返回的 ctx 在租约续期失败时取消，任务应使用它执行
+func begin(parent context.Context, source PriceSource, trigger, triggeredBy string) (context.Context, *models.ScrapeRun, func(), error) {
+	name := source.Name()
+	if _, busy := running.LoadOrStore(name, true); busy {
+		return nil, nil, nil, fmt.Errorf("%s %w", name, ErrRunning)
+	}
+
+	ctx, releaseLease, ok, err := lease.Hold(parent, "scrape:"+name, JobLeaseTTL)
+	if err != nil {
+		running.Delete(name)
+		return nil, nil, nil, fmt.Errorf("获取%s任务租约失败: %v", name, err)
+	}
+	if !ok {
+		running.Delete(name)
+		return nil, nil, nil, fmt.Errorf("%s %w（其他实例）", name, ErrRunning)
+	}
+	release := func() {
+		releaseLease()
+		running.Delete(name)
+	}
+
+	run := &models.ScrapeRun{
+		Job:         name,
+		Trigger:     trigger,
+		TriggeredBy: triggeredBy,
+		Status:      models.ScrapeRunRunning,
+		StartedAt:   time.Now(),
+	}
+	// 执行记录写入失败不影响任务本身
+	if err := database.DB.Create(run).Error; err != nil {
+		log.Printf("创建%s任务执行记录失败: %v", name, err)
+	}
+	return ctx, run, release, nil
+}
+
+// execute 执行抓取和调和，并更新执行记录
+func execute(ctx context.Context, source PriceSource, run *models.ScrapeRun) (result Result, err error) {
+	name := source.Name()
+	defer func() {
+		finish(run, result, err)
+	}()
+
+	log.Printf("开始获取%s价格数据...", name)
+
+	prices, err := source.Fetch(ctx)
+	if err != nil {
+		return Result{}, fmt.Errorf("获取%s价格数据失败: %v", name, err)
+	}
+	if len(prices) == 0 {
+		return Result{}, fmt.Errorf("未解析到任何%s价格数据", name)
+	}
+
+	log.Printf("成功解析到 %d 个%s模型价格", len(prices), name)
+
+	result, err = Reconcile(ctx, source, prices)
+	if err != nil {
+		return result, err
+	}
+
+	log.Printf("%s价格数据处理完成，新建: %d, 更新: %d (待审核: %d), 跳过: %d, 下架: %d",
+		name, result.Created, result.Updated, result.Pending, result.Skipped, result.Delisted)
+
+	// 清除倍率缓存
+	one_hub.ClearRatesCache()
+	return result, nil
+}
+
+// finish 写入执行结果
+func finish(run *models.ScrapeRun, result Result, err error) {
+	now := time.Now()
+	run.FinishedAt = &now
+	run.Status = models.ScrapeRunSuccess
+	if err != nil {
+		run.Status = models.ScrapeRunFailed
+		run.Error = err.Error()
+	}
+	run.Fetched = result.Fetched
+	run.Created = result.Created
+	run.Updated = result.Updated
+	run.Pending = result.Pending
+	run.Skipped = result.Skipped
+	run.Delisted = result.Delisted
+
+	if database.DB == nil || run.ID == 0 {
+		return
+	}
+	if err := database.DB.Save(run).Error; err != nil {
+		log.Printf("更新%s任务执行记录失败: %v", run.Job, err)
+	}
+}
+
+// Reconcile 将抓取结果写入数据库，按来源的审核策略决定自动通过或进入待审核
+// ctx 取消（如任务租约失效）时停止写入，且不更新上架记录
+func Reconcile(ctx context.Context, source PriceSource, prices []ScrapedPrice) (Result, error) {
+	result := Result{Fetched: len(prices)}
+
+	db := database.DB
+	if db == nil {
+		return result, fmt.Errorf("获取数据库连接失败")
+	}
+
+	channelType := source.ChannelType()
+	policy := source.Approval()
+	seen := make(map[string]bool, len(prices))
+
+	// 重新出现的已下架模型先恢复，再按正常流程更新
+	if err := relist(db, channelType, listed(prices)); err != nil {
+		log.Printf("恢复已下架模型失败: %v", err)
+	}
+
+	for _, sp := range prices {
+		if err := ctx.Err(); err != nil {
+			return result, fmt.Errorf("%s任务已中止: %v", source.Name(), err)
+		}
+
+		price := sp.Price
+		price.ChannelType = channelType
+		price.CreatedBy = CreatedBy
+
+		if price.Model == "" || seen[price.Model] {
+			result.Skipped++
+			continue
+		}
+		seen[price.Model] = true
+
+		if sp.Incomplete != "" {
+			log.Printf("%s 价格不完整，保留已有价格: %s", price.Model, sp.Incomplete)
+			result.Skipped++
+			continue
+		}
+
+		// 在模型目录中登记该名称
+		catalog.Register(channelType, price.Model, CreatedBy)
+
+		// 写入模型元数据
+		if sp.Metadata != nil {
+			if _, err := handlers.SaveModelMetadata(channelType, price.Model, *sp.Metadata, policy != ApproveNone, CreatedBy); err != nil {
+				log.Printf("更新模型元数据失败 %s: %v", price.Model, err)
+			}
+		}
+
+		existing, isAdmin, err := lookup(db, policy, price)
+		if err != nil {
+			log.Printf("查询价格记录时发生错误 %s: %v", price.Model, err)
+			result.Skipped++
+			continue
+		}
+
+		saved, changed, err := handlers.ProcessPrice(price, existing, isAdmin, CreatedBy)
+		if errors.Is(err, handlers.ErrDuplicatePrice) && existing == nil {
+			// 其他实例或用户同时创建了该模型，改为更新已有记录
+			existing, isAdmin, err = lookup(db, policy, price)
+			if err == nil && existing == nil {
+				err = handlers.ErrDuplicatePrice
+			}
+			if err == nil {
+				saved, changed, err = handlers.ProcessPrice(price, existing, isAdmin, CreatedBy)
+			}
+		}
+		if err != nil {
+			log.Printf("保存价格记录失败 %s: %v", price.Model, err)
+			result.Skipped++
+			continue
+		}
+		if !changed {
+			result.Skipped++
+			continue
+		}
+
+		if existing == nil {
+			result.Created++
+		} else {
+			result.Updated++
+		}
+		if saved.Status == "pending" {
+			result.Pending++
+			if existing == nil {
+				log.Printf("发现新模型，已提交审核: %s", price.Model)
+			}
+		}
+	}
+
+	// 记录本次出现的模型，连续多次未出现的标记为下架
+	if err := ctx.Err(); err != nil {
+		return result, fmt.Errorf("%s任务已中止: %v", source.Name(), err)
+	}
+	delisted, err := trackListings(db, source, listed(prices))
+	if err != nil {
+		log.Printf("更新%s模型上架记录失败: %v", source.Name(), err)
+	}
+	result.Delisted = len(delisted)
+	if len(delisted) > 0 {
+		log.Printf("%s 中 %d 个模型已不再提供，标记为下架: %v", source.Name(), len(delisted), delisted)
+		if err := notification.NewFeishuWebhook().SendDelistedNotification(source.Name(), delisted); err != nil {
+			log.Printf("发送下架通知失败: %v", err)
+		}
+	}
+
+	return result, nil
+}
+
+// listed 返回抓取结果中去重后的模型名称
+func listed(prices []ScrapedPrice) []string {
+	seen := make(map[string]bool, len(prices))
+	names := make([]string, 0, len(prices))
+	for _, sp := range prices {
+		if sp.Price.Model != "" && !seen[sp.Price.Model] {
+			seen[sp.Price.Model] = true
+			names = append(names, sp.Price.Model)
+		}
+	}
+	return names
+}
+
+// lookup 查询已有价格记录，并按审核策略决定是否以管理员身份写入
+func lookup(db *gorm.DB, policy ApprovalPolicy, price models.Price) (*models.Price, bool, error) {
+	var existing models.Price
+	err := db.Where("model = ? AND channel_type = ?", price.Model, price.ChannelType).First(&existing).Error
+	switch {
+	case err == nil:
+		// 待审核的记录以修改建议提交，避免绕过审核；已下架的记录会先恢复为已审核
+		known := existing.Status == "approved" || existing.Status == "delisted"
+		return &existing, policy == ApproveAll || (policy == ApproveKnown && known), nil
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return nil, policy == ApproveAll, nil
+	default:
+		return nil, false, err
+	}
+}
@@ -0,0 +1,51 @@
+package scraper
+
+import (
+	"log"
+	"os"
+	"strings"
+)
+
+// DefaultSchedule 默认执行计划：每4小时执行一次（秒级cron表达式）
+const DefaultSchedule = "0 0 */4 * * *"
+
+// ScheduleDisabled 执行计划设置为该值时不注册定时任务
+const ScheduleDisabled = "off"
+
+// Schedules 从环境变量 CRON_SCHEDULES 读取各来源的执行计划
+// 格式为 "名称=cron表达式"，多个用分号分隔，例如：
+//
+//	CRON_SCHEDULES="openrouter=0 0 */2 * * *;dashscope=off"
+func Schedules() map[string]string {
+	return parseSchedules(os.Getenv("CRON_SCHEDULES"))
+}
+
+// Schedule 返回来源的执行计划，未配置时使用默认计划，禁用时返回空字符串
+func Schedule(schedules map[string]string, name string) string {
+	spec, ok := schedules[name]
+	if !ok {
+		return DefaultSchedule
+	}
+	if strings.EqualFold(spec, ScheduleDisabled) {
+		return ""
+	}
+	return spec
+}
+
+func parseSchedules(value string) map[string]string {
+	schedules := make(map[string]string)
+	for _, item := range strings.Split(value, ";") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		name, spec, ok := strings.Cut(item, "=")
+		name, spec = strings.TrimSpace(name), strings.TrimSpace(spec)
+		if !ok || name == "" || spec == "" {
+			log.Printf("忽略无效的执行计划配置: %s", item)
+			continue
+		}
+		schedules[name] = spec
+	}
+	return schedules
+}
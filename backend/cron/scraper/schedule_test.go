@@ -0,0 +1,20 @@
+package scraper
+
+import "testing"
+
+func TestSchedule(t *testing.T) {
+	schedules := parseSchedules(" openrouter = 0 0 */2 * * * ;dashscope=OFF;invalid;=x;")
+
+	if len(schedules) != 2 {
+		t.Fatalf("解析结果数量错误: %v", schedules)
+	}
+	if got := Schedule(schedules, "openrouter"); got != "0 0 */2 * * *" {
+		t.Errorf("openrouter 执行计划错误: %q", got)
+	}
+	if got := Schedule(schedules, "dashscope"); got != "" {
+		t.Errorf("dashscope 应被禁用: %q", got)
+	}
+	if got := Schedule(schedules, "openai"); got != DefaultSchedule {
+		t.Errorf("未配置的来源应使用默认计划: %q", got)
+	}
+}
@@ -0,0 +1,115 @@
+package scraper
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"aimodels-prices/models"
+)
+
+// CreatedBy 定时任务写入价格时使用的用户名
+const CreatedBy = "cron自动任务"
+
+// ApprovalPolicy 抓取结果的默认审核策略
+type ApprovalPolicy int
+
+const (
+	// ApproveAll 所有价格自动审核通过，适用于厂商官方API等可信来源
+	ApproveAll ApprovalPolicy = iota
+	// ApproveKnown 已审核的模型自动更新，新模型和待审核模型进入审核队列
+	ApproveKnown
+	// ApproveNone 所有价格都需要人工审核
+	ApproveNone
+)
+
+// String 返回审核策略名称
+func (p ApprovalPolicy) String() string {
+	switch p {
+	case ApproveAll:
+		return "all"
+	case ApproveKnown:
+		return "known"
+	case ApproveNone:
+		return "none"
+	default:
+		return "unknown"
+	}
+}
+
+// MarshalText 以名称形式输出到JSON
+func (p ApprovalPolicy) MarshalText() ([]byte, error) {
+	return []byte(p.String()), nil
+}
+
+// ParseApprovalPolicy 按名称解析审核策略
+func ParseApprovalPolicy(name string) (ApprovalPolicy, error) {
+	switch name {
+	case "all":
+		return ApproveAll, nil
+	case "known":
+		return ApproveKnown, nil
+	case "none":
+		return ApproveNone, nil
+	default:
+		return ApproveNone, fmt.Errorf("未知的审核策略: %s", name)
+	}
+}
+
+// ScrapedPrice 抓取到的单个模型价格
+// Price 的 ChannelType 和 CreatedBy 由调和器统一填写
+type ScrapedPrice struct {
+	Price    models.Price
+	Metadata *models.ModelMetadata // 可选，模型元数据
+	// Incomplete 非空时表示来源仍提供该模型，但本次未取得完整价格（如详情接口失败），内容为原因
+	// 只记录上架状态，不修改已有价格
+	Incomplete string
+}
+
+// PriceSource 价格来源，新增厂商只需实现该接口并在 cron.Init 中注册
+type PriceSource interface {
+	// Name 来源名称，用于注册、日志和执行计划配置，如 "openai"
+	Name() string
+	// ChannelType 价格写入的厂商ID
+	ChannelType() uint
+	// Fetch 抓取并解析价格，不访问数据库
+	Fetch(ctx context.Context) ([]ScrapedPrice, error)
+	// Approval 默认审核策略
+	Approval() ApprovalPolicy
+}
+
+var (
+	registryMu sync.RWMutex
+	registry   = make(map[string]PriceSource)
+	ordered    []PriceSource // 按注册顺序保存，决定启动时的执行顺序
+)
+
+// Register 注册价格来源，名称重复时panic
+func Register(source PriceSource) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	name := source.Name()
+	if _, exists := registry[name]; exists {
+		panic(fmt.Sprintf("价格来源重复注册: %s", name))
+	}
+	registry[name] = source
+	ordered = append(ordered, source)
+}
+
+// Get 按名称获取价格来源
+func Get(name string) (PriceSource, bool) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	source, ok := registry[name]
+	return source, ok
+}
+
+// Sources 返回所有已注册的价格来源，按注册顺序排列
+func Sources() []PriceSource {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	return append([]PriceSource(nil), ordered...)
+}
@@ -1,42 +1,44 @@
 package siliconflow_api
 
 import (
+	"context"
 	"encoding/json"
-	"errors"
 	"fmt"
-	"io"
 	"log"
 	"math"
 	"net/http"
+	"net/url"
 	"os"
+	"sort"
 	"strconv"
 	"strings"
+	"sync"
+	"time"
 
-	"aimodels-prices/database"
-	"aimodels-prices/handlers"
-	"aimodels-prices/handlers/one_hub"
+	"aimodels-prices/cron/scraper"
+	"aimodels-prices/httpclient"
 	"aimodels-prices/models"
-
-	"gorm.io/gorm"
 )
 
 // 常量定义
 const (
-	SiliconFlowChannelType = 45 // SiliconFlow的厂商ID
-	SiliconFlowAPIEndpoint = "/api/v1/playground/comprehensive/all"
-	SiliconFlowAPIHost     = "busy-bear.siliconflow.cn"
-	PriceSource            = "SiliconFlow API"
-	Status                 = "approved" // 设置为approved状态
-	CreatedBy              = "cron自动任务"
-	Currency               = "CNY" // 使用人民币
+	SiliconFlowChannelType    = 45 // SiliconFlow的厂商ID
+	SiliconFlowAPIEndpoint    = "/api/v1/playground/comprehensive/all"
+	SiliconFlowDetailEndpoint = "/api/v1/playground/comprehensive/model" // 模型详情，包含分开的输入、输出价格
+	PriceSource               = "SiliconFlow API"
+	Currency                  = "CNY" // 使用人民币
 )
 
-// 计费类型常量
 const (
-	BillingTypeTokens = "tokens" // 基于token的计费方式
-	BillingTypeTimes  = "times"  // 基于次数的计费方式
+	// detailConcurrency 同时查询模型详情的请求数
+	detailConcurrency = 4
+	// detailCacheTTL 模型详情的缓存时间，列表价格变化时重新查询
+	detailCacheTTL = 24 * time.Hour
 )
 
+// SiliconFlowBaseURL API地址，测试时替换为本地服务
+var SiliconFlowBaseURL = "https://busy-bear.siliconflow.cn"
+
 // 定义API响应结构
 type SiliconFlowResponse struct {
 	Code    int    `json:"code"`
@@ -47,6 +49,14 @@ type SiliconFlowResponse struct {
 	} `json:"data"`
 }
 
+// SiliconFlowDetailResponse 模型详情接口响应
+type SiliconFlowDetailResponse struct {
+	Code    int              `json:"code"`
+	Message string           `json:"message"`
+	Status  bool             `json:"status"`
+	Data    SiliconFlowModel `json:"data"`
+}
+
 // 模型信息结构
 type SiliconFlowModel struct {
 	ModelId             string   `json:"modelId"`
@@ -58,7 +68,9 @@ type SiliconFlowModel struct {
 	Icon                string   `json:"icon"`
 	Size                int      `json:"size"`
 	ContextLen          int      `json:"contextLen"`
-	Price               string   `json:"price"`
+	Price               string   `json:"price"`       // 列表接口只返回一个价格，按token计费的模型为输入价格
+	InputPrice          string   `json:"inputPrice"`  // 输入价格，详情接口返回
+	OutputPrice         string   `json:"outputPrice"` // 输出价格，详情接口返回
 	Currency            string   `json:"currency"`
 	PriceUnit           string   `json:"priceUnit"`
 	Status              string   `json:"status"`
@@ -68,151 +80,97 @@ type SiliconFlowModel struct {
 	FunctionCallSupport bool     `json:"functionCallSupport"`
 }
 
-// UpdateSiliconFlowPrices 更新SiliconFlow模型价格
-func UpdateSiliconFlowPrices() error {
-	log.Println("开始更新SiliconFlow价格数据...")
+// Source SiliconFlow价格来源，价格自动审核通过
+type Source struct{}
+
+// Name 来源名称
+func (Source) Name() string { return "siliconflow" }
 
+// ChannelType 厂商ID
+func (Source) ChannelType() uint { return SiliconFlowChannelType }
+
+// Approval 默认审核策略
+func (Source) Approval() scraper.ApprovalPolicy { return scraper.ApproveAll }
+
+// Fetch 获取SiliconFlow模型价格和模型元数据
+func (Source) Fetch(ctx context.Context) ([]scraper.ScrapedPrice, error) {
 	// 获取API数据
-	modelData, err := fetchSiliconFlowData()
+	modelData, err := fetchSiliconFlowData(ctx)
 	if err != nil {
-		return fmt.Errorf("获取SiliconFlow数据失败: %v", err)
+		return nil, fmt.Errorf("获取SiliconFlow数据失败: %v", err)
 	}
 
-	// 获取数据库连接
-	db := database.DB
-	if db == nil {
-		return fmt.Errorf("获取数据库连接失败")
+	// 按token计费的模型需要分开的输入、输出价格，列表接口没有时查询模型详情
+	if err := fillDetails(ctx, modelData); err != nil {
+		log.Printf("%v", err)
 	}
 
-	// 处理每个模型的价格数据
-	processedCount := 0
-	skippedCount := 0
-
-	// 创建一个集合用于跟踪已处理的模型，避免重复
-	processedModels := make(map[string]bool)
-
+	var prices []scraper.ScrapedPrice
 	for _, model := range modelData {
-		modelName := model.ModelName
-
-		// 检查是否已处理过这个模型
-		if processedModels[modelName] {
-			skippedCount++
+		// 列表价格只是输入价格，不能作为输出价格，缺少时保留已有价格
+		if isTokenPriced(model) && model.OutputPrice == "" {
+			prices = append(prices, scraper.ScrapedPrice{
+				Price:      models.Price{Model: model.ModelName},
+				Incomplete: "缺少输出价格",
+			})
 			continue
 		}
 
-		// 标记此模型为已处理
-		processedModels[modelName] = true
-
-		// 解析价格
-		modelPrice, err := strconv.ParseFloat(model.Price, 64)
+		price, err := buildPrice(model)
 		if err != nil {
-			log.Printf("解析价格失败 %s: %v", modelName, err)
-			skippedCount++
+			log.Printf("解析价格失败 %s: %v", model.ModelName, err)
 			continue
 		}
 
-		// 确定计费类型和价格
-		var billingType string
-		var inputPrice, outputPrice float64
-
-		// 根据价格单位确定计费类型和价格计算方式
-		switch {
-		case isTokenBasedUnit(model.PriceUnit):
-			// 基于Token的模型（如文本模型）
-			billingType = BillingTypeTokens // 使用tokens计费类型
-			// 直接使用价格，系统已经按每百万token为单位
-			inputPrice = roundPrice(modelPrice)
-			outputPrice = inputPrice // 使用相同价格
-		case isTimeBasedUnit(model.PriceUnit, model.Type):
-			// 基于次数的模型（如图像、视频）
-			billingType = BillingTypeTimes // 使用times计费类型
-			// 直接使用价格
-			inputPrice = roundPrice(modelPrice)
-			outputPrice = inputPrice // 使用相同价格
-		default:
-			// 默认按token计费
-			billingType = BillingTypeTokens // 默认使用tokens
-			// 对于未知类型，默认按token处理
-			inputPrice = roundPrice(modelPrice)
-			outputPrice = inputPrice // 使用相同价格
-			log.Printf("未识别的价格单位: %s，默认使用计费类型: %s", model.PriceUnit, billingType)
-		}
-
-		// 创建价格对象
-		price := models.Price{
-			Model:       modelName,
-			BillingType: billingType, // 使用动态确定的计费类型
-			ChannelType: SiliconFlowChannelType,
-			Currency:    Currency, // 使用人民币
-			InputPrice:  inputPrice,
-			OutputPrice: outputPrice,
-			PriceSource: PriceSource,
-			Status:      Status, // 使用approved状态
-			CreatedBy:   CreatedBy,
-		}
-
-		// 检查是否已存在相同模型的价格记录
-		var existingPrice models.Price
-		// 使用静默查询，不输出"record not found"错误
-		result := db.Where("model = ? AND channel_type = ?", modelName, SiliconFlowChannelType).First(&existingPrice)
-
-		if result.Error == nil {
-			// 记录存在，执行更新
-			_, changed, err := handlers.ProcessPrice(price, &existingPrice, true, CreatedBy)
-			if err != nil {
-				log.Printf("更新价格记录失败 %s: %v", modelName, err)
-				skippedCount++
-				continue
-			}
+		meta := buildModelMetadata(model)
+		prices = append(prices, scraper.ScrapedPrice{Price: price, Metadata: &meta})
+	}
 
-			if changed {
-				processedCount++
-			} else {
-				skippedCount++
-			}
-		} else if errors.Is(result.Error, gorm.ErrRecordNotFound) {
-			// 记录不存在，需要创建新记录
-			// 检查是否存在相同模型名称的待审核记录
-			var pendingCount int64
-			if err := db.Model(&models.Price{}).Where("model = ? AND channel_type = ? AND status = 'pending'",
-				modelName, SiliconFlowChannelType).Count(&pendingCount).Error; err != nil {
-				log.Printf("检查待审核记录失败 %s: %v", modelName, err)
-			}
+	return prices, nil
+}
 
-			if pendingCount > 0 {
-				log.Printf("已存在待审核的相同模型记录，跳过创建: %s", modelName)
-				skippedCount++
-				continue
-			}
+// isTokenPriced 是否按token计费
+func isTokenPriced(model SiliconFlowModel) bool {
+	unit, _ := parseBillingUnit(model.PriceUnit, model.Type)
+	return unit == models.BillingUnitTokens
+}
 
-			// 创建新记录
-			_, changed, err := handlers.ProcessPrice(price, nil, true, CreatedBy)
-			if err != nil {
-				log.Printf("创建价格记录失败 %s: %v", modelName, err)
-				skippedCount++
-				continue
-			}
+// buildPrice 将模型信息转换为价格记录
+// 按token计费时使用分开的输入、输出价格，缺少输出价格时返回错误；其他计费单位（图片、视频等）按单位价格计费
+func buildPrice(model SiliconFlowModel) (models.Price, error) {
+	billingUnit, unitMeta := parseBillingUnit(model.PriceUnit, model.Type)
 
-			if changed {
-				processedCount++
-			} else {
-				log.Printf("价格创建失败: %s", modelName)
-				skippedCount++
-			}
-		} else {
-			// 其他错误
-			log.Printf("查询价格记录时发生错误 %s: %v", modelName, result.Error)
-			skippedCount++
-			continue
+	inputStr, outputStr := model.Price, model.Price
+	if billingUnit == models.BillingUnitTokens {
+		if model.InputPrice != "" {
+			inputStr = model.InputPrice
 		}
+		if model.OutputPrice == "" {
+			return models.Price{}, fmt.Errorf("缺少输出价格")
+		}
+		outputStr = model.OutputPrice
 	}
 
-	log.Printf("SiliconFlow价格数据处理完成，成功处理: %d, 跳过: %d", processedCount, skippedCount)
+	inputPrice, err := strconv.ParseFloat(inputStr, 64)
+	if err != nil {
+		return models.Price{}, fmt.Errorf("输入价格无效: %v", err)
+	}
+	outputPrice, err := strconv.ParseFloat(outputStr, 64)
+	if err != nil {
+		return models.Price{}, fmt.Errorf("输出价格无效: %v", err)
+	}
 
-	// 清除倍率缓存
-	one_hub.ClearRatesCache()
-	log.Println("倍率缓存已清除")
-	return nil
+	return models.Price{
+		Model:       model.ModelName,
+		BillingType: models.BillingTypeForUnit(billingUnit),
+		BillingUnit: billingUnit,
+		UnitMeta:    unitMeta,
+		ChannelType: SiliconFlowChannelType,
+		Currency:    Currency, // 使用人民币
+		InputPrice:  roundPrice(inputPrice),
+		OutputPrice: roundPrice(outputPrice), // 按次计费时与输入价格相同
+		PriceSource: PriceSource,
+	}, nil
 }
 
 // roundPrice 对价格进行四舍五入处理，保留6位小数
@@ -222,14 +180,14 @@ func roundPrice(price float64) float64 {
 }
 
 // fetchSiliconFlowData 获取SiliconFlow API数据
-func fetchSiliconFlowData() ([]SiliconFlowModel, error) {
+func fetchSiliconFlowData(ctx context.Context) ([]SiliconFlowModel, error) {
 	apiKey := os.Getenv("SILICONFLOW_API_KEY")
 	if apiKey == "" {
 		return nil, fmt.Errorf("环境变量SILICONFLOW_API_KEY未设置")
 	}
 
 	// 创建HTTPS连接
-	conn, err := http.NewRequest("GET", "https://"+SiliconFlowAPIHost+SiliconFlowAPIEndpoint, nil)
+	conn, err := http.NewRequestWithContext(ctx, "GET", SiliconFlowBaseURL+SiliconFlowAPIEndpoint, nil)
 	if err != nil {
 		return nil, fmt.Errorf("创建HTTP请求失败: %v", err)
 	}
@@ -238,18 +196,10 @@ func fetchSiliconFlowData() ([]SiliconFlowModel, error) {
 	conn.Header.Add("Authorization", fmt.Sprintf("Bearer %s", apiKey))
 
 	// 发送请求
-	client := &http.Client{}
-	resp, err := client.Do(conn)
+	body, err := httpclient.For("siliconflow").Fetch(conn)
 	if err != nil {
 		return nil, fmt.Errorf("请求SiliconFlow API失败: %v", err)
 	}
-	defer resp.Body.Close()
-
-	// 读取响应内容
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, fmt.Errorf("读取响应内容失败: %v", err)
-	}
 
 	// 解析JSON数据
 	var siliconFlowResp SiliconFlowResponse
@@ -265,40 +215,186 @@ func fetchSiliconFlowData() ([]SiliconFlowModel, error) {
 	return siliconFlowResp.Data.Models, nil
 }
 
-// isTokenBasedUnit 判断是否是基于token的计费单位
-func isTokenBasedUnit(unit string) bool {
-	tokenUnits := []string{
-		"/ M Tokens",
-		"/ M UTF-8 bytes",
-		"/ M px / Steps",
-	}
+// cachedDetail 缓存的模型详情
+type cachedDetail struct {
+	detail    SiliconFlowModel
+	fetchedAt time.Time
+}
+
+// detailCache 按模型名称和列表价格缓存查询成功的模型详情，跨运行保留
+// 不使用 database.GlobalCache，修改任意价格时会清空全部缓存
+var (
+	detailMu    sync.Mutex
+	detailCache = make(map[string]cachedDetail)
+)
+
+func detailKey(model SiliconFlowModel) string {
+	return model.ModelName + "|" + model.Price
+}
 
-	for _, tokenUnit := range tokenUnits {
-		if strings.Contains(unit, tokenUnit) {
-			return true
+// fillDetails 为缺少分开价格的按token计费模型补充详情中的输入、输出价格
+// 限制并发请求数，优先使用缓存；查询失败的模型保持不变，返回汇总的错误
+func fillDetails(ctx context.Context, list []SiliconFlowModel) error {
+	var (
+		wg     sync.WaitGroup
+		mu     sync.Mutex
+		failed = make(map[string]error)
+		sem    = make(chan struct{}, detailConcurrency)
+	)
+
+	for i := range list {
+		model := &list[i]
+		if !isTokenPriced(*model) || (model.InputPrice != "" && model.OutputPrice != "") {
+			continue
 		}
+
+		key := detailKey(*model)
+		detailMu.Lock()
+		cached, ok := detailCache[key]
+		detailMu.Unlock()
+		if ok && time.Since(cached.fetchedAt) < detailCacheTTL {
+			model.InputPrice, model.OutputPrice = cached.detail.InputPrice, cached.detail.OutputPrice
+			continue
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			detail, err := fetchModelDetail(ctx, model.ModelName)
+			if err == nil && detail.OutputPrice == "" {
+				err = fmt.Errorf("详情中没有输出价格")
+			}
+			if err != nil {
+				mu.Lock()
+				failed[model.ModelName] = err
+				mu.Unlock()
+				return
+			}
+
+			model.InputPrice, model.OutputPrice = detail.InputPrice, detail.OutputPrice
+			detailMu.Lock()
+			detailCache[key] = cachedDetail{detail: detail, fetchedAt: time.Now()}
+			detailMu.Unlock()
+		}()
+	}
+	wg.Wait()
+
+	if len(failed) == 0 {
+		return nil
+	}
+	names := make([]string, 0, len(failed))
+	for name := range failed {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	shown := names
+	if len(shown) > 5 {
+		shown = shown[:5]
 	}
-	return false
+	return fmt.Errorf("获取 %d 个SiliconFlow模型详情失败（%s 等），%s: %v",
+		len(names), strings.Join(shown, ", "), names[0], failed[names[0]])
 }
 
-// isTimeBasedUnit 判断是否是基于次数的计费单位
-func isTimeBasedUnit(unit string, modelType string) bool {
-	timeUnits := []string{
-		"/ Video",
-		"/ Image",
-		"",
+// fetchModelDetail 获取单个模型的详情
+func fetchModelDetail(ctx context.Context, modelName string) (SiliconFlowModel, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", SiliconFlowBaseURL+SiliconFlowDetailEndpoint+"?modelName="+url.QueryEscape(modelName), nil)
+	if err != nil {
+		return SiliconFlowModel{}, fmt.Errorf("创建HTTP请求失败: %v", err)
+	}
+	req.Header.Add("Authorization", fmt.Sprintf("Bearer %s", os.Getenv("SILICONFLOW_API_KEY")))
+
+	body, err := httpclient.For("siliconflow").Fetch(req)
+	if err != nil {
+		return SiliconFlowModel{}, fmt.Errorf("请求SiliconFlow模型详情失败: %v", err)
+	}
+
+	var detailResp SiliconFlowDetailResponse
+	if err := json.Unmarshal(body, &detailResp); err != nil {
+		return SiliconFlowModel{}, fmt.Errorf("解析JSON数据失败: %v", err)
+	}
+	if !detailResp.Status || detailResp.Code != 20000 {
+		return SiliconFlowModel{}, fmt.Errorf("API请求返回错误: %s", detailResp.Message)
 	}
 
-	// 如果模型类型是视频或图像，即使价格单位为空也按次数计费
-	if modelType == "video" || modelType == "image" {
-		return true
+	return detailResp.Data, nil
+}
+
+// buildModelMetadata 从API返回的模型信息构建模型元数据
+func buildModelMetadata(model SiliconFlowModel) models.ModelMetadata {
+	meta := models.ModelMetadata{Source: PriceSource}
+
+	if model.ContextLen > 0 {
+		contextLen := model.ContextLen
+		meta.ContextLength = &contextLen
 	}
 
-	for _, timeUnit := range timeUnits {
-		if strings.Contains(unit, timeUnit) {
-			return true
+	// 根据标签和子类型判断是否支持图片理解
+	vision := strings.Contains(strings.ToLower(model.SubType), "vision")
+	for _, tag := range model.Tags {
+		if strings.Contains(strings.ToLower(tag), "vision") || strings.Contains(tag, "视觉") {
+			vision = true
 		}
 	}
-	return false
+
+	inputModalities, outputModalities := "text", "text"
+	switch strings.ToLower(model.Type) {
+	case "image":
+		outputModalities = "image"
+	case "video":
+		outputModalities = "video"
+	case "audio":
+		if strings.Contains(strings.ToLower(model.SubType), "speech-to-text") {
+			inputModalities = "audio"
+		} else {
+			outputModalities = "audio"
+		}
+	case "text":
+		// 只有文本模型返回工具调用和JSON模式的支持情况
+		tools := model.FunctionCallSupport
+		jsonMode := model.JsonModeSupport
+		meta.SupportsTools = &tools
+		meta.SupportsJSONMode = &jsonMode
+	}
+	if vision {
+		inputModalities += ",image"
+	}
+
+	meta.InputModalities = &inputModalities
+	meta.OutputModalities = &outputModalities
+	meta.SupportsVision = &vision
+
+	return meta
 }
 
+// parseBillingUnit 根据SiliconFlow的价格单位确定计费单位
+// 例如 "/ M Tokens"、"/ M UTF-8 bytes"、"/ M px / Steps"、"/ Video"、"/ Image"
+func parseBillingUnit(priceUnit string, modelType string) (string, *models.UnitMeta) {
+	meta := &models.UnitMeta{Raw: priceUnit}
+
+	switch {
+	case strings.Contains(priceUnit, "/ M Tokens"):
+		// 保留原始单位，价格本身即为每百万token
+		return models.BillingUnitTokens, meta
+	case strings.Contains(priceUnit, "/ M UTF-8 bytes"):
+		// 语音合成按UTF-8字节数计费，中文一个字为3字节，不能按字符计算
+		meta.Quantity = 1000000
+		return models.BillingUnitByte, meta
+	case strings.Contains(priceUnit, "/ M px / Steps"):
+		// 图片生成按像素和步数计费
+		meta.Quantity = 1000000
+		return models.BillingUnitPixelStep, meta
+	case strings.Contains(priceUnit, "/ Image"), modelType == "image":
+		return models.BillingUnitImage, meta
+	case strings.Contains(priceUnit, "/ Video"), modelType == "video":
+		// 按生成的视频个数计费，即每次请求
+		return models.BillingUnitRequest, meta
+	case priceUnit == "":
+		return models.BillingUnitRequest, nil
+	default:
+		log.Printf("未识别的价格单位: %s，默认按次计费", priceUnit)
+		return models.BillingUnitRequest, meta
+	}
+}
@@ -0,0 +1,159 @@
+package siliconflow_api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"aimodels-prices/models"
+)
+
+// resetDetailCache 清空模型详情缓存，避免测试之间互相影响
+func resetDetailCache() {
+	detailMu.Lock()
+	detailCache = make(map[string]cachedDetail)
+	detailMu.Unlock()
+}
+
+func TestFetch(t *testing.T) {
+	resetDetailCache()
+	var detailRequests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var file string
+		switch r.URL.Path {
+		case SiliconFlowAPIEndpoint:
+			file = "testdata/comprehensive.json"
+		case SiliconFlowDetailEndpoint:
+			detailRequests.Add(1)
+			if r.URL.Query().Get("modelName") != "Qwen/Qwen2.5-VL-72B-Instruct" {
+				t.Errorf("不应查询模型详情: %s", r.URL.Query().Get("modelName"))
+			}
+			file = "testdata/detail.json"
+		default:
+			http.NotFound(w, r)
+			return
+		}
+		content, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatalf("读取测试数据失败: %v", err)
+		}
+		w.Write(content)
+	}))
+	defer server.Close()
+
+	t.Setenv("SILICONFLOW_API_KEY", "test")
+	original := SiliconFlowBaseURL
+	SiliconFlowBaseURL = server.URL
+	defer func() { SiliconFlowBaseURL = original }()
+
+	prices, err := Source{}.Fetch(context.Background())
+	if err != nil {
+		t.Fatalf("Fetch 失败: %v", err)
+	}
+
+	type want struct {
+		unit     string
+		input    float64
+		output   float64
+		quantity float64
+	}
+	wants := map[string]want{
+		// 列表接口直接返回分开的价格
+		"deepseek-ai/DeepSeek-V3": {unit: models.BillingUnitTokens, input: 2, output: 8, quantity: 1},
+		// 列表接口只有单个价格，从模型详情获取输出价格
+		"Qwen/Qwen2.5-VL-72B-Instruct": {unit: models.BillingUnitTokens, input: 4.13, output: 12.39, quantity: 1},
+		// 图片、视频按次计费，输出价格与输入价格相同
+		"black-forest-labs/FLUX.1-dev":           {unit: models.BillingUnitImage, input: 0.14, output: 0.14, quantity: 1},
+		"stabilityai/stable-diffusion-3-5-large": {unit: models.BillingUnitPixelStep, input: 0.0032, output: 0.0032, quantity: 1000000},
+		"Wan-AI/Wan2.1-T2V-14B":                  {unit: models.BillingUnitRequest, input: 2, output: 2, quantity: 1},
+		"FunAudioLLM/CosyVoice2-0.5B":            {unit: models.BillingUnitByte, input: 50, output: 50, quantity: 1000000},
+	}
+
+	if len(prices) != len(wants) {
+		t.Fatalf("解析到 %d 个模型，期望 %d 个", len(prices), len(wants))
+	}
+	for _, sp := range prices {
+		price := sp.Price
+		w, ok := wants[price.Model]
+		if !ok {
+			t.Errorf("多余的模型: %s", price.Model)
+			continue
+		}
+		if price.Unit() != w.unit || price.InputPrice != w.input || price.OutputPrice != w.output || price.UnitQuantity() != w.quantity {
+			t.Errorf("%s 价格错误: unit=%s input=%v output=%v quantity=%v", price.Model, price.Unit(), price.InputPrice, price.OutputPrice, price.UnitQuantity())
+		}
+		// 保留原始价格单位
+		if price.UnitMeta == nil || price.UnitMeta.Raw == "" {
+			t.Errorf("%s 未记录原始价格单位", price.Model)
+		}
+	}
+
+	// 再次运行时使用缓存的模型详情
+	if _, err := (Source{}).Fetch(context.Background()); err != nil {
+		t.Fatalf("Fetch 失败: %v", err)
+	}
+	if n := detailRequests.Load(); n != 1 {
+		t.Errorf("模型详情请求 %d 次，期望 1 次", n)
+	}
+}
+
+func TestFetchDetailFailure(t *testing.T) {
+	resetDetailCache()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case SiliconFlowAPIEndpoint:
+			content, err := os.ReadFile("testdata/comprehensive.json")
+			if err != nil {
+				t.Fatalf("读取测试数据失败: %v", err)
+			}
+			w.Write(content)
+		case SiliconFlowDetailEndpoint:
+			w.Write([]byte(`{"code":50000,"message":"internal error","status":false}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	t.Setenv("SILICONFLOW_API_KEY", "test")
+	original := SiliconFlowBaseURL
+	SiliconFlowBaseURL = server.URL
+	defer func() { SiliconFlowBaseURL = original }()
+
+	prices, err := Source{}.Fetch(context.Background())
+	if err != nil {
+		t.Fatalf("Fetch 失败: %v", err)
+	}
+
+	found := false
+	for _, sp := range prices {
+		if sp.Price.Model != "Qwen/Qwen2.5-VL-72B-Instruct" {
+			continue
+		}
+		found = true
+		// 详情获取失败时不能用列表价格作为输出价格，只记录仍在提供
+		if sp.Incomplete == "" {
+			t.Errorf("详情获取失败时应标记为价格不完整，得到 input=%v output=%v", sp.Price.InputPrice, sp.Price.OutputPrice)
+		}
+	}
+	if !found {
+		t.Error("详情获取失败的模型仍应出现在结果中，避免被标记为下架")
+	}
+
+	// 失败汇总为一个错误，且不缓存
+	list := []SiliconFlowModel{
+		{ModelName: "a/model-1", Price: "1", PriceUnit: "/ M Tokens"},
+		{ModelName: "a/model-2", Price: "2", PriceUnit: "/ M Tokens"},
+	}
+	err = fillDetails(context.Background(), list)
+	if err == nil || !strings.Contains(err.Error(), "获取 2 个SiliconFlow模型详情失败") {
+		t.Errorf("应返回汇总的错误: %v", err)
+	}
+	if list[0].OutputPrice != "" || len(detailCache) != 0 {
+		t.Errorf("详情获取失败时不应设置或缓存价格: %+v", list[0])
+	}
+}
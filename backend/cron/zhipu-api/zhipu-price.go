@@ -0,0 +1,166 @@
+package zhipu_api
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"strings"
+
+	"aimodels-prices/cron/htmlutil"
+	"aimodels-prices/cron/scraper"
+	"aimodels-prices/httpclient"
+	"aimodels-prices/models"
+
+	"golang.org/x/net/html"
+)
+
+const (
+	ZhipuPricingURL  = "https://open.bigmodel.cn/pricing"
+	ZhipuChannelType = 16
+	BillingType      = "tokens"
+	Currency         = "CNY"
+	PriceSource      = "https://open.bigmodel.cn/pricing"
+)
+
+// ZhipuModelPrice 从页面解析出的模型价格数据，价格单位均为 元/1M tokens
+type ZhipuModelPrice struct {
+	Model       string
+	InputPrice  float64 // 输入（缓存未命中）
+	OutputPrice float64
+	CachedPrice float64 // 输入（缓存命中）
+}
+
+// Source 智谱价格来源，已审核的模型自动更新，新模型进入待审核队列
+type Source struct{}
+
+// Name 来源名称
+func (Source) Name() string { return "zhipu" }
+
+// ChannelType 厂商ID
+func (Source) ChannelType() uint { return ZhipuChannelType }
+
+// Approval 默认审核策略
+func (Source) Approval() scraper.ApprovalPolicy { return scraper.ApproveKnown }
+
+// Fetch 抓取定价页面并转换为价格记录
+func (Source) Fetch(ctx context.Context) ([]scraper.ScrapedPrice, error) {
+	prices, err := fetchZhipuPrices(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]scraper.ScrapedPrice, 0, len(prices))
+	for _, mp := range prices {
+		result = append(result, scraper.ScrapedPrice{Price: buildPrice(mp)})
+	}
+	return result, nil
+}
+
+// fetchZhipuPrices 抓取智谱定价页面并解析价格表格
+func fetchZhipuPrices(ctx context.Context) ([]ZhipuModelPrice, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", ZhipuPricingURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("创建HTTP请求失败: %v", err)
+	}
+	body, err := httpclient.For("zhipu").Fetch(req)
+	if err != nil {
+		return nil, fmt.Errorf("请求智谱定价页面失败: %v", err)
+	}
+
+	return parseHTMLPrices(string(body))
+}
+
+// buildPrice 将解析结果转换为价格记录
+func buildPrice(mp ZhipuModelPrice) models.Price {
+	price := models.Price{
+		Model:       mp.Model,
+		BillingType: BillingType,
+		ChannelType: ZhipuChannelType,
+		Currency:    Currency,
+		InputPrice:  mp.InputPrice,
+		OutputPrice: mp.OutputPrice,
+		PriceSource: PriceSource,
+	}
+
+	// 缓存命中价格
+	if mp.CachedPrice > 0 {
+		cached := mp.CachedPrice
+		price.CachedTokens = &cached
+	}
+
+	return price
+}
+
+// parseHTMLPrices 从HTML中解析价格表格
+// 价格表包含 模型/输入/缓存命中/输出 列，部分模型只有一个 单价 列（输入输出同价），价格为每百万Tokens
+func parseHTMLPrices(htmlContent string) ([]ZhipuModelPrice, error) {
+	doc, err := html.Parse(strings.NewReader(htmlContent))
+	if err != nil {
+		return nil, fmt.Errorf("解析HTML失败: %v", err)
+	}
+
+	var prices []ZhipuModelPrice
+	seen := make(map[string]bool)
+
+	for _, table := range htmlutil.Tables(doc) {
+		modelCol := table.Column("模型")
+		cachedCol := table.Column("缓存")
+		inputCol := table.Column("输入")
+		outputCol := table.Column("输出")
+		if inputCol < 0 && outputCol < 0 {
+			// 输入输出同价
+			inputCol = table.Column("单价")
+			outputCol = inputCol
+		}
+		if modelCol < 0 || inputCol < 0 || outputCol < 0 {
+			continue
+		}
+
+		for _, row := range table.Rows {
+			if len(row) <= modelCol || len(row) <= inputCol || len(row) <= outputCol {
+				continue
+			}
+			fields := strings.Fields(row[modelCol])
+			if len(fields) == 0 {
+				continue
+			}
+			model := strings.ToLower(fields[0])
+			if !strings.HasPrefix(model, "glm-") || seen[model] {
+				continue
+			}
+
+			input, err := htmlutil.ParseAmount(row[inputCol])
+			if err != nil {
+				log.Printf("解析输入价格失败 %s: %v", model, err)
+				continue
+			}
+			output, err := htmlutil.ParseAmount(row[outputCol])
+			if err != nil {
+				log.Printf("解析输出价格失败 %s: %v", model, err)
+				continue
+			}
+
+			mp := ZhipuModelPrice{Model: model, InputPrice: roundPrice(input), OutputPrice: roundPrice(output)}
+			if cachedCol >= 0 && cachedCol < len(row) {
+				if cached, err := htmlutil.ParseAmount(row[cachedCol]); err == nil {
+					mp.CachedPrice = roundPrice(cached)
+				}
+			}
+
+			seen[model] = true
+			prices = append(prices, mp)
+		}
+	}
+
+	if len(prices) == 0 {
+		return nil, fmt.Errorf("未找到智谱模型价格表格")
+	}
+	return prices, nil
+}
+
+// roundPrice 四舍五入到6位小数
+func roundPrice(price float64) float64 {
+	return math.Round(price*1000000) / 1000000
+}
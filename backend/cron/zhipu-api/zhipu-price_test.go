@@ -0,0 +1,33 @@
+package zhipu_api
+
+import (
+	"os"
+	"testing"
+)
+
+func TestParseHTMLPrices(t *testing.T) {
+	content, err := os.ReadFile("testdata/pricing.html")
+	if err != nil {
+		t.Fatalf("读取测试页面失败: %v", err)
+	}
+
+	prices, err := parseHTMLPrices(string(content))
+	if err != nil {
+		t.Fatalf("parseHTMLPrices 失败: %v", err)
+	}
+
+	want := []ZhipuModelPrice{
+		{Model: "glm-4.5", InputPrice: 2, OutputPrice: 8, CachedPrice: 0.4},
+		{Model: "glm-4.5-air", InputPrice: 0.8, OutputPrice: 2, CachedPrice: 0.16},
+		{Model: "glm-4-plus", InputPrice: 5, OutputPrice: 5},
+		{Model: "glm-4-flash", InputPrice: 0, OutputPrice: 0},
+	}
+	if len(prices) != len(want) {
+		t.Fatalf("解析到 %d 个模型，期望 %d 个: %+v", len(prices), len(want), prices)
+	}
+	for i := range want {
+		if prices[i] != want[i] {
+			t.Errorf("价格错误: got %+v, want %+v", prices[i], want[i])
+		}
+	}
+}
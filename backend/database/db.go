@@ -143,6 +143,8 @@ func InitDB(cfg *config.Config) error {
 	// 连接MySQL
 	DB, err = gorm.Open(mysql.Open(dsn), &gorm.Config{
 		Logger: logger.Default.LogMode(logger.Error),
+		// 将唯一索引冲突等错误转换为 gorm.ErrDuplicatedKey
+		TranslateError: true,
 	})
 	if err != nil {
 		return fmt.Errorf("failed to connect to MySQL: %v", err)
@@ -301,6 +303,17 @@ func migrateModels() error {
 		&models.Provider{},
 		&models.User{},
 		&models.Session{},
+		&models.Model{},
+		&models.ModelAlias{},
+		&models.ModelMetadata{},
+		&models.ScrapeRun{},
+		&models.SourceListing{},
+		&models.JobLease{},
+		&models.ProviderMapping{},
+		&models.SourceDefinition{},
+		&models.PriceEvent{},
+		&models.Webhook{},
+		&models.WebhookDelivery{},
 	); err != nil {
 		log.Printf("Failed to migrate tables: %v", err)
 		return err
@@ -0,0 +1,158 @@
+package events
+
+import (
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"aimodels-prices/database"
+	"aimodels-prices/models"
+)
+
+// Handler 事件订阅者，在发布事件的goroutine中同步调用，不能阻塞
+type Handler func(event models.PriceEvent)
+
+var (
+	mu       sync.RWMutex
+	handlers []Handler
+)
+
+// Subscribe 订阅价格变更事件，用于Webhook推送等
+func Subscribe(handler Handler) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	handlers = append(handlers, handler)
+}
+
+// Publish 记录价格变更事件并通知订阅者，previous 为修改前的价格，新建时为nil
+// 事件在价格写入成功后发布，记录失败只输出日志，不影响价格修改
+func Publish(eventType string, price models.Price, previous *models.Price, actor string) {
+	snapshot := models.PriceSnapshot(price)
+	event := models.PriceEvent{
+		Type:        eventType,
+		PriceID:     price.ID,
+		Model:       price.Model,
+		ChannelType: price.ChannelType,
+		Actor:       actor,
+		Price:       &snapshot,
+	}
+	if previous != nil {
+		before := models.PriceSnapshot(*previous)
+		event.Previous = &before
+	}
+
+	if database.DB == nil {
+		return
+	}
+	if err := database.DB.Create(&event).Error; err != nil {
+		log.Printf("记录价格变更事件失败 %s %s: %v", eventType, price.Model, err)
+		return
+	}
+
+	recent.notify()
+
+	mu.RLock()
+	defer mu.RUnlock()
+	for _, handler := range handlers {
+		handler(event)
+	}
+}
+
+// Filter 事件过滤条件，未设置的条件视为全部匹配
+type Filter struct {
+	Types        []string
+	ChannelTypes []uint
+	Models       []string // 支持 gpt-4* 前缀匹配
+}
+
+// Match 判断事件是否符合过滤条件
+func (f Filter) Match(event models.PriceEvent) bool {
+	if len(f.Types) > 0 && !contains(f.Types, event.Type) {
+		return false
+	}
+	if len(f.ChannelTypes) > 0 {
+		found := false
+		for _, channelType := range f.ChannelTypes {
+			if channelType == event.ChannelType {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	if len(f.Models) > 0 {
+		for _, pattern := range f.Models {
+			if prefix, ok := strings.CutSuffix(pattern, "*"); ok && strings.HasPrefix(event.Model, prefix) {
+				return true
+			}
+			if pattern == event.Model {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
+
+// Since 按ID顺序返回afterID之后的事件，最多limit条
+func Since(afterID uint, limit int) ([]models.PriceEvent, error) {
+	var list []models.PriceEvent
+	err := database.DB.Where("id > ?", afterID).Order("id").Limit(limit).Find(&list).Error
+	return list, err
+}
+
+// LatestID 返回最新事件的ID，没有事件时返回0
+func LatestID() (uint, error) {
+	var event models.PriceEvent
+	err := database.DB.Select("id").Order("id DESC").Limit(1).Find(&event).Error
+	return event.ID, err
+}
+
+// DefaultRetentionDays 默认保留事件的天数
+const DefaultRetentionDays = 90
+
+// RetentionDays 从环境变量 PRICE_EVENT_RETENTION_DAYS 读取事件保留天数，0 表示永久保留
+func RetentionDays() int {
+	value := os.Getenv("PRICE_EVENT_RETENTION_DAYS")
+	if value == "" {
+		return DefaultRetentionDays
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 0 {
+		log.Printf("无效的事件保留天数 PRICE_EVENT_RETENTION_DAYS=%s，使用默认值 %d", value, DefaultRetentionDays)
+		return DefaultRetentionDays
+	}
+	return n
+}
+
+// Prune 删除超过保留期的事件
+func Prune() error {
+	days := RetentionDays()
+	if days == 0 {
+		return nil
+	}
+	cutoff := time.Now().AddDate(0, 0, -days)
+	result := database.DB.Where("created_at < ?", cutoff).Delete(&models.PriceEvent{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected > 0 {
+		log.Printf("已清理 %d 条过期价格变更事件", result.RowsAffected)
+	}
+	return nil
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,155 @@
+package events
+
+import (
+	"log"
+	"sync"
+	"time"
+
+	"aimodels-prices/models"
+)
+
+const (
+	// TailSize 内存中保留的最近事件数，实时推送的客户端从中读取
+	TailSize = 1000
+	// TailPollInterval 轮询数据库的间隔，用于获取其他实例发布的事件，本实例发布时立即轮询
+	TailPollInterval = 5 * time.Second
+)
+
+// tail 每个实例一个，按ID顺序缓存最近的事件，所有实时推送的客户端共享，避免每个连接各自轮询数据库
+type tail struct {
+	mu      sync.Mutex
+	base    uint // ID大于base的事件都在events中
+	events  []models.PriceEvent
+	changed chan struct{} // 有新事件时关闭并替换，用于通知所有等待的客户端
+
+	once sync.Once
+	kick chan struct{}
+}
+
+var recent = newTail(0)
+
+func newTail(base uint) *tail {
+	return &tail{
+		base:    base,
+		changed: make(chan struct{}),
+		kick:    make(chan struct{}, 1),
+	}
+}
+
+// start 首次使用时启动轮询，从当前最新的事件开始缓存
+func (t *tail) start() {
+	t.once.Do(func() {
+		latest, err := LatestID()
+		if err != nil {
+			log.Printf("读取最新价格变更事件失败: %v", err)
+		}
+		t.mu.Lock()
+		t.base = latest
+		t.mu.Unlock()
+		go t.run()
+	})
+}
+
+func (t *tail) run() {
+	ticker := time.NewTicker(TailPollInterval)
+	defer ticker.Stop()
+	for {
+		t.poll()
+		select {
+		case <-ticker.C:
+		case <-t.kick:
+		}
+	}
+}
+
+// poll 读取缓存之后的新事件
+func (t *tail) poll() {
+	for {
+		t.mu.Lock()
+		lastID := t.base
+		if n := len(t.events); n > 0 {
+			lastID = t.events[n-1].ID
+		}
+		t.mu.Unlock()
+
+		list, err := Since(lastID, TailSize)
+		if err != nil {
+			log.Printf("读取价格变更事件失败: %v", err)
+			return
+		}
+		t.append(list)
+		if len(list) < TailSize {
+			return
+		}
+	}
+}
+
+// append 追加新事件，超过容量时丢弃最早的事件，并通知等待的客户端
+func (t *tail) append(list []models.PriceEvent) {
+	if len(list) == 0 {
+		return
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.events = append(t.events, list...)
+	if drop := len(t.events) - TailSize; drop > 0 {
+		t.base = t.events[drop-1].ID
+		t.events = append([]models.PriceEvent(nil), t.events[drop:]...)
+	}
+	close(t.changed)
+	t.changed = make(chan struct{})
+}
+
+// after 返回缓存中afterID之后的事件，最多limit条；afterID早于缓存范围时ok为false
+// changed 在之后有新事件时关闭
+func (t *tail) after(afterID uint, limit int) (list []models.PriceEvent, changed <-chan struct{}, ok bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if afterID < t.base {
+		return nil, nil, false
+	}
+	for _, event := range t.events {
+		if event.ID <= afterID {
+			continue
+		}
+		if len(list) >= limit {
+			break
+		}
+		list = append(list, event)
+	}
+	return list, t.changed, true
+}
+
+// notify 本实例发布事件后立即轮询
+func (t *tail) notify() {
+	select {
+	case t.kick <- struct{}{}:
+	default:
+	}
+}
+
+// Next 返回afterID之后的事件，最多limit条，用于实时推送
+// 最近的事件从本实例共享的缓存读取，断线重连时较早的事件从数据库读取；
+// 没有新事件时返回的changed在有新事件时关闭，从数据库读取时为nil，应立即再次调用
+func Next(afterID uint, limit int) ([]models.PriceEvent, <-chan struct{}, error) {
+	recent.start()
+
+	if list, changed, ok := recent.after(afterID, limit); ok {
+		return list, changed, nil
+	}
+
+	list, err := Since(afterID, limit)
+	if err != nil || len(list) > 0 {
+		return list, nil, err
+	}
+
+	// 缓存之前的事件已被清理，从缓存开始处继续
+	recent.mu.Lock()
+	base := recent.base
+	recent.mu.Unlock()
+	list, changed, _ := recent.after(base, limit)
+	return list, changed, nil
+}
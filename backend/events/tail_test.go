@@ -0,0 +1,49 @@
+package events
+
+import (
+	"testing"
+
+	"aimodels-prices/models"
+)
+
+func eventRange(from, to uint) []models.PriceEvent {
+	var list []models.PriceEvent
+	for id := from; id <= to; id++ {
+		list = append(list, models.PriceEvent{ID: id})
+	}
+	return list
+}
+
+func TestTail(t *testing.T) {
+	tl := newTail(10)
+
+	list, changed, ok := tl.after(10, 100)
+	if !ok || len(list) != 0 {
+		t.Fatalf("空缓存应返回空列表: %v %v", list, ok)
+	}
+
+	tl.append(eventRange(11, 15))
+	select {
+	case <-changed:
+	default:
+		t.Fatal("追加事件后应通知等待的客户端")
+	}
+
+	list, _, ok = tl.after(12, 2)
+	if !ok || len(list) != 2 || list[0].ID != 13 || list[1].ID != 14 {
+		t.Errorf("读取缓存错误: %v", list)
+	}
+
+	// 超过容量后丢弃最早的事件，早于缓存范围的需从数据库读取
+	tl.append(eventRange(16, 15+TailSize))
+	if _, _, ok := tl.after(12, 10); ok {
+		t.Error("早于缓存范围时应返回 ok=false")
+	}
+	list, _, ok = tl.after(tl.base, 1)
+	if !ok || len(list) != 1 || list[0].ID != tl.base+1 {
+		t.Errorf("缓存开始处读取错误: base=%d %v", tl.base, list)
+	}
+	if len(tl.events) != TailSize {
+		t.Errorf("缓存大小错误: %d", len(tl.events))
+	}
+}
@@ -0,0 +1,263 @@
+package feeds
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"html"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"aimodels-prices/models"
+	"aimodels-prices/seo"
+)
+
+// MaxEntries 订阅源最多包含的条目数
+const MaxEntries = 50
+
+// idPrefix 条目ID前缀，使用tag URI保证地址变化后ID不变
+const idPrefix = "tag:ai-prices.sunai.net,2025:"
+
+type atomFeed struct {
+	XMLName xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	ID      string      `xml:"id"`
+	Title   string      `xml:"title"`
+	Updated string      `xml:"updated"`
+	Links   []atomLink  `xml:"link"`
+	Author  atomAuthor  `xml:"author"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+type atomLink struct {
+	Rel  string `xml:"rel,attr,omitempty"`
+	Type string `xml:"type,attr,omitempty"`
+	Href string `xml:"href,attr"`
+}
+
+type atomAuthor struct {
+	Name string `xml:"name"`
+}
+
+type atomCategory struct {
+	Term string `xml:"term,attr"`
+}
+
+type atomContent struct {
+	Type string `xml:"type,attr"`
+	Body string `xml:",chardata"`
+}
+
+type atomEntry struct {
+	ID        string        `xml:"id"`
+	Title     string        `xml:"title"`
+	Updated   string        `xml:"updated"`
+	Published string        `xml:"published"`
+	Links     []atomLink    `xml:"link"`
+	Author    atomAuthor    `xml:"author"`
+	Category  *atomCategory `xml:"category"`
+	Content   atomContent   `xml:"content"`
+}
+
+// Feed 订阅源的基本信息
+type Feed struct {
+	ID    string // 附加在idPrefix之后
+	Title string
+	Path  string // 订阅源自身的路径
+}
+
+// change 单个字段修改前后的值，新增模型时Before为空
+type change struct {
+	Label  string
+	Before string
+	After  string
+}
+
+// priceFields 订阅源中展示的价格字段
+var priceFields = []struct {
+	Label string
+	Value func(p *models.PriceSnapshot) string
+}{
+	{"模型", func(p *models.PriceSnapshot) string { return p.Model }},
+	{"计费类型", func(p *models.PriceSnapshot) string { return p.BillingType }},
+	{"计费单位", func(p *models.PriceSnapshot) string { return p.BillingUnit }},
+	{"币种", func(p *models.PriceSnapshot) string { return p.Currency }},
+	{"输入价格", func(p *models.PriceSnapshot) string { return formatFloat(&p.InputPrice) }},
+	{"输出价格", func(p *models.PriceSnapshot) string { return formatFloat(&p.OutputPrice) }},
+	{"音频输入", func(p *models.PriceSnapshot) string { return formatFloat(p.InputAudioTokens) }},
+	{"音频输出", func(p *models.PriceSnapshot) string { return formatFloat(p.OutputAudioTokens) }},
+	{"缓存", func(p *models.PriceSnapshot) string { return formatFloat(p.CachedTokens) }},
+	{"缓存读取", func(p *models.PriceSnapshot) string { return formatFloat(p.CachedReadTokens) }},
+	{"缓存写入", func(p *models.PriceSnapshot) string { return formatFloat(p.CachedWriteTokens) }},
+	{"推理", func(p *models.PriceSnapshot) string { return formatFloat(p.ReasoningTokens) }},
+	{"输入文本", func(p *models.PriceSnapshot) string { return formatFloat(p.InputTextTokens) }},
+	{"输出文本", func(p *models.PriceSnapshot) string { return formatFloat(p.OutputTextTokens) }},
+	{"输入图片", func(p *models.PriceSnapshot) string { return formatFloat(p.InputImageTokens) }},
+	{"输出图片", func(p *models.PriceSnapshot) string { return formatFloat(p.OutputImageTokens) }},
+	{"每次请求", func(p *models.PriceSnapshot) string { return formatFloat(p.RequestPrice) }},
+	{"每张图片", func(p *models.PriceSnapshot) string { return formatFloat(p.ImagePrice) }},
+	{"阶梯价格", func(p *models.PriceSnapshot) string { return formatJSON(len(p.Tiers), p.Tiers) }},
+	{"价格调整", func(p *models.PriceSnapshot) string { return formatJSON(len(p.Modifiers), p.Modifiers) }},
+	{"来源", func(p *models.PriceSnapshot) string { return p.PriceSource }},
+}
+
+// Build 根据价格变更事件生成Atom订阅源，events 按ID倒序，providers 为厂商ID到名称的映射
+// 只包含已生效的变更，没有字段变化的事件会被跳过
+func Build(feed Feed, events []models.PriceEvent, providers map[uint]string, now time.Time) ([]byte, time.Time, error) {
+	out := atomFeed{
+		ID:    idPrefix + feed.ID,
+		Title: feed.Title,
+		Links: []atomLink{
+			{Rel: "self", Type: "application/atom+xml", Href: seo.BaseURL + feed.Path},
+			{Rel: "alternate", Type: "text/html", Href: seo.BaseURL + "/prices"},
+		},
+		Author: atomAuthor{Name: "AI模型价格汇总"},
+	}
+
+	// 没有条目时使用生成时间
+	updated := now
+	for _, event := range events {
+		if len(out.Entries) >= MaxEntries {
+			break
+		}
+		entry, ok := buildEntry(event, providers)
+		if !ok {
+			continue
+		}
+		if len(out.Entries) == 0 {
+			updated = event.CreatedAt
+		}
+		out.Entries = append(out.Entries, entry)
+	}
+	out.Updated = formatTime(updated)
+
+	data, err := xml.MarshalIndent(out, "", "  ")
+	if err != nil {
+		return nil, updated, err
+	}
+	return append([]byte(xml.Header), data...), updated, nil
+}
+
+// buildEntry 生成单个条目，不是已生效的价格变更时返回false
+func buildEntry(event models.PriceEvent, providers map[uint]string) (atomEntry, bool) {
+	if event.Price == nil {
+		return atomEntry{}, false
+	}
+
+	var previous *models.PriceSnapshot
+	switch event.Type {
+	case models.PriceEventCreated:
+		// 普通用户新建的价格需审核通过后才生效，由approved事件展示
+		if event.Price.Status != "approved" {
+			return atomEntry{}, false
+		}
+	case models.PriceEventUpdated:
+		previous = event.Previous
+	case models.PriceEventApproved:
+		// 新模型审核通过时没有待审核的临时字段，修改前的值就是提交的值，按新增展示
+		if event.Previous != nil && !(event.Previous.Status == "pending" && event.Previous.TempModel == nil) {
+			previous = event.Previous
+		}
+	default:
+		return atomEntry{}, false
+	}
+
+	changes := diff(previous, event.Price)
+	if len(changes) == 0 {
+		return atomEntry{}, false
+	}
+
+	provider := providers[event.Price.ChannelType]
+	if provider == "" {
+		provider = strconv.FormatUint(uint64(event.Price.ChannelType), 10)
+	}
+	action := "价格更新"
+	if previous == nil {
+		action = "新增"
+	}
+
+	link := seo.BaseURL + "/prices"
+	if isHTTPURL(event.Price.PriceSource) {
+		link = event.Price.PriceSource
+	}
+
+	timestamp := formatTime(event.CreatedAt)
+	return atomEntry{
+		ID:        idPrefix + "price-event/" + strconv.FormatUint(uint64(event.ID), 10),
+		Title:     fmt.Sprintf("[%s] %s %s", provider, event.Price.Model, action),
+		Updated:   timestamp,
+		Published: timestamp,
+		Links:     []atomLink{{Rel: "alternate", Href: link}},
+		Author:    atomAuthor{Name: event.Actor},
+		Category:  &atomCategory{Term: provider},
+		Content:   atomContent{Type: "html", Body: renderChanges(changes, event.Price.PriceSource)},
+	}, true
+}
+
+// diff 比较修改前后的价格字段，previous 为nil时列出所有有值的字段
+func diff(previous, current *models.PriceSnapshot) []change {
+	var changes []change
+	for _, field := range priceFields {
+		after := field.Value(current)
+		if previous == nil {
+			if after != "" {
+				changes = append(changes, change{Label: field.Label, After: after})
+			}
+			continue
+		}
+		if before := field.Value(previous); before != after {
+			changes = append(changes, change{Label: field.Label, Before: before, After: after})
+		}
+	}
+	return changes
+}
+
+// renderChanges 以HTML表格展示字段变化
+func renderChanges(changes []change, source string) string {
+	var b strings.Builder
+	b.WriteString("<table><tr><th>项目</th><th>修改前</th><th>修改后</th></tr>")
+	for _, c := range changes {
+		fmt.Fprintf(&b, "<tr><td>%s</td><td>%s</td><td>%s</td></tr>",
+			html.EscapeString(c.Label), html.EscapeString(orDash(c.Before)), html.EscapeString(orDash(c.After)))
+	}
+	b.WriteString("</table>")
+	if isHTTPURL(source) {
+		fmt.Fprintf(&b, `<p>来源：<a href="%s">%s</a></p>`, html.EscapeString(source), html.EscapeString(source))
+	}
+	return b.String()
+}
+
+func formatFloat(v *float64) string {
+	if v == nil {
+		return ""
+	}
+	return strconv.FormatFloat(*v, 'f', -1, 64)
+}
+
+func formatJSON(size int, v interface{}) string {
+	if size == 0 {
+		return ""
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return ""
+	}
+	return string(data)
+}
+
+func formatTime(t time.Time) string {
+	return t.UTC().Format(time.RFC3339)
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+func isHTTPURL(raw string) bool {
+	u, err := url.Parse(raw)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
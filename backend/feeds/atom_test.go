@@ -0,0 +1,94 @@
+package feeds
+
+import (
+	"encoding/xml"
+	"strings"
+	"testing"
+	"time"
+
+	"aimodels-prices/models"
+)
+
+func snapshot(status string, input, output float64) *models.PriceSnapshot {
+	return &models.PriceSnapshot{
+		Model:       "gpt-4o",
+		BillingType: "tokens",
+		ChannelType: 1,
+		Currency:    "USD",
+		InputPrice:  input,
+		OutputPrice: output,
+		PriceSource: "https://openai.com/api/pricing/",
+		Status:      status,
+	}
+}
+
+func TestBuild(t *testing.T) {
+	base := time.Date(2025, 6, 1, 8, 0, 0, 0, time.UTC)
+	pendingModel := "gpt-4o"
+	events := []models.PriceEvent{
+		// 按ID倒序
+		{ID: 5, Type: models.PriceEventUpdated, Actor: "admin", Price: snapshot("approved", 2.5, 10), Previous: snapshot("approved", 2.5, 10), CreatedAt: base.Add(4 * time.Hour)},
+		{ID: 4, Type: models.PriceEventCreated, Actor: "user", Price: snapshot("pending", 1, 2), CreatedAt: base.Add(3 * time.Hour)},
+		{ID: 3, Type: models.PriceEventApproved, Actor: "admin", Price: snapshot("approved", 2.5, 10), Previous: &models.PriceSnapshot{Model: "gpt-4o", ChannelType: 1, Currency: "USD", InputPrice: 5, OutputPrice: 15, Status: "pending", TempModel: &pendingModel, PriceSource: "https://openai.com/api/pricing/"}, CreatedAt: base.Add(2 * time.Hour)},
+		{ID: 2, Type: models.PriceEventSubmitted, Actor: "user", Price: snapshot("pending", 5, 15), CreatedAt: base.Add(time.Hour)},
+		{ID: 1, Type: models.PriceEventCreated, Actor: "cron自动任务", Price: snapshot("approved", 5, 15), CreatedAt: base},
+	}
+
+	data, updated, err := Build(Feed{ID: "changes", Title: "AI模型价格变更", Path: "/feeds/changes.atom"}, events, map[uint]string{1: "OpenAI"}, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !updated.Equal(base.Add(2 * time.Hour)) {
+		t.Errorf("updated = %v, want newest shown entry", updated)
+	}
+
+	var feed atomFeed
+	if err := xml.Unmarshal(data, &feed); err != nil {
+		t.Fatalf("invalid feed: %v", err)
+	}
+	if feed.Updated != "2025-06-01T10:00:00Z" {
+		t.Errorf("feed updated = %s", feed.Updated)
+	}
+	if len(feed.Entries) != 2 {
+		t.Fatalf("got %d entries, want 2", len(feed.Entries))
+	}
+
+	update := feed.Entries[0]
+	if update.ID != idPrefix+"price-event/3" || update.Title != "[OpenAI] gpt-4o 价格更新" {
+		t.Errorf("unexpected entry: %s %s", update.ID, update.Title)
+	}
+	if update.Links[0].Href != "https://openai.com/api/pricing/" {
+		t.Errorf("link = %s", update.Links[0].Href)
+	}
+	if !strings.Contains(update.Content.Body, "<td>输入价格</td><td>5</td><td>2.5</td>") {
+		t.Errorf("missing input price change: %s", update.Content.Body)
+	}
+	if strings.Contains(update.Content.Body, "币种") {
+		t.Errorf("unchanged fields should be omitted: %s", update.Content.Body)
+	}
+
+	created := feed.Entries[1]
+	if created.Title != "[OpenAI] gpt-4o 新增" || created.Author.Name != "cron自动任务" {
+		t.Errorf("unexpected entry: %s by %s", created.Title, created.Author.Name)
+	}
+	if !strings.Contains(created.Content.Body, "<td>输出价格</td><td>-</td><td>15</td>") {
+		t.Errorf("missing output price: %s", created.Content.Body)
+	}
+}
+
+func TestBuildNewModelApproved(t *testing.T) {
+	event := models.PriceEvent{
+		ID:        7,
+		Type:      models.PriceEventApproved,
+		Price:     snapshot("approved", 1, 2),
+		Previous:  snapshot("pending", 1, 2),
+		CreatedAt: time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC),
+	}
+	entry, ok := buildEntry(event, nil)
+	if !ok {
+		t.Fatal("approved new model should be shown")
+	}
+	if entry.Title != "[1] gpt-4o 新增" {
+		t.Errorf("title = %s", entry.Title)
+	}
+}
@@ -0,0 +1,115 @@
+package feeds
+
+import (
+	"bytes"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"aimodels-prices/database"
+	"aimodels-prices/models"
+)
+
+// cacheTTL 订阅源缓存时间，同时作为客户端的缓存时间
+const cacheTTL = 10 * time.Minute
+
+// scanLimit 每次生成时读取的事件数量，部分事件不展示，需多读一些才能凑满条目
+const scanLimit = 4 * MaxEntries
+
+// feedTypes 订阅源展示的事件类型，只包含已生效的变更
+var feedTypes = []string{
+	models.PriceEventCreated,
+	models.PriceEventUpdated,
+	models.PriceEventApproved,
+}
+
+type cachedFeed struct {
+	Data    []byte
+	Updated time.Time
+}
+
+// Changes 所有厂商已生效价格变更的Atom订阅源
+func Changes(c *gin.Context) {
+	serve(c, "feeds:changes", func() (cachedFeed, error) {
+		return generate(Feed{
+			ID:    "changes",
+			Title: "AI模型价格变更",
+			Path:  "/feeds/changes.atom",
+		}, 0)
+	})
+}
+
+// ProviderChanges 单个厂商已生效价格变更的Atom订阅源
+func ProviderChanges(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Provider not found"})
+		return
+	}
+
+	var provider models.Provider
+	if err := database.DB.First(&provider, id).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Provider not found"})
+		return
+	}
+
+	serve(c, "feeds:changes:"+c.Param("id"), func() (cachedFeed, error) {
+		return generate(Feed{
+			ID:    "changes/provider/" + c.Param("id"),
+			Title: provider.Name + " 模型价格变更",
+			Path:  "/feeds/providers/" + c.Param("id") + "/changes.atom",
+		}, provider.ID)
+	})
+}
+
+// serve 输出缓存的订阅源，缓存过期后重新生成，支持 If-Modified-Since
+func serve(c *gin.Context, cacheKey string, build func() (cachedFeed, error)) {
+	var feed cachedFeed
+	if cached, found := database.GlobalCache.Get(cacheKey); found {
+		feed, _ = cached.(cachedFeed)
+	}
+	if feed.Data == nil {
+		var err error
+		if feed, err = build(); err != nil {
+			log.Printf("生成价格变更订阅源失败: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate feed"})
+			return
+		}
+		database.GlobalCache.Set(cacheKey, feed, cacheTTL)
+	}
+
+	c.Header("Content-Type", "application/atom+xml; charset=utf-8")
+	c.Header("Cache-Control", "public, max-age="+strconv.Itoa(int(cacheTTL.Seconds())))
+	http.ServeContent(c.Writer, c.Request, "", feed.Updated, bytes.NewReader(feed.Data))
+}
+
+// generate 读取最近的价格变更事件生成订阅源，channelType 为0时包含所有厂商
+func generate(feed Feed, channelType uint) (cachedFeed, error) {
+	query := database.DB.Where("type IN ?", feedTypes)
+	if channelType != 0 {
+		query = query.Where("channel_type = ?", channelType)
+	}
+	var list []models.PriceEvent
+	if err := query.Order("id DESC").Limit(scanLimit).Find(&list).Error; err != nil {
+		return cachedFeed{}, err
+	}
+
+	// 已删除的厂商仍需显示名称
+	var providers []models.Provider
+	if err := database.DB.Unscoped().Find(&providers).Error; err != nil {
+		return cachedFeed{}, err
+	}
+	names := make(map[uint]string, len(providers))
+	for _, provider := range providers {
+		names[provider.ID] = provider.Name
+	}
+
+	data, updated, err := Build(feed, list, names, time.Now())
+	if err != nil {
+		return cachedFeed{}, err
+	}
+	return cachedFeed{Data: data, Updated: updated}, nil
+}
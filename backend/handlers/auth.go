@@ -5,17 +5,43 @@ import (
 	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"log"
 	"net/http"
 	"net/url"
 	"os"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/gin-gonic/gin"
 
 	"aimodels-prices/database"
+	"aimodels-prices/httpclient"
 	"aimodels-prices/models"
 )
 
+var (
+	tokenClientOnce sync.Once
+	tokenClient     *httpclient.Client
+)
+
+// oauthTokenClient 换取访问令牌使用的客户端，配置与 oauth 相同但不重试
+// 授权码只能使用一次，服务端已处理后重试会返回 invalid_grant 并掩盖原始错误
+func oauthTokenClient() *httpclient.Client {
+	tokenClientOnce.Do(func() {
+		cfg := httpclient.LoadConfig("oauth")
+		cfg.MaxRetries = 0
+		c, err := httpclient.New("oauth-token", cfg)
+		if err != nil {
+			log.Printf("OAuth HTTP客户端配置错误，忽略代理设置: %v", err)
+			cfg.Proxy = ""
+			c, _ = httpclient.New("oauth-token", cfg)
+		}
+		tokenClient = c
+	})
+	return tokenClient
+}
+
 // generateSessionID 生成随机会话ID
 func generateSessionID() string {
 	b := make([]byte, 32)
@@ -126,7 +152,13 @@ func AuthCallback(c *gin.Context) {
 	data.Set("grant_type", "authorization_code")
 
 	// 发送请求获取访问令牌
-	resp, err := http.PostForm(tokenURL, data)
+	tokenReq, err := http.NewRequestWithContext(c.Request.Context(), "POST", tokenURL, strings.NewReader(data.Encode()))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create token request"})
+		return
+	}
+	tokenReq.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	resp, err := oauthTokenClient().Do(tokenReq)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get access token"})
 		return
@@ -146,15 +178,14 @@ func AuthCallback(c *gin.Context) {
 
 	// 使用访问令牌获取用户信息
 	userURL := "https://connect.czl.net/api/oauth2/userinfo" // 固定用户信息URL
-	req, err := http.NewRequest("GET", userURL, nil)
+	req, err := http.NewRequestWithContext(c.Request.Context(), "GET", userURL, nil)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create user info request"})
 		return
 	}
 
 	req.Header.Set("Authorization", "Bearer "+tokenResp.AccessToken)
-	client := &http.Client{}
-	userResp, err := client.Do(req)
+	userResp, err := httpclient.For("oauth").Do(req)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get user info"})
 		return
@@ -241,3 +272,11 @@ func AuthCallback(c *gin.Context) {
 	// 重定向到前端
 	c.Redirect(http.StatusTemporaryRedirect, "https://ai-prices.sunai.net")
 }
+
+// currentUsername 返回当前登录用户的用户名
+func currentUsername(c *gin.Context) string {
+	if user, exists := c.Get("user"); exists {
+		return user.(*models.User).Username
+	}
+	return ""
+}
@@ -0,0 +1,149 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"aimodels-prices/database"
+	"aimodels-prices/models"
+	"aimodels-prices/pricing"
+)
+
+// CalculateRequest 费用计算请求
+type CalculateRequest struct {
+	Model       string        `json:"model" binding:"required"`
+	ChannelType *uint         `json:"channel_type"`
+	Currency    string        `json:"currency" binding:"omitempty,oneof=USD CNY"`
+	Usage       pricing.Usage `json:"usage"`
+}
+
+// CalculateResult 单个厂商的费用计算结果
+type CalculateResult struct {
+	ChannelType  uint               `json:"channel_type"`
+	ProviderName string             `json:"provider_name"`
+	Model        string             `json:"model"`
+	BillingType  string             `json:"billing_type"`
+	Total        float64            `json:"total"`
+	Breakdown    map[string]float64 `json:"breakdown"`
+	AppliedTier  *int64             `json:"applied_tier,omitempty"`
+	Modifiers    []string           `json:"modifiers,omitempty"`
+}
+
+// CalculateCost 根据用量计算各厂商提供该模型的费用
+func CalculateCost(c *gin.Context) {
+	var input CalculateRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	currency := input.Currency
+	if currency == "" {
+		currency = "USD"
+	}
+
+	at, mode, err := parseEvalOptions(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	query := database.DB.Where("model = ? AND status = 'approved'", input.Model)
+	if input.ChannelType != nil {
+		query = query.Where("channel_type = ?", *input.ChannelType)
+	}
+
+	var prices []models.Price
+	if err := query.Find(&prices).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch prices"})
+		return
+	}
+
+	if len(prices) == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "No approved price found for this model"})
+		return
+	}
+
+	providerNames := getProviderNames()
+
+	results := make([]CalculateResult, 0, len(prices))
+	for _, price := range prices {
+		cost := pricing.Calculate(price, input.Usage, pricing.Options{Currency: currency, At: at, Mode: mode})
+		results = append(results, CalculateResult{
+			ChannelType:  price.ChannelType,
+			ProviderName: providerNames[price.ChannelType],
+			Model:        price.Model,
+			BillingType:  price.BillingType,
+			Total:        cost.Total,
+			Breakdown:    cost.Breakdown,
+			AppliedTier:  cost.AppliedTier,
+			Modifiers:    cost.Modifiers,
+		})
+	}
+
+	// 按总费用从低到高排序
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].Total < results[j].Total
+	})
+
+	result := gin.H{
+		"model":    input.Model,
+		"currency": currency,
+		"usage":    input.Usage,
+		"results":  results,
+	}
+	if !at.IsZero() {
+		result["at"] = at
+	}
+	if mode != "" {
+		result["mode"] = mode
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// parseEvalOptions 解析计算时间(at)和调用模式(mode)查询参数
+// at支持RFC3339格式或Unix时间戳，为空时不应用时间段价格调整
+func parseEvalOptions(c *gin.Context) (time.Time, string, error) {
+	var at time.Time
+	if v := c.Query("at"); v != "" {
+		if ts, err := strconv.ParseInt(v, 10, 64); err == nil {
+			at = time.Unix(ts, 0).UTC()
+		} else if t, err := time.Parse(time.RFC3339, v); err == nil {
+			at = t
+		} else {
+			return at, "", errors.New("Invalid at, expected RFC3339 or unix timestamp")
+		}
+	}
+
+	mode := c.Query("mode")
+	switch mode {
+	case "", models.ModeRealtime, models.ModeBatch, models.ModeFlex, models.ModePriority:
+	default:
+		return at, "", errors.New("mode must be realtime, batch, flex or priority")
+	}
+
+	return at, mode, nil
+}
+
+// getProviderNames 获取厂商ID到名称的映射，优先使用缓存
+func getProviderNames() map[uint]string {
+	var providers []models.Provider
+	if cachedData, found := database.GlobalCache.Get("providers"); found {
+		providers, _ = cachedData.([]models.Provider)
+	}
+	if providers == nil {
+		database.DB.Order("id").Find(&providers)
+	}
+
+	names := make(map[uint]string, len(providers))
+	for _, p := range providers {
+		names[p.ID] = p.Name
+	}
+	return names
+}
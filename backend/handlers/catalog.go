@@ -0,0 +1,211 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"aimodels-prices/catalog"
+	"aimodels-prices/database"
+	"aimodels-prices/models"
+)
+
+// GetCatalogModels 获取模型目录
+func GetCatalogModels(c *gin.Context) {
+	query := database.DB.Preload("Aliases").Order("id")
+	if vendor := c.Query("vendor"); vendor != "" {
+		query = query.Where("vendor = ?", vendor)
+	}
+	if family := c.Query("family"); family != "" {
+		query = query.Where("family = ?", family)
+	}
+
+	var catalogModels []models.Model
+	if err := query.Find(&catalogModels).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch models"})
+		return
+	}
+
+	c.JSON(http.StatusOK, catalogModels)
+}
+
+// GetCatalogModel 按规范ID或别名获取模型
+func GetCatalogModel(c *gin.Context) {
+	canonicalID, found := catalog.Resolve(catalog.AnyChannel, c.Param("name"))
+	if !found {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Model not found"})
+		return
+	}
+
+	var model models.Model
+	if err := database.DB.Preload("Aliases").Where("id = ?", canonicalID).First(&model).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Model not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, model)
+}
+
+// CreateCatalogModel 创建规范模型
+func CreateCatalogModel(c *gin.Context) {
+	var model models.Model
+	if err := c.ShouldBindJSON(&model); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	model.ID = strings.ToLower(strings.TrimSpace(model.ID))
+	if model.ID == "" || model.Vendor == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "id and vendor are required"})
+		return
+	}
+
+	// 检查ID是否已存在
+	var count int64
+	if err := database.DB.Model(&models.Model{}).Where("id = ?", model.ID).Count(&count).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check model existence"})
+		return
+	}
+	if count > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Model ID already exists"})
+		return
+	}
+
+	currentUser := c.MustGet("user").(*models.User)
+	model.CreatedBy = currentUser.Username
+	for i := range model.Aliases {
+		model.Aliases[i].ModelID = model.ID
+		model.Aliases[i].CreatedBy = currentUser.Username
+	}
+
+	if err := database.DB.Create(&model).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create model"})
+		return
+	}
+
+	clearPriceCache()
+
+	c.JSON(http.StatusCreated, model)
+}
+
+// UpdateCatalogModel 更新规范模型的基础信息
+func UpdateCatalogModel(c *gin.Context) {
+	var input models.Model
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var model models.Model
+	if err := database.DB.Where("id = ?", c.Param("name")).First(&model).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Model not found"})
+		return
+	}
+
+	model.DisplayName = input.DisplayName
+	model.Family = input.Family
+	if input.Vendor != "" {
+		model.Vendor = input.Vendor
+	}
+	model.VendorChannel = input.VendorChannel
+
+	if err := database.DB.Save(&model).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update model"})
+		return
+	}
+
+	clearPriceCache()
+
+	c.JSON(http.StatusOK, model)
+}
+
+// DeleteCatalogModel 删除规范模型及其别名
+func DeleteCatalogModel(c *gin.Context) {
+	var model models.Model
+	if err := database.DB.Where("id = ?", c.Param("name")).First(&model).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Model not found"})
+		return
+	}
+
+	tx := database.DB.Begin()
+	if err := tx.Where("model_id = ?", model.ID).Delete(&models.ModelAlias{}).Error; err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete model aliases"})
+		return
+	}
+	if err := tx.Delete(&model).Error; err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete model"})
+		return
+	}
+	if err := tx.Commit().Error; err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to commit transaction"})
+		return
+	}
+
+	clearPriceCache()
+
+	c.JSON(http.StatusOK, gin.H{"message": "Model deleted successfully"})
+}
+
+// CreateModelAlias 为规范模型添加厂商别名
+func CreateModelAlias(c *gin.Context) {
+	var alias models.ModelAlias
+	if err := c.ShouldBindJSON(&alias); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	alias.Alias = strings.TrimSpace(alias.Alias)
+	if alias.Alias == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "alias is required"})
+		return
+	}
+
+	var model models.Model
+	if err := database.DB.Where("id = ?", c.Param("name")).First(&model).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Model not found"})
+		return
+	}
+
+	// 同一厂商下的别名只能指向一个模型
+	var existing models.ModelAlias
+	if err := database.DB.Where("channel_type = ? AND alias = ?", alias.ChannelType, alias.Alias).First(&existing).Error; err == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Alias already exists for model " + existing.ModelID})
+		return
+	}
+
+	currentUser := c.MustGet("user").(*models.User)
+	alias.ID = 0
+	alias.ModelID = model.ID
+	alias.CreatedBy = currentUser.Username
+
+	if err := database.DB.Create(&alias).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create alias"})
+		return
+	}
+
+	clearPriceCache()
+
+	c.JSON(http.StatusCreated, alias)
+}
+
+// DeleteModelAlias 删除模型别名
+func DeleteModelAlias(c *gin.Context) {
+	var alias models.ModelAlias
+	if err := database.DB.Where("id = ? AND model_id = ?", c.Param("alias_id"), c.Param("name")).First(&alias).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Alias not found"})
+		return
+	}
+
+	if err := database.DB.Delete(&alias).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete alias"})
+		return
+	}
+
+	clearPriceCache()
+
+	c.JSON(http.StatusOK, gin.H{"message": "Alias deleted successfully"})
+}
@@ -0,0 +1,192 @@
+package handlers
+
+import (
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"aimodels-prices/catalog"
+	"aimodels-prices/database"
+	"aimodels-prices/models"
+	"aimodels-prices/pricing"
+)
+
+// CompareRow 模型在单个厂商下的对比结果
+type CompareRow struct {
+	ChannelType     uint     `json:"channel_type"`
+	ProviderName    string   `json:"provider_name"`
+	Model           string   `json:"model"`
+	InputPrice      float64  `json:"input_price"`
+	OutputPrice     float64  `json:"output_price"`
+	BlendedPrice    float64  `json:"blended_price"`
+	IsOfficial      bool     `json:"is_official"`
+	DeltaToOfficial *float64 `json:"delta_to_official,omitempty"`
+	DeltaPercent    *float64 `json:"delta_percent,omitempty"`
+}
+
+// CompareModelPrices 对比同一模型在各厂商下的已审核价格
+// 价格统一换算为同一货币、每百万token，并按给定输入输出比例的混合价格从低到高排序
+// 模型名称从路径参数读取，包含/的名称（如 vendor/model）通过查询参数 model 传入
+func CompareModelPrices(c *gin.Context) {
+	name := c.Param("name")
+	if model := c.Query("model"); model != "" {
+		name = model
+	}
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "model is required"})
+		return
+	}
+	currency := c.DefaultQuery("currency", "USD")
+	if currency != "USD" && currency != "CNY" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "currency must be USD or CNY"})
+		return
+	}
+
+	inputRatio, err1 := strconv.ParseFloat(c.DefaultQuery("input_ratio", "1"), 64)
+	outputRatio, err2 := strconv.ParseFloat(c.DefaultQuery("output_ratio", "1"), 64)
+	if err1 != nil || err2 != nil || inputRatio < 0 || outputRatio < 0 || inputRatio+outputRatio == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input_ratio or output_ratio"})
+		return
+	}
+
+	at, mode, err := parseEvalOptions(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// 通过模型目录解析规范模型及其在各厂商下的名称
+	canonicalID, inCatalog := catalog.Resolve(catalog.AnyChannel, name)
+	if !inCatalog {
+		canonicalID = name
+	}
+
+	// 不在目录中的模型同时匹配聚合平台 vendor/model 形式的名称
+	query := database.DB.Where("status = 'approved' AND billing_type = 'tokens'")
+	if inCatalog {
+		query = query.Where("model IN ?", catalog.Names(canonicalID))
+	} else {
+		query = query.Where("model IN ? OR LOWER(model) LIKE ?", catalog.Names(canonicalID), "%/"+escapeLike(name))
+	}
+	var candidates []models.Price
+	if err := query.Find(&candidates).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch prices"})
+		return
+	}
+
+	// 别名按厂商区分，过滤掉在该厂商下指向其他模型的同名记录
+	prices := make([]models.Price, 0, len(candidates))
+	for _, price := range candidates {
+		resolved, found := catalog.Resolve(price.ChannelType, price.Model)
+		if !found {
+			resolved = strings.ToLower(price.Model)
+		}
+		if resolved == canonicalID || (!inCatalog && !found && strings.HasSuffix(resolved, "/"+name)) {
+			prices = append(prices, price)
+		}
+	}
+
+	if len(prices) == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "No approved price found for this model"})
+		return
+	}
+
+	// 官方厂商可通过参数指定，否则使用模型目录中的官方厂商
+	var officialChannel uint
+	var hasOfficial bool
+	if m, found := catalog.Get(canonicalID); found && m.VendorChannel != nil {
+		officialChannel, hasOfficial = *m.VendorChannel, true
+	}
+	if v := c.Query("official_channel"); v != "" {
+		id, err := strconv.ParseUint(v, 10, 32)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid official_channel"})
+			return
+		}
+		officialChannel, hasOfficial = uint(id), true
+	}
+
+	// 按比例构造每百万token的用量
+	inputShare := inputRatio / (inputRatio + outputRatio)
+	usage := pricing.Usage{
+		InputTokens:  int64(inputShare * pricing.TokensPerUnit),
+		OutputTokens: pricing.TokensPerUnit - int64(inputShare*pricing.TokensPerUnit),
+	}
+
+	providerNames := getProviderNames()
+	opts := pricing.Options{Currency: currency, At: at, Mode: mode}
+
+	rows := make([]CompareRow, 0, len(prices))
+	var official *CompareRow
+	for _, price := range prices {
+		row := CompareRow{
+			ChannelType:  price.ChannelType,
+			ProviderName: providerNames[price.ChannelType],
+			Model:        price.Model,
+			InputPrice:   pricing.Calculate(price, pricing.Usage{InputTokens: pricing.TokensPerUnit}, opts).Total,
+			OutputPrice:  pricing.Calculate(price, pricing.Usage{OutputTokens: pricing.TokensPerUnit}, opts).Total,
+			BlendedPrice: pricing.Calculate(price, usage, opts).Total,
+			IsOfficial:   hasOfficial && price.ChannelType == officialChannel,
+		}
+		rows = append(rows, row)
+	}
+
+	for i := range rows {
+		if rows[i].IsOfficial {
+			official = &rows[i]
+			break
+		}
+	}
+
+	// 计算与官方价格的差值
+	if official != nil {
+		base := official.BlendedPrice
+		for i := range rows {
+			delta := roundCompare(rows[i].BlendedPrice - base)
+			rows[i].DeltaToOfficial = &delta
+			if base > 0 {
+				percent := roundCompare(delta / base * 100)
+				rows[i].DeltaPercent = &percent
+			}
+		}
+	}
+
+	sort.SliceStable(rows, func(i, j int) bool {
+		return rows[i].BlendedPrice < rows[j].BlendedPrice
+	})
+
+	result := gin.H{
+		"model":        canonicalID,
+		"currency":     currency,
+		"unit":         "1M tokens",
+		"input_ratio":  inputRatio,
+		"output_ratio": outputRatio,
+		"data":         rows,
+	}
+	if official != nil {
+		result["official_channel"] = officialChannel
+	}
+	if !at.IsZero() {
+		result["at"] = at
+	}
+	if mode != "" {
+		result["mode"] = mode
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// escapeLike 转义LIKE中的通配符，模型名称常包含_
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
+
+// roundCompare 四舍五入到6位小数
+func roundCompare(v float64) float64 {
+	return math.Round(v*1000000) / 1000000
+}
@@ -0,0 +1,200 @@
+package jobs
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"aimodels-prices/cron"
+	"aimodels-prices/cron/declarative"
+	openrouter_api "aimodels-prices/cron/openrouter-api"
+	"aimodels-prices/cron/scraper"
+	"aimodels-prices/database"
+	"aimodels-prices/models"
+)
+
+// sourceDefinitionInput 创建、修改和测试来源定义的请求参数
+type sourceDefinitionInput struct {
+	Name        string           `json:"name"`
+	Format      string           `json:"format"`
+	URL         string           `json:"url"`
+	Headers     models.StringMap `json:"headers"`
+	RowSelector string           `json:"row_selector"`
+	Columns     models.StringMap `json:"columns"`
+	Multipliers models.FloatMap  `json:"multipliers"`
+	ChannelType uint             `json:"channel_type"`
+	Currency    string           `json:"currency"`
+	BillingUnit string           `json:"billing_unit"`
+	Approval    string           `json:"approval"`
+	Schedule    string           `json:"schedule"`
+	Enabled     *bool            `json:"enabled"` // 未指定时默认启用
+}
+
+// apply 将请求参数写入来源定义，未指定的审核策略和币种使用默认值
+func (input sourceDefinitionInput) apply(def *models.SourceDefinition) {
+	def.Name = input.Name
+	def.Format = input.Format
+	def.URL = input.URL
+	def.Headers = input.Headers
+	def.RowSelector = input.RowSelector
+	def.Columns = input.Columns
+	def.Multipliers = input.Multipliers
+	def.ChannelType = input.ChannelType
+	def.Currency = input.Currency
+	def.BillingUnit = input.BillingUnit
+	def.Approval = input.Approval
+	def.Schedule = input.Schedule
+	def.Enabled = input.Enabled == nil || *input.Enabled
+
+	if def.Currency == "" {
+		def.Currency = "USD"
+	}
+	if def.Approval == "" {
+		def.Approval = scraper.ApproveKnown.String()
+	}
+}
+
+// GetSourceDefinitions 获取管理员配置的价格来源
+func GetSourceDefinitions(c *gin.Context) {
+	var defs []models.SourceDefinition
+	if err := database.DB.Order("name").Find(&defs).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch source definitions"})
+		return
+	}
+
+	c.JSON(http.StatusOK, defs)
+}
+
+// CreateSourceDefinition 创建价格来源，保存后按执行计划自动抓取
+func CreateSourceDefinition(c *gin.Context) {
+	var input sourceDefinitionInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	def := models.SourceDefinition{CreatedBy: definitionUsername(c)}
+	input.apply(&def)
+	if err := validateDefinition(def); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := database.DB.Create(&def).Error; err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			c.JSON(http.StatusConflict, gin.H{"error": "Source definition already exists"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create source definition"})
+		return
+	}
+
+	cron.SyncDefinitions()
+	c.JSON(http.StatusCreated, def)
+}
+
+// UpdateSourceDefinition 修改价格来源
+func UpdateSourceDefinition(c *gin.Context) {
+	var def models.SourceDefinition
+	if err := database.DB.First(&def, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Source definition not found"})
+		return
+	}
+
+	var input sourceDefinitionInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	input.apply(&def)
+	if err := validateDefinition(def); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	username := definitionUsername(c)
+	def.UpdatedBy = &username
+	if err := database.DB.Save(&def).Error; err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			c.JSON(http.StatusConflict, gin.H{"error": "Source definition already exists"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update source definition"})
+		return
+	}
+
+	cron.SyncDefinitions()
+	c.JSON(http.StatusOK, def)
+}
+
+// DeleteSourceDefinition 删除价格来源，已写入的价格保留
+func DeleteSourceDefinition(c *gin.Context) {
+	result := database.DB.Delete(&models.SourceDefinition{}, c.Param("id"))
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete source definition"})
+		return
+	}
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Source definition not found"})
+		return
+	}
+
+	cron.SyncDefinitions()
+	c.JSON(http.StatusOK, gin.H{"message": "Source definition deleted successfully"})
+}
+
+// TestSourceDefinition 按请求中的定义抓取并解析页面，返回解析出的行和价格，不保存定义也不写入价格
+func TestSourceDefinition(c *gin.Context) {
+	var input sourceDefinitionInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var def models.SourceDefinition
+	input.apply(&def)
+	if err := declarative.Validate(def); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	result, err := declarative.Test(c.Request.Context(), def)
+	if err != nil {
+		c.JSON(http.StatusBadGateway, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// validateDefinition 校验来源定义，名称不能与内置来源重复，厂商必须存在
+func validateDefinition(def models.SourceDefinition) error {
+	if err := declarative.Validate(def); err != nil {
+		return err
+	}
+
+	cron.RegisterSources()
+	if _, ok := scraper.Get(def.Name); ok || def.Name == openrouter_api.ProviderJobName {
+		return errors.New("Name conflicts with a built-in source")
+	}
+
+	var count int64
+	if err := database.DB.Model(&models.Provider{}).Where("id = ?", def.ChannelType).Count(&count).Error; err != nil {
+		return err
+	}
+	if count == 0 {
+		return errors.New("Provider not found")
+	}
+	return nil
+}
+
+// definitionUsername 返回当前管理员的用户名
+func definitionUsername(c *gin.Context) string {
+	if user, exists := c.Get("user"); exists {
+		return user.(*models.User).Username
+	}
+	return ""
+}
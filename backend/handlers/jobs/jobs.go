@@ -0,0 +1,181 @@
+package jobs
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"aimodels-prices/cron/declarative"
+	"aimodels-prices/cron/scraper"
+	"aimodels-prices/database"
+	"aimodels-prices/models"
+)
+
+// JobInfo 价格抓取任务概况
+type JobInfo struct {
+	Name        string                 `json:"name"`
+	ChannelType uint                   `json:"channel_type"`
+	Approval    scraper.ApprovalPolicy `json:"approval"`
+	Schedule    string                 `json:"schedule"` // 为空表示已禁用定时执行
+	Running     bool                   `json:"running"`
+	LastRun     *models.ScrapeRun      `json:"last_run"`
+}
+
+// GetJobs 获取所有价格抓取任务及其最近一次执行记录，包括管理员配置的来源
+func GetJobs(c *gin.Context) {
+	schedules := scraper.Schedules()
+	sources := scraper.Sources()
+
+	jobs := make([]JobInfo, 0, len(sources))
+	for _, source := range sources {
+		jobs = append(jobs, JobInfo{
+			Name:        source.Name(),
+			ChannelType: source.ChannelType(),
+			Approval:    source.Approval(),
+			Schedule:    scraper.Schedule(schedules, source.Name()),
+			Running:     scraper.IsRunning(source.Name()),
+		})
+	}
+
+	var defs []models.SourceDefinition
+	if err := database.DB.Order("name").Find(&defs).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch source definitions"})
+		return
+	}
+	for _, def := range defs {
+		source := declarative.Source{Definition: def}
+		jobs = append(jobs, JobInfo{
+			Name:        source.Name(),
+			ChannelType: source.ChannelType(),
+			Approval:    source.Approval(),
+			Schedule:    declarative.Schedule(def, schedules),
+			Running:     scraper.IsRunning(source.Name()),
+		})
+	}
+
+	for i := range jobs {
+		var lastRun models.ScrapeRun
+		err := database.DB.Where("job = ?", jobs[i].Name).Order("started_at DESC").First(&lastRun).Error
+		if err == nil {
+			jobs[i].LastRun = &lastRun
+		} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch job runs"})
+			return
+		}
+	}
+
+	c.JSON(http.StatusOK, jobs)
+}
+
+// findSource 按名称查找内置来源或管理员配置的来源
+func findSource(name string) (scraper.PriceSource, bool, error) {
+	if source, ok := scraper.Get(name); ok {
+		return source, true, nil
+	}
+	source, ok, err := declarative.Find(name)
+	if err != nil || !ok {
+		return nil, false, err
+	}
+	return source, true, nil
+}
+
+// GetJobRuns 分页获取任务的执行历史
+func GetJobRuns(c *gin.Context) {
+	name := c.Param("name")
+	_, ok, err := findSource(name)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch job"})
+		return
+	}
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Job not found"})
+		return
+	}
+
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("pageSize", "20"))
+	status := c.Query("status")
+
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 20
+	}
+
+	query := database.DB.Model(&models.ScrapeRun{}).Where("job = ?", name)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to count job runs"})
+		return
+	}
+
+	var runs []models.ScrapeRun
+	if err := query.Order("started_at DESC").Limit(pageSize).Offset((page - 1) * pageSize).Find(&runs).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch job runs"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"total": total,
+		"data":  runs,
+	})
+}
+
+// RunJob 立即在后台执行一次价格抓取任务
+func RunJob(c *gin.Context) {
+	source, ok, err := findSource(c.Param("name"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch job"})
+		return
+	}
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Job not found"})
+		return
+	}
+
+	var username string
+	if user, exists := c.Get("user"); exists {
+		username = user.(*models.User).Username
+	}
+
+	run, err := scraper.Start(source, models.ScrapeTriggerManual, username)
+	if errors.Is(err, scraper.ErrRunning) {
+		c.JSON(http.StatusConflict, gin.H{"error": "Job is already running"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start job"})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, run)
+}
+
+// DryRunJob 试运行价格抓取任务，返回将要新建、更新和跳过的记录，不写入数据库
+func DryRunJob(c *gin.Context) {
+	source, ok, err := findSource(c.Param("name"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch job"})
+		return
+	}
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Job not found"})
+		return
+	}
+
+	preview, err := scraper.DryRun(c.Request.Context(), source)
+	if err != nil {
+		c.JSON(http.StatusBadGateway, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, preview)
+}
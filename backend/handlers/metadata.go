@@ -0,0 +1,431 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"aimodels-prices/catalog"
+	"aimodels-prices/database"
+	"aimodels-prices/middleware"
+	"aimodels-prices/models"
+)
+
+// 支持的模态
+var validModalities = map[string]bool{
+	"text":  true,
+	"image": true,
+	"audio": true,
+	"video": true,
+	"file":  true,
+}
+
+// ptrEqual 比较两个指针指向的值是否相等
+func ptrEqual[T comparable](a, b *T) bool {
+	if a == nil && b == nil {
+		return true
+	}
+	if a == nil || b == nil {
+		return false
+	}
+	return *a == *b
+}
+
+// mergeMetadata 将src中非空的字段合并到dst，返回是否有变化
+func mergeMetadata(dst *models.ModelMetadata, src models.ModelMetadata) bool {
+	changed := false
+	if src.ContextLength != nil && !ptrEqual(dst.ContextLength, src.ContextLength) {
+		dst.ContextLength = src.ContextLength
+		changed = true
+	}
+	if src.MaxOutputTokens != nil && !ptrEqual(dst.MaxOutputTokens, src.MaxOutputTokens) {
+		dst.MaxOutputTokens = src.MaxOutputTokens
+		changed = true
+	}
+	if src.InputModalities != nil && !ptrEqual(dst.InputModalities, src.InputModalities) {
+		dst.InputModalities = src.InputModalities
+		changed = true
+	}
+	if src.OutputModalities != nil && !ptrEqual(dst.OutputModalities, src.OutputModalities) {
+		dst.OutputModalities = src.OutputModalities
+		changed = true
+	}
+	if src.SupportsTools != nil && !ptrEqual(dst.SupportsTools, src.SupportsTools) {
+		dst.SupportsTools = src.SupportsTools
+		changed = true
+	}
+	if src.SupportsJSONMode != nil && !ptrEqual(dst.SupportsJSONMode, src.SupportsJSONMode) {
+		dst.SupportsJSONMode = src.SupportsJSONMode
+		changed = true
+	}
+	if src.SupportsVision != nil && !ptrEqual(dst.SupportsVision, src.SupportsVision) {
+		dst.SupportsVision = src.SupportsVision
+		changed = true
+	}
+	if src.KnowledgeCutoff != nil && !ptrEqual(dst.KnowledgeCutoff, src.KnowledgeCutoff) {
+		dst.KnowledgeCutoff = src.KnowledgeCutoff
+		changed = true
+	}
+	return changed
+}
+
+// pendingMetadata 获取待审核的元数据视图（临时字段覆盖正式字段）
+func pendingMetadata(m models.ModelMetadata) models.ModelMetadata {
+	view := m
+	mergeMetadata(&view, models.ModelMetadata{
+		ContextLength:    m.TempContextLength,
+		MaxOutputTokens:  m.TempMaxOutputTokens,
+		InputModalities:  m.TempInputModalities,
+		OutputModalities: m.TempOutputModalities,
+		SupportsTools:    m.TempSupportsTools,
+		SupportsJSONMode: m.TempSupportsJSONMode,
+		SupportsVision:   m.TempSupportsVision,
+		KnowledgeCutoff:  m.TempKnowledgeCutoff,
+	})
+	return view
+}
+
+// validateMetadata 校验元数据字段
+func validateMetadata(meta models.ModelMetadata) error {
+	if meta.ContextLength != nil && *meta.ContextLength < 0 {
+		return fmt.Errorf("上下文长度不能为负数")
+	}
+	if meta.MaxOutputTokens != nil && *meta.MaxOutputTokens < 0 {
+		return fmt.Errorf("最大输出token数不能为负数")
+	}
+	for _, modalities := range []*string{meta.InputModalities, meta.OutputModalities} {
+		if modalities == nil {
+			continue
+		}
+		for _, m := range strings.Split(*modalities, ",") {
+			if m = strings.TrimSpace(m); m != "" && !validModalities[m] {
+				return fmt.Errorf("不支持的模态: %s", m)
+			}
+		}
+	}
+	return nil
+}
+
+// ProcessModelMetadata 处理模型元数据的创建和更新，与ProcessPrice一致：
+// 有审核权限时直接更新正式字段，否则写入临时字段等待审核。提交中为空的字段保持原值
+func ProcessModelMetadata(meta models.ModelMetadata, existing *models.ModelMetadata, isAdmin bool, username string) (models.ModelMetadata, bool, error) {
+	if err := validateMetadata(meta); err != nil {
+		return meta, false, err
+	}
+
+	if existing == nil {
+		// 创建新记录
+		meta.ID = 0
+		meta.Status = "pending"
+		if isAdmin {
+			meta.Status = "approved"
+		}
+		meta.CreatedBy = username
+		if err := database.DB.Create(&meta).Error; err != nil {
+			return meta, false, err
+		}
+		return meta, true, nil
+	}
+
+	if isAdmin {
+		// 管理员直接更新正式字段
+		if !mergeMetadata(existing, meta) && existing.Status == "approved" {
+			return *existing, false, nil
+		}
+		if meta.Source != "" {
+			existing.Source = meta.Source
+		}
+		existing.Status = "approved"
+		existing.UpdatedBy = &username
+		clearMetadataTemp(existing)
+	} else if existing.Status == "pending" && existing.TempSource == nil {
+		// 尚未审核的新记录直接修改正式字段
+		if !mergeMetadata(existing, meta) {
+			return *existing, false, nil
+		}
+		existing.UpdatedBy = &username
+	} else {
+		// 普通用户的修改与正式字段和已提交的临时字段都相同时不更新
+		proposed := *existing
+		if !mergeMetadata(&proposed, meta) {
+			return *existing, false, nil
+		}
+		current := pendingMetadata(*existing)
+		if !mergeMetadata(&current, meta) && existing.Status == "pending" {
+			return *existing, false, nil
+		}
+
+		existing.TempContextLength = proposed.ContextLength
+		existing.TempMaxOutputTokens = proposed.MaxOutputTokens
+		existing.TempInputModalities = proposed.InputModalities
+		existing.TempOutputModalities = proposed.OutputModalities
+		existing.TempSupportsTools = proposed.SupportsTools
+		existing.TempSupportsJSONMode = proposed.SupportsJSONMode
+		existing.TempSupportsVision = proposed.SupportsVision
+		existing.TempKnowledgeCutoff = proposed.KnowledgeCutoff
+		existing.TempSource = &meta.Source
+		existing.Status = "pending"
+		existing.UpdatedBy = &username
+	}
+
+	if err := database.DB.Save(existing).Error; err != nil {
+		return *existing, false, err
+	}
+	return *existing, true, nil
+}
+
+// clearMetadataTemp 清除所有临时字段
+func clearMetadataTemp(m *models.ModelMetadata) {
+	m.TempContextLength = nil
+	m.TempMaxOutputTokens = nil
+	m.TempInputModalities = nil
+	m.TempOutputModalities = nil
+	m.TempSupportsTools = nil
+	m.TempSupportsJSONMode = nil
+	m.TempSupportsVision = nil
+	m.TempKnowledgeCutoff = nil
+	m.TempSource = nil
+}
+
+// SaveModelMetadata 供抓取任务使用，按模型名称写入元数据
+// 多个来源会写入同一模型，只有上次由同一来源写入的记录才会被更新；
+// 其他来源写入或人工修改过的已审核记录只补充为空的字段（需来源自动审核通过），待审核的记录交由审核人员处理
+func SaveModelMetadata(channelType uint, modelName string, meta models.ModelMetadata, isAdmin bool, username string) (bool, error) {
+	meta.ModelID = catalog.Key(channelType, modelName)
+
+	var existing models.ModelMetadata
+	err := database.DB.Where("model_id = ?", meta.ModelID).First(&existing).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return false, err
+	}
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		_, changed, err := ProcessModelMetadata(meta, nil, isAdmin, username)
+		return changed, err
+	}
+
+	lastWriter := existing.CreatedBy
+	if existing.UpdatedBy != nil {
+		lastWriter = *existing.UpdatedBy
+	}
+	if existing.Source != meta.Source || lastWriter != username {
+		if existing.Status != "approved" || !isAdmin {
+			return false, nil
+		}
+		// 只补充空字段，保留原来源和修改人，避免之后被当作本来源的记录覆盖
+		if err := validateMetadata(meta); err != nil {
+			return false, err
+		}
+		if !mergeMetadata(&existing, emptyFields(existing, meta)) {
+			return false, nil
+		}
+		return true, database.DB.Save(&existing).Error
+	}
+
+	_, changed, err := ProcessModelMetadata(meta, &existing, isAdmin, username)
+	return changed, err
+}
+
+// emptyFields 只保留meta中existing为空的字段
+func emptyFields(existing, meta models.ModelMetadata) models.ModelMetadata {
+	if existing.ContextLength != nil {
+		meta.ContextLength = nil
+	}
+	if existing.MaxOutputTokens != nil {
+		meta.MaxOutputTokens = nil
+	}
+	if existing.InputModalities != nil {
+		meta.InputModalities = nil
+	}
+	if existing.OutputModalities != nil {
+		meta.OutputModalities = nil
+	}
+	if existing.SupportsTools != nil {
+		meta.SupportsTools = nil
+	}
+	if existing.SupportsJSONMode != nil {
+		meta.SupportsJSONMode = nil
+	}
+	if existing.SupportsVision != nil {
+		meta.SupportsVision = nil
+	}
+	if existing.KnowledgeCutoff != nil {
+		meta.KnowledgeCutoff = nil
+	}
+	return meta
+}
+
+// GetMetadataList 获取模型元数据列表，可按状态筛选
+func GetMetadataList(c *gin.Context) {
+	query := database.DB.Order("model_id")
+	if status := c.Query("status"); status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	var list []models.ModelMetadata
+	if err := query.Find(&list).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch model metadata"})
+		return
+	}
+
+	c.JSON(http.StatusOK, list)
+}
+
+// GetModelMetadata 获取单个模型的元数据
+func GetModelMetadata(c *gin.Context) {
+	modelID := catalog.Key(catalog.AnyChannel, c.Param("name"))
+
+	var meta models.ModelMetadata
+	if err := database.DB.Where("model_id = ?", modelID).First(&meta).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Model metadata not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, meta)
+}
+
+// SubmitModelMetadata 提交模型元数据，t4或admin用户提交的直接生效
+func SubmitModelMetadata(c *gin.Context) {
+	var meta models.ModelMetadata
+	if err := c.ShouldBindJSON(&meta); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found"})
+		return
+	}
+	currentUser := user.(*models.User)
+
+	meta.ModelID = catalog.Key(catalog.AnyChannel, c.Param("name"))
+
+	var existing *models.ModelMetadata
+	var found models.ModelMetadata
+	if err := database.DB.Where("model_id = ?", meta.ModelID).First(&found).Error; err == nil {
+		existing = &found
+	}
+
+	result, changed, err := ProcessModelMetadata(meta, existing, middleware.IsModerator(currentUser), currentUser.Username)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if changed {
+		clearPriceCache()
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// UpdateModelMetadataStatus 审核模型元数据
+func UpdateModelMetadataStatus(c *gin.Context) {
+	var input struct {
+		Status string `json:"status" binding:"required,oneof=approved rejected"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	modelID := catalog.Key(catalog.AnyChannel, c.Param("name"))
+
+	var meta models.ModelMetadata
+	if err := database.DB.Where("model_id = ?", modelID).First(&meta).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Model metadata not found"})
+		return
+	}
+
+	hasTemp := meta.TempSource != nil
+	if input.Status == "approved" {
+		meta = pendingMetadata(meta)
+		if meta.TempSource != nil {
+			meta.Source = *meta.TempSource
+		}
+		clearMetadataTemp(&meta)
+		meta.Status = "approved"
+		if err := database.DB.Save(&meta).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update metadata status"})
+			return
+		}
+	} else if !hasTemp {
+		// 新提交的元数据被拒绝时直接删除
+		if err := database.DB.Delete(&meta).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete rejected metadata"})
+			return
+		}
+	} else {
+		// 更新被拒绝时恢复到原始状态
+		clearMetadataTemp(&meta)
+		meta.Status = "approved"
+		meta.UpdatedBy = nil
+		if err := database.DB.Save(&meta).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update metadata status"})
+			return
+		}
+	}
+
+	clearPriceCache()
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Status updated successfully",
+		"status":  input.Status,
+	})
+}
+
+// 能力筛选参数到元数据字段的映射
+var supportColumns = map[string]string{
+	"tools":  "supports_tools",
+	"json":   "supports_json_mode",
+	"vision": "supports_vision",
+}
+
+// filterModelsByMetadata 按元数据筛选条件获取匹配的模型键及其别名名称
+func filterModelsByMetadata(minContext, supports, modality string) ([]string, []string, error) {
+	query := database.DB.Model(&models.ModelMetadata{}).Where("status = 'approved'")
+
+	if minContext != "" {
+		n, err := strconv.Atoi(minContext)
+		if err != nil || n < 0 {
+			return nil, nil, fmt.Errorf("invalid min_context")
+		}
+		query = query.Where("context_length >= ?", n)
+	}
+
+	if supports != "" {
+		for _, s := range strings.Split(supports, ",") {
+			column, ok := supportColumns[strings.TrimSpace(s)]
+			if !ok {
+				return nil, nil, fmt.Errorf("invalid supports value: %s", s)
+			}
+			query = query.Where(column+" = ?", true)
+		}
+	}
+
+	if modality != "" {
+		if !validModalities[modality] {
+			return nil, nil, fmt.Errorf("invalid modality: %s", modality)
+		}
+		query = query.Where("FIND_IN_SET(?, REPLACE(input_modalities, ' ', '')) > 0", modality)
+	}
+
+	var modelIDs []string
+	if err := query.Pluck("model_id", &modelIDs).Error; err != nil {
+		return nil, nil, err
+	}
+
+	names := append([]string{}, modelIDs...)
+	for _, id := range modelIDs {
+		for _, a := range catalog.Aliases(id) {
+			names = append(names, a.Alias)
+		}
+	}
+
+	return modelIDs, names, nil
+}
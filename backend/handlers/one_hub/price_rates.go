@@ -8,6 +8,7 @@ import (
 
 	"github.com/gin-gonic/gin"
 
+	"aimodels-prices/catalog"
 	"aimodels-prices/database"
 	"aimodels-prices/models"
 )
@@ -28,12 +29,34 @@ type ExtraRatios struct {
 
 // PriceRate 价格倍率结构
 type PriceRate struct {
-	Model       string       `json:"model"`
-	Type        string       `json:"type"`
-	ChannelType uint         `json:"channel_type"`
-	Input       float64      `json:"input"`
-	Output      float64      `json:"output"`
-	ExtraRatios *ExtraRatios `json:"extra_ratios,omitempty"`
+	Model       string         `json:"model"`
+	Type        string         `json:"type"`
+	ChannelType uint           `json:"channel_type"`
+	Input       float64        `json:"input"`
+	Output      float64        `json:"output"`
+	ExtraRatios *ExtraRatios   `json:"extra_ratios,omitempty"`
+	Tiers       []TierRate     `json:"tiers,omitempty"`
+	Modifiers   []ModifierRate `json:"modifiers,omitempty"`
+}
+
+// ModifierRate 价格调整后的倍率，仅在 modifiers=true 时导出
+type ModifierRate struct {
+	Kind     string  `json:"kind"`
+	Start    string  `json:"start,omitempty"`
+	End      string  `json:"end,omitempty"`
+	Timezone string  `json:"timezone,omitempty"`
+	Input    float64 `json:"input"`
+	Output   float64 `json:"output"`
+}
+
+// TierRate 阶梯价格倍率，提示词token数超过阈值时使用
+type TierRate struct {
+	AbovePromptTokens int64    `json:"above_prompt_tokens"`
+	Input             float64  `json:"input"`
+	Output            float64  `json:"output"`
+	CachedTokens      *float64 `json:"cached_tokens,omitempty"`
+	CachedReadTokens  *float64 `json:"cached_read_tokens,omitempty"`
+	CachedWriteTokens *float64 `json:"cached_write_tokens,omitempty"`
 }
 
 // 定义扩展价格字段是否相对于input的映射
@@ -61,6 +84,72 @@ func round(num float64, precision int) float64 {
 	return math.Round(num*multiplier) / multiplier
 }
 
+// buildTierRates 计算阶梯价格倍率，缓存价格为相对于该档输入倍率的比值
+func buildTierRates(price models.Price) []TierRate {
+	if len(price.Tiers) == 0 {
+		return nil
+	}
+
+	divisor := 14.0
+	if price.Currency == "USD" {
+		divisor = 2
+	}
+
+	relative := func(value *float64, baseRate float64) *float64 {
+		if value == nil {
+			return nil
+		}
+		rate := calculateSafeRatio(round(*value/divisor, 4), baseRate)
+		return &rate
+	}
+
+	tiers := make([]TierRate, 0, len(price.Tiers))
+	for _, tier := range price.Tiers {
+		inputRate := round(tier.InputPrice/divisor, 4)
+		tiers = append(tiers, TierRate{
+			AbovePromptTokens: tier.AbovePromptTokens,
+			Input:             inputRate,
+			Output:            round(tier.OutputPrice/divisor, 4),
+			CachedTokens:      relative(tier.CachedTokens, inputRate),
+			CachedReadTokens:  relative(tier.CachedReadTokens, inputRate),
+			CachedWriteTokens: relative(tier.CachedWriteTokens, inputRate),
+		})
+	}
+	return tiers
+}
+
+// buildModifierRates 计算价格调整规则生效时的输入输出倍率
+func buildModifierRates(modifiers models.PriceModifiers, inputRate, outputRate float64) []ModifierRate {
+	if len(modifiers) == 0 {
+		return nil
+	}
+
+	rates := make([]ModifierRate, 0, len(modifiers))
+	for _, modifier := range modifiers {
+		rates = append(rates, ModifierRate{
+			Kind:     modifier.Kind,
+			Start:    modifier.Start,
+			End:      modifier.End,
+			Timezone: modifier.Timezone,
+			Input:    round(inputRate*modifier.InputMultiplier, 4),
+			Output:   round(outputRate*modifier.OutputMultiplier, 4),
+		})
+	}
+	return rates
+}
+
+// rateTypeOf 返回价格在one_hub中的计费类型，按张、按秒等计数单位与按次计费一样导出为times
+// 按字节、字符、像素步数计费的价格没有对应的倍率形式，返回false
+func rateTypeOf(price models.Price) (string, bool) {
+	switch price.Unit() {
+	case models.BillingUnitTokens:
+		return "tokens", true
+	case models.BillingUnitRequest, models.BillingUnitImage, models.BillingUnitVideoSecond, models.BillingUnitAudioSecond:
+		return "times", true
+	}
+	return "", false
+}
+
 // 计算安全倍率，避免除以零
 func calculateSafeRatio(value, baseRate float64) float64 {
 	// 如果基准率为0或接近0，返回1作为默认倍率
@@ -75,6 +164,25 @@ func calculateSafeRatio(value, baseRate float64) float64 {
 func GetPriceRates(c *gin.Context) {
 	cacheKey := "one_hub_price_rates"
 
+	// canonical=true 时按模型目录中的规范名称导出并去重
+	canonical := c.Query("canonical") == "true"
+	if canonical {
+		cacheKey += "_canonical"
+	}
+
+	// modifiers=true 时额外导出错峰、批量等价格调整后的倍率
+	withModifiers := c.Query("modifiers") == "true"
+	if withModifiers {
+		cacheKey += "_modifiers"
+	}
+
+	// 默认只导出已审核的价格，include_delisted=true 时同时导出上游已下架的价格
+	statuses := []string{"approved"}
+	if c.Query("include_delisted") == "true" {
+		cacheKey += "_delisted"
+		statuses = append(statuses, "delisted")
+	}
+
 	// 尝试从缓存获取
 	if cachedData, found := database.GlobalCache.Get(cacheKey); found {
 		if rates, ok := cachedData.([]PriceRate); ok {
@@ -85,8 +193,8 @@ func GetPriceRates(c *gin.Context) {
 
 	// 使用索引优化查询，只查询需要的字段
 	var prices []models.Price
-	if err := database.DB.Select("model, billing_type, channel_type, input_price, output_price, currency, status, input_audio_tokens, output_audio_tokens, cached_tokens, cached_read_tokens, cached_write_tokens, reasoning_tokens, input_text_tokens, output_text_tokens, input_image_tokens, output_image_tokens").
-		Where("status = 'approved'").
+	if err := database.DB.Select("model, billing_type, billing_unit, unit_meta, channel_type, input_price, output_price, currency, status, input_audio_tokens, output_audio_tokens, cached_tokens, cached_read_tokens, cached_write_tokens, reasoning_tokens, input_text_tokens, output_text_tokens, input_image_tokens, output_image_tokens, tiers, modifiers").
+		Where("status IN ?", statuses).
 		Find(&prices).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch prices"})
 		return
@@ -97,6 +205,12 @@ func GetPriceRates(c *gin.Context) {
 
 	// 计算倍率
 	for _, price := range prices {
+		// one_hub只支持按token和按次计费，按字节、字符等计费的价格无法用倍率表示，跳过以免被当作token价格
+		rateType, ok := rateTypeOf(price)
+		if !ok {
+			continue
+		}
+
 		// 根据货币类型计算倍率
 		var inputRate, outputRate float64
 
@@ -316,15 +430,25 @@ func GetPriceRates(c *gin.Context) {
 		// 创建当前价格的PriceRate
 		currentRate := PriceRate{
 			Model:       price.Model,
-			Type:        price.BillingType,
+			Type:        rateType,
 			ChannelType: price.ChannelType,
 			Input:       inputRate,
 			Output:      outputRate,
 			ExtraRatios: extraRatios,
+			Tiers:       buildTierRates(price),
+		}
+		if withModifiers {
+			currentRate.Modifiers = buildModifierRates(price.Modifiers, inputRate, outputRate)
+		}
+
+		if canonical {
+			if canonicalID, found := catalog.Resolve(price.ChannelType, price.Model); found {
+				currentRate.Model = canonicalID
+			}
 		}
 
 		// 转换为小写以实现不区分大小写比较
-		modelLower := strings.ToLower(price.Model)
+		modelLower := strings.ToLower(currentRate.Model)
 
 		// 检查是否已存在相同模型名称（不区分大小写）
 		if existingRate, exists := modelRateMap[modelLower]; exists {
@@ -359,6 +483,25 @@ func GetPriceRates(c *gin.Context) {
 func GetOfficialPriceRates(c *gin.Context) {
 	cacheKey := "one_hub_official_price_rates"
 
+	// canonical=true 时按模型目录中的规范名称导出并去重
+	canonical := c.Query("canonical") == "true"
+	if canonical {
+		cacheKey += "_canonical"
+	}
+
+	// modifiers=true 时额外导出错峰、批量等价格调整后的倍率
+	withModifiers := c.Query("modifiers") == "true"
+	if withModifiers {
+		cacheKey += "_modifiers"
+	}
+
+	// 默认只导出已审核的价格，include_delisted=true 时同时导出上游已下架的价格
+	statuses := []string{"approved"}
+	if c.Query("include_delisted") == "true" {
+		cacheKey += "_delisted"
+		statuses = append(statuses, "delisted")
+	}
+
 	// 尝试从缓存获取
 	if cachedData, found := database.GlobalCache.Get(cacheKey); found {
 		if rates, ok := cachedData.([]PriceRate); ok {
@@ -370,8 +513,8 @@ func GetOfficialPriceRates(c *gin.Context) {
 	// 使用索引优化查询，只查询需要的字段，并添加厂商ID筛选条件
 	var prices []models.Price
 	result := database.DB.Model(&models.Price{}).
-		Select("model, billing_type, channel_type, input_price, output_price, currency, status, input_audio_tokens, output_audio_tokens, cached_tokens, cached_read_tokens, cached_write_tokens, reasoning_tokens, input_text_tokens, output_text_tokens, input_image_tokens, output_image_tokens").
-		Where(&models.Price{Status: "approved"}).
+		Select("model, billing_type, billing_unit, unit_meta, channel_type, input_price, output_price, currency, status, input_audio_tokens, output_audio_tokens, cached_tokens, cached_read_tokens, cached_write_tokens, reasoning_tokens, input_text_tokens, output_text_tokens, input_image_tokens, output_image_tokens, tiers, modifiers").
+		Where("status IN ?", statuses).
 		Where("channel_type < ?", 1000).
 		Find(&prices)
 
@@ -385,6 +528,12 @@ func GetOfficialPriceRates(c *gin.Context) {
 
 	// 计算倍率
 	for _, price := range prices {
+		// one_hub只支持按token和按次计费，按字节、字符等计费的价格无法用倍率表示，跳过以免被当作token价格
+		rateType, ok := rateTypeOf(price)
+		if !ok {
+			continue
+		}
+
 		// 根据货币类型计算倍率
 		var inputRate, outputRate float64
 
@@ -604,15 +753,25 @@ func GetOfficialPriceRates(c *gin.Context) {
 		// 创建当前价格的PriceRate
 		currentRate := PriceRate{
 			Model:       price.Model,
-			Type:        price.BillingType,
+			Type:        rateType,
 			ChannelType: price.ChannelType,
 			Input:       inputRate,
 			Output:      outputRate,
 			ExtraRatios: extraRatios,
+			Tiers:       buildTierRates(price),
+		}
+		if withModifiers {
+			currentRate.Modifiers = buildModifierRates(price.Modifiers, inputRate, outputRate)
+		}
+
+		if canonical {
+			if canonicalID, found := catalog.Resolve(price.ChannelType, price.Model); found {
+				currentRate.Model = canonicalID
+			}
 		}
 
 		// 转换为小写以实现不区分大小写比较
-		modelLower := strings.ToLower(price.Model)
+		modelLower := strings.ToLower(currentRate.Model)
 
 		// 检查是否已存在相同模型名称（不区分大小写）
 		if existingRate, exists := modelRateMap[modelLower]; exists {
@@ -645,6 +804,17 @@ func GetOfficialPriceRates(c *gin.Context) {
 
 // ClearRatesCache 清除价格倍率缓存
 func ClearRatesCache() {
-	database.GlobalCache.Delete("one_hub_price_rates")
-	database.GlobalCache.Delete("one_hub_official_price_rates")
+	// 缓存键后缀按 canonical、modifiers、include_delisted 的顺序组合
+	suffixes := []string{""}
+	for _, flag := range []string{"_canonical", "_modifiers", "_delisted"} {
+		for _, suffix := range suffixes {
+			suffixes = append(suffixes, suffix+flag)
+		}
+	}
+
+	for _, key := range []string{"one_hub_price_rates", "one_hub_official_price_rates"} {
+		for _, suffix := range suffixes {
+			database.GlobalCache.Delete(key + suffix)
+		}
+	}
 }
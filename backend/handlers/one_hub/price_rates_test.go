@@ -0,0 +1,37 @@
+package one_hub
+
+import (
+	"testing"
+
+	"aimodels-prices/models"
+)
+
+func TestRateTypeOf(t *testing.T) {
+	tests := []struct {
+		name  string
+		price models.Price
+		want  string
+		ok    bool
+	}{
+		{"tokens", models.Price{BillingType: "tokens"}, "tokens", true},
+		{"times", models.Price{BillingType: "times"}, "times", true},
+		// SiliconFlow的图片生成模型，原先按times导出
+		{"siliconflow image", models.Price{
+			Model:       "black-forest-labs/FLUX.1-pro",
+			BillingType: "times",
+			BillingUnit: models.BillingUnitImage,
+			UnitMeta:    &models.UnitMeta{Raw: "/ Image"},
+		}, "times", true},
+		{"video second", models.Price{BillingType: "times", BillingUnit: models.BillingUnitVideoSecond}, "times", true},
+		{"audio second", models.Price{BillingType: "times", BillingUnit: models.BillingUnitAudioSecond}, "times", true},
+		{"byte", models.Price{BillingType: "times", BillingUnit: models.BillingUnitByte}, "", false},
+		{"character", models.Price{BillingType: "times", BillingUnit: models.BillingUnitCharacter}, "", false},
+		{"pixel step", models.Price{BillingType: "times", BillingUnit: models.BillingUnitPixelStep}, "", false},
+	}
+	for _, tt := range tests {
+		got, ok := rateTypeOf(tt.price)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("%s: rateTypeOf = %q, %v, want %q, %v", tt.name, got, ok, tt.want, tt.ok)
+		}
+	}
+}
@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"errors"
 	"fmt"
 	"math"
 	"net/http"
@@ -8,13 +9,27 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 
+	"aimodels-prices/catalog"
 	"aimodels-prices/database"
+	"aimodels-prices/events"
 	"aimodels-prices/handlers/one_hub"
 	"aimodels-prices/middleware"
 	"aimodels-prices/models"
 )
 
+// ErrDuplicatePrice 同一厂商下已存在相同名称（不区分大小写）的模型，由数据库唯一索引保证
+var ErrDuplicatePrice = errors.New("同一厂商下已存在相同名称的模型")
+
+// translateSaveError 将唯一索引冲突转换为 ErrDuplicatePrice
+func translateSaveError(err error) error {
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return ErrDuplicatePrice
+	}
+	return err
+}
+
 func GetPrices(c *gin.Context) {
 	// 获取分页和筛选参数
 	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
@@ -22,6 +37,9 @@ func GetPrices(c *gin.Context) {
 	channelType := c.Query("channel_type") // 厂商筛选参数
 	searchQuery := c.Query("search")       // 搜索查询参数
 	status := c.Query("status")            // 状态筛选参数
+	minContext := c.Query("min_context")   // 最小上下文长度
+	supports := c.Query("supports")        // 能力筛选，如 tools,json,vision
+	modality := c.Query("modality")        // 输入模态筛选，如 image
 
 	if page < 1 {
 		page = 1
@@ -33,8 +51,8 @@ func GetPrices(c *gin.Context) {
 	offset := (page - 1) * pageSize
 
 	// 构建缓存键
-	cacheKey := fmt.Sprintf("prices_page_%d_size_%d_channel_%s_search_%s_status_%s",
-		page, pageSize, channelType, searchQuery, status)
+	cacheKey := fmt.Sprintf("prices_page_%d_size_%d_channel_%s_search_%s_status_%s_ctx_%s_supports_%s_modality_%s",
+		page, pageSize, channelType, searchQuery, status, minContext, supports, modality)
 
 	// 尝试从缓存获取
 	if cachedData, found := database.GlobalCache.Get(cacheKey); found {
@@ -51,19 +69,32 @@ func GetPrices(c *gin.Context) {
 	if channelType != "" {
 		query = query.Where("channel_type = ?", channelType)
 	}
-	// 添加搜索条件
+	// 添加搜索条件，能解析到模型目录时同时匹配该模型的所有别名
 	if searchQuery != "" {
-		query = query.Where("model LIKE ?", "%"+searchQuery+"%")
+		if canonicalID, found := catalog.Resolve(catalog.AnyChannel, searchQuery); found {
+			query = query.Where("model LIKE ? OR model IN ?", "%"+searchQuery+"%", catalog.Names(canonicalID))
+		} else {
+			query = query.Where("model LIKE ?", "%"+searchQuery+"%")
+		}
 	}
 	// 添加状态筛选条件
 	if status != "" {
 		query = query.Where("status = ?", status)
 	}
+	// 添加模型元数据筛选条件
+	if minContext != "" || supports != "" || modality != "" {
+		modelIDs, names, err := filterModelsByMetadata(minContext, supports, modality)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		query = query.Where("(model IN ? OR LOWER(SUBSTRING_INDEX(model, '/', -1)) IN ?)", names, modelIDs)
+	}
 
 	// 获取总数 - 使用缓存优化
 	var total int64
-	totalCacheKey := fmt.Sprintf("prices_count_channel_%s_search_%s_status_%s",
-		channelType, searchQuery, status)
+	totalCacheKey := fmt.Sprintf("prices_count_channel_%s_search_%s_status_%s_ctx_%s_supports_%s_modality_%s",
+		channelType, searchQuery, status, minContext, supports, modality)
 
 	if cachedTotal, found := database.GlobalCache.Get(totalCacheKey); found {
 		if t, ok := cachedTotal.(int64); ok {
@@ -103,6 +134,24 @@ func GetPrices(c *gin.Context) {
 
 // processPrice 处理价格的创建和更新逻辑,只负责处理业务逻辑
 func ProcessPrice(price models.Price, existingPrice *models.Price, isAdmin bool, username string) (models.Price, bool, error) {
+	// 验证阶梯价格（如果提供）
+	if err := price.Tiers.Validate(); err != nil {
+		return price, false, err
+	}
+
+	// 验证价格调整规则（如果提供）
+	if err := price.Modifiers.Validate(); err != nil {
+		return price, false, err
+	}
+
+	// 验证计费单位，非token单位统一按数量计费
+	if err := models.ValidateBillingUnit(price.BillingUnit, price.UnitMeta); err != nil {
+		return price, false, err
+	}
+	if price.BillingUnit != "" {
+		price.BillingType = models.BillingTypeForUnit(price.BillingUnit)
+	}
+
 	// 如果是更新操作且存在现有记录
 	if existingPrice != nil {
 		// 使用更精确的浮点数比较函数
@@ -128,6 +177,8 @@ func ProcessPrice(price models.Price, existingPrice *models.Price, isAdmin bool,
 			// 管理员直接更新主字段，检查是否有实际变化
 			if existingPrice.Model == price.Model &&
 				existingPrice.BillingType == price.BillingType &&
+				existingPrice.BillingUnit == price.BillingUnit &&
+				existingPrice.UnitMeta.Equal(price.UnitMeta) &&
 				existingPrice.ChannelType == price.ChannelType &&
 				existingPrice.Currency == price.Currency &&
 				priceEqual(existingPrice.InputPrice, price.InputPrice) &&
@@ -142,14 +193,22 @@ func ProcessPrice(price models.Price, existingPrice *models.Price, isAdmin bool,
 				pointerPriceEqual(existingPrice.OutputTextTokens, price.OutputTextTokens) &&
 				pointerPriceEqual(existingPrice.InputImageTokens, price.InputImageTokens) &&
 				pointerPriceEqual(existingPrice.OutputImageTokens, price.OutputImageTokens) &&
+				pointerPriceEqual(existingPrice.RequestPrice, price.RequestPrice) &&
+				pointerPriceEqual(existingPrice.ImagePrice, price.ImagePrice) &&
+				existingPrice.Tiers.Equal(price.Tiers) &&
+				existingPrice.Modifiers.Equal(price.Modifiers) &&
 				existingPrice.PriceSource == price.PriceSource {
 				// 没有变化，不需要更新
 				return *existingPrice, false, nil
 			}
 
+			previous := *existingPrice
+
 			// 有变化，更新字段
 			existingPrice.Model = price.Model
 			existingPrice.BillingType = price.BillingType
+			existingPrice.BillingUnit = price.BillingUnit
+			existingPrice.UnitMeta = price.UnitMeta
 			existingPrice.ChannelType = price.ChannelType
 			existingPrice.Currency = price.Currency
 			existingPrice.InputPrice = price.InputPrice
@@ -164,11 +223,18 @@ func ProcessPrice(price models.Price, existingPrice *models.Price, isAdmin bool,
 			existingPrice.OutputTextTokens = price.OutputTextTokens
 			existingPrice.InputImageTokens = price.InputImageTokens
 			existingPrice.OutputImageTokens = price.OutputImageTokens
+			existingPrice.RequestPrice = price.RequestPrice
+			existingPrice.ImagePrice = price.ImagePrice
+			existingPrice.Tiers = price.Tiers
+			existingPrice.Modifiers = price.Modifiers
 			existingPrice.PriceSource = price.PriceSource
 			existingPrice.Status = "approved"
+			existingPrice.DelistedAt = nil
 			existingPrice.UpdatedBy = &username
 			existingPrice.TempModel = nil
 			existingPrice.TempBillingType = nil
+			existingPrice.TempBillingUnit = nil
+			existingPrice.TempUnitMeta = nil
 			existingPrice.TempChannelType = nil
 			existingPrice.TempCurrency = nil
 			existingPrice.TempInputPrice = nil
@@ -183,12 +249,17 @@ func ProcessPrice(price models.Price, existingPrice *models.Price, isAdmin bool,
 			existingPrice.TempOutputTextTokens = nil
 			existingPrice.TempInputImageTokens = nil
 			existingPrice.TempOutputImageTokens = nil
+			existingPrice.TempRequestPrice = nil
+			existingPrice.TempImagePrice = nil
+			existingPrice.TempTiers = nil
+			existingPrice.TempModifiers = nil
 			existingPrice.TempPriceSource = nil
 
 			// 保存更新
 			if err := database.DB.Save(existingPrice).Error; err != nil {
-				return *existingPrice, false, err
+				return *existingPrice, false, translateSaveError(err)
 			}
+			events.Publish(models.PriceEventUpdated, *existingPrice, &previous, username)
 			return *existingPrice, true, nil
 		} else {
 			// 普通用户更新临时字段，检查是否有实际变化
@@ -198,6 +269,8 @@ func ProcessPrice(price models.Price, existingPrice *models.Price, isAdmin bool,
 
 			if existingPrice.Model != price.Model ||
 				existingPrice.BillingType != price.BillingType ||
+				existingPrice.BillingUnit != price.BillingUnit ||
+				!existingPrice.UnitMeta.Equal(price.UnitMeta) ||
 				existingPrice.ChannelType != price.ChannelType ||
 				existingPrice.Currency != price.Currency ||
 				!priceEqual(existingPrice.InputPrice, price.InputPrice) ||
@@ -212,6 +285,10 @@ func ProcessPrice(price models.Price, existingPrice *models.Price, isAdmin bool,
 				!pointerPriceEqual(existingPrice.OutputTextTokens, price.OutputTextTokens) ||
 				!pointerPriceEqual(existingPrice.InputImageTokens, price.InputImageTokens) ||
 				!pointerPriceEqual(existingPrice.OutputImageTokens, price.OutputImageTokens) ||
+				!pointerPriceEqual(existingPrice.RequestPrice, price.RequestPrice) ||
+				!pointerPriceEqual(existingPrice.ImagePrice, price.ImagePrice) ||
+				!existingPrice.Tiers.Equal(price.Tiers) ||
+				!existingPrice.Modifiers.Equal(price.Modifiers) ||
 				existingPrice.PriceSource != price.PriceSource {
 				hasChanges = true
 			}
@@ -221,6 +298,8 @@ func ProcessPrice(price models.Price, existingPrice *models.Price, isAdmin bool,
 				// 检查是否与已有的临时字段相同
 				if *existingPrice.TempModel == price.Model &&
 					(existingPrice.TempBillingType == nil || *existingPrice.TempBillingType == price.BillingType) &&
+					(existingPrice.TempBillingUnit == nil || *existingPrice.TempBillingUnit == price.BillingUnit) &&
+					(existingPrice.TempUnitMeta == nil || existingPrice.TempUnitMeta.Equal(price.UnitMeta)) &&
 					(existingPrice.TempChannelType == nil || *existingPrice.TempChannelType == price.ChannelType) &&
 					(existingPrice.TempCurrency == nil || *existingPrice.TempCurrency == price.Currency) &&
 					(existingPrice.TempInputPrice == nil || priceEqual(*existingPrice.TempInputPrice, price.InputPrice)) &&
@@ -235,6 +314,10 @@ func ProcessPrice(price models.Price, existingPrice *models.Price, isAdmin bool,
 					(existingPrice.TempOutputTextTokens == nil || pointerPriceEqual(existingPrice.TempOutputTextTokens, price.OutputTextTokens)) &&
 					(existingPrice.TempInputImageTokens == nil || pointerPriceEqual(existingPrice.TempInputImageTokens, price.InputImageTokens)) &&
 					(existingPrice.TempOutputImageTokens == nil || pointerPriceEqual(existingPrice.TempOutputImageTokens, price.OutputImageTokens)) &&
+					(existingPrice.TempRequestPrice == nil || pointerPriceEqual(existingPrice.TempRequestPrice, price.RequestPrice)) &&
+					(existingPrice.TempImagePrice == nil || pointerPriceEqual(existingPrice.TempImagePrice, price.ImagePrice)) &&
+					(existingPrice.TempTiers == nil || existingPrice.TempTiers.Equal(price.Tiers)) &&
+					(existingPrice.TempModifiers == nil || existingPrice.TempModifiers.Equal(price.Modifiers)) &&
 					(existingPrice.TempPriceSource == nil || *existingPrice.TempPriceSource == price.PriceSource) {
 					// 与之前提交的临时值相同，不需要更新
 					hasChanges = false
@@ -246,9 +329,13 @@ func ProcessPrice(price models.Price, existingPrice *models.Price, isAdmin bool,
 				return *existingPrice, false, nil
 			}
 
+			previous := *existingPrice
+
 			// 有变化，更新临时字段
 			existingPrice.TempModel = &price.Model
 			existingPrice.TempBillingType = &price.BillingType
+			existingPrice.TempBillingUnit = &price.BillingUnit
+			existingPrice.TempUnitMeta = price.UnitMeta
 			existingPrice.TempChannelType = &price.ChannelType
 			existingPrice.TempCurrency = &price.Currency
 			existingPrice.TempInputPrice = &price.InputPrice
@@ -263,14 +350,19 @@ func ProcessPrice(price models.Price, existingPrice *models.Price, isAdmin bool,
 			existingPrice.TempOutputTextTokens = price.OutputTextTokens
 			existingPrice.TempInputImageTokens = price.InputImageTokens
 			existingPrice.TempOutputImageTokens = price.OutputImageTokens
+			existingPrice.TempRequestPrice = price.RequestPrice
+			existingPrice.TempImagePrice = price.ImagePrice
+			existingPrice.TempTiers = price.Tiers
+			existingPrice.TempModifiers = price.Modifiers
 			existingPrice.TempPriceSource = &price.PriceSource
 			existingPrice.Status = "pending"
 			existingPrice.UpdatedBy = &username
 
 			// 保存更新
 			if err := database.DB.Save(existingPrice).Error; err != nil {
-				return *existingPrice, false, err
+				return *existingPrice, false, translateSaveError(err)
 			}
+			events.Publish(models.PriceEventSubmitted, *existingPrice, &previous, username)
 			return *existingPrice, true, nil
 		}
 	} else {
@@ -312,11 +404,18 @@ func ProcessPrice(price models.Price, existingPrice *models.Price, isAdmin bool,
 		if price.OutputImageTokens != nil && *price.OutputImageTokens < 0 {
 			return price, false, fmt.Errorf("输出图片价格不能为负数")
 		}
+		if price.RequestPrice != nil && *price.RequestPrice < 0 {
+			return price, false, fmt.Errorf("每次请求费用不能为负数")
+		}
+		if price.ImagePrice != nil && *price.ImagePrice < 0 {
+			return price, false, fmt.Errorf("每张图片价格不能为负数")
+		}
 
-		// 保存新记录
+		// 保存新记录，并发写入同名模型时由唯一索引拒绝
 		if err := database.DB.Create(&price).Error; err != nil {
-			return price, false, err
+			return price, false, translateSaveError(err)
 		}
+		events.Publish(models.PriceEventCreated, price, nil, username)
 		return price, true, nil
 	}
 }
@@ -328,6 +427,24 @@ func CreatePrice(c *gin.Context) {
 		return
 	}
 
+	// 验证阶梯价格
+	if err := price.Tiers.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// 验证价格调整规则
+	if err := price.Modifiers.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// 验证计费单位
+	if err := models.ValidateBillingUnit(price.BillingUnit, price.UnitMeta); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
 	// 验证模型厂商ID是否存在
 	var provider models.Provider
 	if err := database.DB.Where("id = ?", price.ChannelType).First(&provider).Error; err != nil {
@@ -343,7 +460,7 @@ func CreatePrice(c *gin.Context) {
 		return
 	}
 	if count > 0 {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Model with the same name already exists for this provider"})
+		c.JSON(http.StatusConflict, gin.H{"error": "Model with the same name already exists for this provider"})
 		return
 	}
 
@@ -358,6 +475,10 @@ func CreatePrice(c *gin.Context) {
 	// 处理价格创建 - t4或admin用户创建的价格自动审核通过
 	isModerator := middleware.IsModerator(currentUser)
 	result, changed, err := ProcessPrice(price, nil, isModerator, currentUser.Username)
+	if errors.Is(err, ErrDuplicatePrice) {
+		c.JSON(http.StatusConflict, gin.H{"error": "Model with the same name already exists for this provider"})
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create price"})
 		return
@@ -395,8 +516,9 @@ func UpdatePriceStatus(c *gin.Context) {
 	if input.Status == "approved" {
 		// 如果是批准，将临时字段的值更新到正式字段
 		updateMap := map[string]interface{}{
-			"status":     input.Status,
-			"updated_at": time.Now(),
+			"status":      input.Status,
+			"delisted_at": nil,
+			"updated_at":  time.Now(),
 		}
 
 		// 如果临时字段有值，则更新主字段
@@ -406,6 +528,12 @@ func UpdatePriceStatus(c *gin.Context) {
 		if price.TempBillingType != nil {
 			updateMap["billing_type"] = *price.TempBillingType
 		}
+		if price.TempBillingUnit != nil {
+			updateMap["billing_unit"] = *price.TempBillingUnit
+		}
+		if price.TempUnitMeta != nil {
+			updateMap["unit_meta"] = price.TempUnitMeta
+		}
 		if price.TempChannelType != nil {
 			updateMap["channel_type"] = *price.TempChannelType
 		}
@@ -448,6 +576,18 @@ func UpdatePriceStatus(c *gin.Context) {
 		if price.TempOutputImageTokens != nil {
 			updateMap["output_image_tokens"] = *price.TempOutputImageTokens
 		}
+		if price.TempRequestPrice != nil {
+			updateMap["request_price"] = *price.TempRequestPrice
+		}
+		if price.TempImagePrice != nil {
+			updateMap["image_price"] = *price.TempImagePrice
+		}
+		if price.TempTiers != nil {
+			updateMap["tiers"] = price.TempTiers
+		}
+		if price.TempModifiers != nil {
+			updateMap["modifiers"] = price.TempModifiers
+		}
 		if price.TempPriceSource != nil {
 			updateMap["price_source"] = *price.TempPriceSource
 		}
@@ -455,6 +595,8 @@ func UpdatePriceStatus(c *gin.Context) {
 		// 清除所有临时字段
 		updateMap["temp_model"] = nil
 		updateMap["temp_billing_type"] = nil
+		updateMap["temp_billing_unit"] = nil
+		updateMap["temp_unit_meta"] = nil
 		updateMap["temp_channel_type"] = nil
 		updateMap["temp_currency"] = nil
 		updateMap["temp_input_price"] = nil
@@ -469,10 +611,18 @@ func UpdatePriceStatus(c *gin.Context) {
 		updateMap["temp_output_text_tokens"] = nil
 		updateMap["temp_input_image_tokens"] = nil
 		updateMap["temp_output_image_tokens"] = nil
+		updateMap["temp_request_price"] = nil
+		updateMap["temp_image_price"] = nil
+		updateMap["temp_tiers"] = nil
+		updateMap["temp_modifiers"] = nil
 		updateMap["temp_price_source"] = nil
 
 		if err := tx.Model(&price).Updates(updateMap).Error; err != nil {
 			tx.Rollback()
+			if errors.Is(err, gorm.ErrDuplicatedKey) {
+				c.JSON(http.StatusConflict, gin.H{"error": "Model with the same name already exists for this provider"})
+				return
+			}
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update price status"})
 			return
 		}
@@ -495,6 +645,8 @@ func UpdatePriceStatus(c *gin.Context) {
 				"updated_at":       time.Now(),
 				"temp_model":       nil,
 				"temp_billing_type": nil,
+				"temp_billing_unit":        nil,
+				"temp_unit_meta":           nil,
 				"temp_channel_type":        nil,
 				"temp_currency":            nil,
 				"temp_input_price":         nil,
@@ -509,6 +661,10 @@ func UpdatePriceStatus(c *gin.Context) {
 				"temp_output_text_tokens":  nil,
 				"temp_input_image_tokens":  nil,
 				"temp_output_image_tokens": nil,
+				"temp_request_price":       nil,
+				"temp_image_price":         nil,
+				"temp_tiers":               nil,
+				"temp_modifiers":           nil,
 				"temp_price_source":        nil,
 				"updated_by":               nil,
 			}).Error; err != nil {
@@ -529,6 +685,12 @@ func UpdatePriceStatus(c *gin.Context) {
 	// 清除所有价格相关缓存
 	clearPriceCache()
 
+	if input.Status == "approved" {
+		publishReview(models.PriceEventApproved, price, currentUsername(c))
+	} else {
+		publishReview(models.PriceEventRejected, price, currentUsername(c))
+	}
+
 	// 根据操作类型返回不同的消息
 	if input.Status == "rejected" && (price.Model == "" || (price.TempModel != nil && price.Model == *price.TempModel)) {
 		c.JSON(http.StatusOK, gin.H{
@@ -553,6 +715,24 @@ func UpdatePrice(c *gin.Context) {
 		return
 	}
 
+	// 验证阶梯价格
+	if err := price.Tiers.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// 验证价格调整规则
+	if err := price.Modifiers.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// 验证计费单位
+	if err := models.ValidateBillingUnit(price.BillingUnit, price.UnitMeta); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
 	// 验证模型厂商ID是否存在
 	var provider models.Provider
 	if err := database.DB.Where("id = ?", price.ChannelType).First(&provider).Error; err != nil {
@@ -568,7 +748,7 @@ func UpdatePrice(c *gin.Context) {
 		return
 	}
 	if count > 0 {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Model with the same name already exists for this provider"})
+		c.JSON(http.StatusConflict, gin.H{"error": "Model with the same name already exists for this provider"})
 		return
 	}
 
@@ -590,6 +770,10 @@ func UpdatePrice(c *gin.Context) {
 	// 处理价格更新 - t4或admin用户更新的价格自动审核通过
 	isModerator := middleware.IsModerator(currentUser)
 	result, changed, err := ProcessPrice(price, &existingPrice, isModerator, currentUser.Username)
+	if errors.Is(err, ErrDuplicatePrice) {
+		c.JSON(http.StatusConflict, gin.H{"error": "Model with the same name already exists for this provider"})
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update price"})
 		return
@@ -621,6 +805,7 @@ func DeletePrice(c *gin.Context) {
 
 	// 清除所有价格相关缓存
 	clearPriceCache()
+	events.Publish(models.PriceEventDeleted, price, nil, currentUsername(c))
 
 	c.JSON(http.StatusOK, gin.H{"message": "Price deleted successfully"})
 }
@@ -652,8 +837,9 @@ func ApproveAllPrices(c *gin.Context) {
 		if input.Action == "approve" {
 			// 批准操作
 			updateMap := map[string]interface{}{
-				"status":     "approved",
-				"updated_at": time.Now(),
+				"status":      "approved",
+				"delisted_at": nil,
+				"updated_at":  time.Now(),
 			}
 
 			// 如果临时字段有值，则更新主字段
@@ -663,6 +849,12 @@ func ApproveAllPrices(c *gin.Context) {
 			if price.TempBillingType != nil {
 				updateMap["billing_type"] = *price.TempBillingType
 			}
+			if price.TempBillingUnit != nil {
+				updateMap["billing_unit"] = *price.TempBillingUnit
+			}
+			if price.TempUnitMeta != nil {
+				updateMap["unit_meta"] = price.TempUnitMeta
+			}
 			if price.TempChannelType != nil {
 				updateMap["channel_type"] = *price.TempChannelType
 			}
@@ -705,6 +897,18 @@ func ApproveAllPrices(c *gin.Context) {
 			if price.TempOutputImageTokens != nil {
 				updateMap["output_image_tokens"] = *price.TempOutputImageTokens
 			}
+			if price.TempRequestPrice != nil {
+				updateMap["request_price"] = *price.TempRequestPrice
+			}
+			if price.TempImagePrice != nil {
+				updateMap["image_price"] = *price.TempImagePrice
+			}
+			if price.TempTiers != nil {
+				updateMap["tiers"] = price.TempTiers
+			}
+			if price.TempModifiers != nil {
+				updateMap["modifiers"] = price.TempModifiers
+			}
 			if price.TempPriceSource != nil {
 				updateMap["price_source"] = *price.TempPriceSource
 			}
@@ -712,6 +916,8 @@ func ApproveAllPrices(c *gin.Context) {
 			// 清除所有临时字段
 			updateMap["temp_model"] = nil
 			updateMap["temp_billing_type"] = nil
+			updateMap["temp_billing_unit"] = nil
+			updateMap["temp_unit_meta"] = nil
 			updateMap["temp_channel_type"] = nil
 			updateMap["temp_currency"] = nil
 			updateMap["temp_input_price"] = nil
@@ -726,10 +932,18 @@ func ApproveAllPrices(c *gin.Context) {
 			updateMap["temp_output_text_tokens"] = nil
 			updateMap["temp_input_image_tokens"] = nil
 			updateMap["temp_output_image_tokens"] = nil
+			updateMap["temp_request_price"] = nil
+			updateMap["temp_image_price"] = nil
+			updateMap["temp_tiers"] = nil
+			updateMap["temp_modifiers"] = nil
 			updateMap["temp_price_source"] = nil
 
 			if err := tx.Model(&price).Updates(updateMap).Error; err != nil {
 				tx.Rollback()
+				if errors.Is(err, gorm.ErrDuplicatedKey) {
+					c.JSON(http.StatusConflict, gin.H{"error": fmt.Sprintf("Model %s already exists for this provider", price.Model)})
+					return
+				}
 				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to approve prices"})
 				return
 			}
@@ -754,6 +968,8 @@ func ApproveAllPrices(c *gin.Context) {
 					"updated_at":       time.Now(),
 					"temp_model":       nil,
 					"temp_billing_type": nil,
+					"temp_billing_unit":        nil,
+					"temp_unit_meta":           nil,
 					"temp_channel_type":        nil,
 					"temp_currency":            nil,
 					"temp_input_price":         nil,
@@ -768,6 +984,10 @@ func ApproveAllPrices(c *gin.Context) {
 					"temp_output_text_tokens":  nil,
 					"temp_input_image_tokens":  nil,
 					"temp_output_image_tokens": nil,
+					"temp_request_price":       nil,
+					"temp_image_price":         nil,
+					"temp_tiers":               nil,
+					"temp_modifiers":           nil,
 					"temp_price_source":        nil,
 					"updated_by":               nil,
 				}).Error; err != nil {
@@ -790,6 +1010,15 @@ func ApproveAllPrices(c *gin.Context) {
 	// 清除所有价格相关缓存
 	clearPriceCache()
 
+	eventType := models.PriceEventApproved
+	if input.Action == "reject" {
+		eventType = models.PriceEventRejected
+	}
+	username := currentUsername(c)
+	for _, price := range pendingPrices {
+		publishReview(eventType, price, username)
+	}
+
 	// 根据操作类型返回不同的消息
 	if input.Action == "approve" {
 		c.JSON(http.StatusOK, gin.H{
@@ -806,6 +1035,16 @@ func ApproveAllPrices(c *gin.Context) {
 	}
 }
 
+// publishReview 发布审核事件，审核后重新读取价格，被拒绝删除的新价格使用删除前的记录
+// price 为审核前的记录，作为事件的修改前价格
+func publishReview(eventType string, price models.Price, username string) {
+	var reviewed models.Price
+	if err := database.DB.First(&reviewed, price.ID).Error; err != nil {
+		reviewed = price
+	}
+	events.Publish(eventType, reviewed, &price, username)
+}
+
 // clearPriceCache 清除所有价格相关的缓存
 func clearPriceCache() {
 	// 由于我们无法精确知道哪些缓存键与价格相关，所以清除所有缓存
@@ -0,0 +1,147 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"aimodels-prices/database"
+	"aimodels-prices/models"
+)
+
+// GetProviderMappings 获取聚合来源的供应商映射，可按 source、status 过滤
+func GetProviderMappings(c *gin.Context) {
+	query := database.DB.Model(&models.ProviderMapping{})
+	if source := c.Query("source"); source != "" {
+		query = query.Where("source = ?", source)
+	}
+	if status := c.Query("status"); status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	var mappings []models.ProviderMapping
+	if err := query.Order("source, name").Find(&mappings).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch provider mappings"})
+		return
+	}
+
+	c.JSON(http.StatusOK, mappings)
+}
+
+type providerMappingInput struct {
+	Source     string `json:"source"`
+	Name       string `json:"name"`
+	ProviderID *uint  `json:"provider_id"`
+	Status     string `json:"status" binding:"omitempty,oneof=pending approved ignored"`
+}
+
+// CreateProviderMapping 预先配置供应商映射
+func CreateProviderMapping(c *gin.Context) {
+	var input providerMappingInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if input.Source == "" || input.Name == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Source and name are required"})
+		return
+	}
+
+	mapping := models.ProviderMapping{
+		Source:     input.Source,
+		Name:       input.Name,
+		ProviderID: input.ProviderID,
+		Status:     input.Status,
+		CreatedBy:  currentUsername(c),
+	}
+	if mapping.Status == "" {
+		mapping.Status = models.ProviderMappingApproved
+	}
+	if err := validateProviderMapping(mapping); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := database.DB.Create(&mapping).Error; err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			c.JSON(http.StatusConflict, gin.H{"error": "Provider mapping already exists"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create provider mapping"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, mapping)
+}
+
+// UpdateProviderMapping 指定供应商映射的厂商ID或状态
+func UpdateProviderMapping(c *gin.Context) {
+	var mapping models.ProviderMapping
+	if err := database.DB.First(&mapping, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Provider mapping not found"})
+		return
+	}
+
+	var input providerMappingInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	mapping.ProviderID = input.ProviderID
+	switch {
+	case input.Status != "":
+		mapping.Status = input.Status
+	case input.ProviderID != nil:
+		// 只指定了厂商ID时视为确认映射
+		mapping.Status = models.ProviderMappingApproved
+	}
+	if err := validateProviderMapping(mapping); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	username := currentUsername(c)
+	mapping.UpdatedBy = &username
+	if err := database.DB.Save(&mapping).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update provider mapping"})
+		return
+	}
+
+	c.JSON(http.StatusOK, mapping)
+}
+
+// DeleteProviderMapping 删除供应商映射，下次抓取时重新登记为待映射
+func DeleteProviderMapping(c *gin.Context) {
+	result := database.DB.Delete(&models.ProviderMapping{}, c.Param("id"))
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete provider mapping"})
+		return
+	}
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Provider mapping not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Provider mapping deleted successfully"})
+}
+
+// validateProviderMapping 已映射的记录必须指定存在的厂商
+func validateProviderMapping(mapping models.ProviderMapping) error {
+	if mapping.Status != models.ProviderMappingApproved {
+		return nil
+	}
+	if mapping.ProviderID == nil {
+		return errors.New("Provider ID is required for approved mappings")
+	}
+	var count int64
+	if err := database.DB.Model(&models.Provider{}).Where("id = ?", *mapping.ProviderID).Count(&count).Error; err != nil {
+		return err
+	}
+	if count == 0 {
+		return errors.New("Provider not found")
+	}
+	return nil
+}
@@ -0,0 +1,151 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"aimodels-prices/events"
+)
+
+// MaxStreamClients 单个实例同时保持的实时推送连接数上限
+const MaxStreamClients = 500
+
+const (
+	streamBatchSize     = 100
+	streamPingInterval  = 30 * time.Second
+	streamRetryInterval = 3000 // 断线后客户端重连间隔，毫秒
+)
+
+var streamClients atomic.Int64
+
+// StreamPriceEvents 以Server-Sent Events推送价格变更事件
+// 支持通过 Last-Event-ID 请求头或 last_event_id 参数从指定事件之后继续推送，可回放的事件受保留天数限制
+// 过滤参数：type、channel_type、model，多个值用逗号分隔，model 支持 gpt-4* 前缀匹配
+func StreamPriceEvents(c *gin.Context) {
+	filter, err := parseEventFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	lastID, err := lastEventID(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid Last-Event-ID"})
+		return
+	}
+	if lastID == 0 {
+		// 未指定时只推送连接之后的新事件
+		if lastID, err = events.LatestID(); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch events"})
+			return
+		}
+	}
+
+	if streamClients.Add(1) > MaxStreamClients {
+		streamClients.Add(-1)
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Too many stream clients"})
+		return
+	}
+	defer streamClients.Add(-1)
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+	fmt.Fprintf(c.Writer, "retry: %d\n\n", streamRetryInterval)
+	c.Writer.Flush()
+
+	ping := time.NewTicker(streamPingInterval)
+	defer ping.Stop()
+
+	for {
+		// 新事件从本实例共享的缓存读取，积压的事件读取完之前不等待
+		list, changed, err := events.Next(lastID, streamBatchSize)
+		if err != nil {
+			log.Printf("读取价格变更事件失败: %v", err)
+			return
+		}
+		for _, event := range list {
+			lastID = event.ID
+			if !filter.Match(event) {
+				continue
+			}
+			data, err := json.Marshal(event)
+			if err != nil {
+				continue
+			}
+			if _, err := fmt.Fprintf(c.Writer, "id: %d\nevent: %s\ndata: %s\n\n", event.ID, event.Type, data); err != nil {
+				return
+			}
+		}
+		if len(list) > 0 {
+			c.Writer.Flush()
+			continue
+		}
+		// 缓存之前的事件已被清理时稍后重试
+		var retry <-chan time.Time
+		if changed == nil {
+			retry = time.After(time.Second)
+		}
+
+		select {
+		case <-c.Request.Context().Done():
+			return
+		case <-changed:
+		case <-retry:
+		case <-ping.C:
+			if _, err := fmt.Fprint(c.Writer, ": ping\n\n"); err != nil {
+				return
+			}
+			c.Writer.Flush()
+		}
+	}
+}
+
+// lastEventID 读取断线重连时的最后事件ID，未指定时返回0
+func lastEventID(c *gin.Context) (uint, error) {
+	value := c.GetHeader("Last-Event-ID")
+	if value == "" {
+		value = c.Query("last_event_id")
+	}
+	if value == "" {
+		return 0, nil
+	}
+	id, err := strconv.ParseUint(value, 10, 64)
+	return uint(id), err
+}
+
+// parseEventFilter 从查询参数解析事件过滤条件
+func parseEventFilter(c *gin.Context) (events.Filter, error) {
+	var filter events.Filter
+	filter.Types = splitQuery(c.Query("type"))
+	filter.Models = splitQuery(c.Query("model"))
+	for _, value := range splitQuery(c.Query("channel_type")) {
+		channelType, err := strconv.ParseUint(value, 10, 64)
+		if err != nil {
+			return filter, fmt.Errorf("Invalid channel_type: %s", value)
+		}
+		filter.ChannelTypes = append(filter.ChannelTypes, uint(channelType))
+	}
+	return filter, nil
+}
+
+// splitQuery 拆分逗号分隔的查询参数，忽略空值
+func splitQuery(value string) []string {
+	var list []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
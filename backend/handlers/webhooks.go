@@ -0,0 +1,257 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"aimodels-prices/database"
+	"aimodels-prices/middleware"
+	"aimodels-prices/models"
+	"aimodels-prices/webhooks"
+)
+
+// MaxWebhooksPerUser 普通用户最多创建的订阅数量
+const MaxWebhooksPerUser = 10
+
+type webhookInput struct {
+	URL          string            `json:"url" binding:"required"`
+	ChannelTypes models.UintList   `json:"channel_types"`
+	Models       models.StringList `json:"models"`
+	EventTypes   models.StringList `json:"event_types"`
+	Enabled      *bool             `json:"enabled"` // 未指定时默认启用
+}
+
+// GetWebhooks 获取当前用户的Webhook订阅，管理员获取全部订阅
+func GetWebhooks(c *gin.Context) {
+	user := c.MustGet("user").(*models.User)
+
+	query := database.DB.Model(&models.Webhook{})
+	if !middleware.IsAdmin(user) {
+		query = query.Where("user_id = ?", user.ID)
+	}
+
+	var hooks []models.Webhook
+	if err := query.Order("id").Find(&hooks).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch webhooks"})
+		return
+	}
+	for i := range hooks {
+		hooks[i].Secret = ""
+	}
+
+	c.JSON(http.StatusOK, hooks)
+}
+
+// CreateWebhook 创建Webhook订阅，返回的签名密钥只显示这一次
+func CreateWebhook(c *gin.Context) {
+	user := c.MustGet("user").(*models.User)
+
+	var input webhookInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := validateWebhook(input, user); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if !middleware.IsAdmin(user) {
+		var count int64
+		if err := database.DB.Model(&models.Webhook{}).Where("user_id = ?", user.ID).Count(&count).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to count webhooks"})
+			return
+		}
+		if count >= MaxWebhooksPerUser {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Too many webhooks"})
+			return
+		}
+	}
+
+	secret, err := webhooks.GenerateSecret()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate secret"})
+		return
+	}
+
+	hook := models.Webhook{
+		URL:          input.URL,
+		Secret:       secret,
+		ChannelTypes: input.ChannelTypes,
+		Models:       input.Models,
+		EventTypes:   input.EventTypes,
+		Enabled:      input.Enabled == nil || *input.Enabled,
+		UserID:       user.ID,
+		CreatedBy:    user.Username,
+	}
+	if err := database.DB.Create(&hook).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create webhook"})
+		return
+	}
+	webhooks.ClearCache()
+
+	c.JSON(http.StatusCreated, hook)
+}
+
+// UpdateWebhook 修改Webhook订阅的地址、过滤条件或启用状态
+func UpdateWebhook(c *gin.Context) {
+	user := c.MustGet("user").(*models.User)
+	hook, ok := findWebhook(c, user)
+	if !ok {
+		return
+	}
+
+	var input webhookInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := validateWebhook(input, user); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	hook.URL = input.URL
+	hook.ChannelTypes = input.ChannelTypes
+	hook.Models = input.Models
+	hook.EventTypes = input.EventTypes
+	if input.Enabled != nil {
+		hook.Enabled = *input.Enabled
+	}
+	if err := database.DB.Save(&hook).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update webhook"})
+		return
+	}
+	webhooks.ClearCache()
+
+	hook.Secret = ""
+	c.JSON(http.StatusOK, hook)
+}
+
+// DeleteWebhook 删除Webhook订阅及其投递记录
+func DeleteWebhook(c *gin.Context) {
+	user := c.MustGet("user").(*models.User)
+	hook, ok := findWebhook(c, user)
+	if !ok {
+		return
+	}
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("webhook_id = ?", hook.ID).Delete(&models.WebhookDelivery{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&hook).Error
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete webhook"})
+		return
+	}
+	webhooks.ClearCache()
+
+	c.JSON(http.StatusOK, gin.H{"message": "Webhook deleted successfully"})
+}
+
+// GetWebhookDeliveries 分页获取Webhook的投递记录，status=dead 可查看死信
+func GetWebhookDeliveries(c *gin.Context) {
+	user := c.MustGet("user").(*models.User)
+	hook, ok := findWebhook(c, user)
+	if !ok {
+		return
+	}
+
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("pageSize", "20"))
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 20
+	}
+
+	query := database.DB.Model(&models.WebhookDelivery{}).Where("webhook_id = ?", hook.ID)
+	if status := c.Query("status"); status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to count deliveries"})
+		return
+	}
+
+	var deliveries []models.WebhookDelivery
+	if err := query.Order("id DESC").Limit(pageSize).Offset((page - 1) * pageSize).Find(&deliveries).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch deliveries"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"total": total,
+		"data":  deliveries,
+	})
+}
+
+// RedeliverWebhook 重新投递一条记录，通常用于处理死信
+func RedeliverWebhook(c *gin.Context) {
+	user := c.MustGet("user").(*models.User)
+	hook, ok := findWebhook(c, user)
+	if !ok {
+		return
+	}
+
+	var delivery models.WebhookDelivery
+	if err := database.DB.Where("id = ? AND webhook_id = ?", c.Param("delivery_id"), hook.ID).First(&delivery).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Delivery not found"})
+		return
+	}
+	if delivery.Status == models.WebhookDeliverySending {
+		c.JSON(http.StatusConflict, gin.H{"error": "Delivery is in progress"})
+		return
+	}
+
+	if err := webhooks.Redeliver(delivery); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to redeliver"})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{"message": "Redelivery scheduled"})
+}
+
+// findWebhook 查找当前用户可管理的订阅，未找到时直接返回404
+func findWebhook(c *gin.Context, user *models.User) (models.Webhook, bool) {
+	var hook models.Webhook
+	err := database.DB.First(&hook, c.Param("id")).Error
+	if err == nil && (hook.UserID == user.ID || middleware.IsAdmin(user)) {
+		return hook, true
+	}
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch webhook"})
+		return hook, false
+	}
+	c.JSON(http.StatusNotFound, gin.H{"error": "Webhook not found"})
+	return hook, false
+}
+
+// validateWebhook 校验推送地址和过滤条件，普通用户不能推送到内网地址
+func validateWebhook(input webhookInput, user *models.User) error {
+	if err := webhooks.ValidateURL(input.URL, middleware.IsAdmin(user)); err != nil {
+		return err
+	}
+	for _, eventType := range input.EventTypes {
+		valid := false
+		for _, known := range models.PriceEventTypes {
+			if eventType == known {
+				valid = true
+				break
+			}
+		}
+		if !valid {
+			return errors.New("Unknown event type: " + eventType)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,230 @@
+package httpclient
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math/rand"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// ErrBodyTooLarge 响应体超过大小限制
+var ErrBodyTooLarge = errors.New("响应内容超过大小限制")
+
+// ErrPrivateAddress 目标为本机或内网地址，由 DenyPrivate 拒绝
+var ErrPrivateAddress = errors.New("不允许访问内网地址")
+
+// StatusError 非2xx响应
+type StatusError struct {
+	StatusCode int
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("返回状态码: %d", e.StatusCode)
+}
+
+// Client 带超时、重试、代理和响应大小限制的HTTP客户端
+type Client struct {
+	name   string
+	cfg    Config
+	client *http.Client
+}
+
+// New 按配置创建客户端，name 用于日志
+func New(name string, cfg Config) (*Client, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if cfg.Proxy != "" {
+		proxyURL, err := url.Parse(cfg.Proxy)
+		if err != nil {
+			return nil, fmt.Errorf("无效的代理地址 %s: %v", cfg.Proxy, err)
+		}
+		switch proxyURL.Scheme {
+		case "http", "https", "socks5", "socks5h":
+		default:
+			return nil, fmt.Errorf("不支持的代理协议: %s", proxyURL.Scheme)
+		}
+		transport.Proxy = http.ProxyURL(proxyURL)
+	}
+	if cfg.DenyPrivate {
+		// 在建立连接时检查实际连接的IP，DNS重绑定和重定向都无法绕过；经代理连接时无法检查目标地址
+		transport.Proxy = nil
+		dialer := &net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second, Control: denyPrivate}
+		transport.DialContext = dialer.DialContext
+	}
+
+	client := &http.Client{
+		Timeout:   cfg.Timeout,
+		Transport: transport,
+	}
+	if cfg.NoRedirect {
+		client.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		}
+	}
+
+	return &Client{name: name, cfg: cfg, client: client}, nil
+}
+
+// IsPrivateIP 判断是否为本机、内网、链路本地等不应从外部请求访问的地址
+func IsPrivateIP(ip net.IP) bool {
+	return ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsMulticast() || ip.IsUnspecified()
+}
+
+// denyPrivate 作为 net.Dialer.Control，拒绝连接内网地址
+func denyPrivate(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	ip := net.ParseIP(host)
+	if ip == nil || IsPrivateIP(ip) {
+		return ErrPrivateAddress
+	}
+	return nil
+}
+
+var clients sync.Map
+
+// For 返回指定来源的客户端，配置从环境变量读取，同一来源复用同一客户端
+func For(name string) *Client {
+	if c, ok := clients.Load(name); ok {
+		return c.(*Client)
+	}
+
+	cfg := LoadConfig(name)
+	c, err := New(name, cfg)
+	if err != nil {
+		log.Printf("%s HTTP客户端配置错误，忽略代理设置: %v", name, err)
+		cfg.Proxy = ""
+		c, _ = New(name, cfg)
+	}
+
+	actual, _ := clients.LoadOrStore(name, c)
+	return actual.(*Client)
+}
+
+// Do 发送请求，网络错误、429和5xx响应按指数退避加随机抖动重试
+// 带请求体的请求只有在可重放（设置了GetBody）时才会重试
+func (c *Client) Do(req *http.Request) (*http.Response, error) {
+	if req.Header.Get("User-Agent") == "" && c.cfg.UserAgent != "" {
+		req.Header.Set("User-Agent", c.cfg.UserAgent)
+	}
+	replayable := req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+
+	for attempt := 0; ; attempt++ {
+		if attempt > 0 && req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			req.Body = body
+		}
+
+		resp, err := c.client.Do(req)
+		if !shouldRetry(resp, err) || attempt >= c.cfg.MaxRetries || !replayable || req.Context().Err() != nil {
+			if err != nil {
+				return nil, err
+			}
+			if c.cfg.MaxBodySize > 0 {
+				resp.Body = &limitedBody{ReadCloser: resp.Body, remaining: c.cfg.MaxBodySize}
+			}
+			return resp, nil
+		}
+
+		wait := c.backoff(attempt, resp)
+		if err != nil {
+			log.Printf("%s 请求失败，%v 后重试 (%d/%d): %v", c.name, wait, attempt+1, c.cfg.MaxRetries, err)
+		} else {
+			log.Printf("%s 请求返回状态码 %d，%v 后重试 (%d/%d)", c.name, resp.StatusCode, wait, attempt+1, c.cfg.MaxRetries)
+			io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+			resp.Body.Close()
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-req.Context().Done():
+			timer.Stop()
+			return nil, req.Context().Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// Fetch 发送请求并读取完整响应体，非2xx响应返回 *StatusError
+func (c *Client) Fetch(req *http.Request) ([]byte, error) {
+	resp, err := c.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, &StatusError{StatusCode: resp.StatusCode}
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("读取响应内容失败: %w", err)
+	}
+	return body, nil
+}
+
+func shouldRetry(resp *http.Response, err error) bool {
+	if err != nil {
+		// 被拒绝的内网地址重试也不会成功
+		return !errors.Is(err, ErrPrivateAddress)
+	}
+	return resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+}
+
+// backoff 计算第 attempt 次重试前的等待时间，优先使用响应的 Retry-After
+func (c *Client) backoff(attempt int, resp *http.Response) time.Duration {
+	if resp != nil {
+		if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && seconds >= 0 {
+			return min(time.Duration(seconds)*time.Second, c.cfg.MaxRetryWait)
+		}
+	}
+
+	wait := c.cfg.RetryWait << attempt
+	if wait <= 0 || wait > c.cfg.MaxRetryWait {
+		wait = c.cfg.MaxRetryWait
+	}
+	// 在 [wait/2, wait] 之间随机，避免多个请求同时重试
+	half := wait / 2
+	if half <= 0 {
+		return wait
+	}
+	return half + time.Duration(rand.Int63n(int64(half)+1))
+}
+
+// limitedBody 超过大小限制时返回 ErrBodyTooLarge
+type limitedBody struct {
+	io.ReadCloser
+	remaining int64
+}
+
+func (b *limitedBody) Read(p []byte) (int, error) {
+	if b.remaining <= 0 {
+		// 多读一个字节判断是否还有剩余内容
+		var probe [1]byte
+		n, err := b.ReadCloser.Read(probe[:])
+		if n > 0 {
+			return 0, ErrBodyTooLarge
+		}
+		return 0, err
+	}
+	if int64(len(p)) > b.remaining {
+		p = p[:b.remaining]
+	}
+	n, err := b.ReadCloser.Read(p)
+	b.remaining -= int64(n)
+	return n, err
+}
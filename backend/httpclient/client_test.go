@@ -0,0 +1,121 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func testConfig() Config {
+	cfg := DefaultConfig()
+	cfg.RetryWait = time.Millisecond
+	cfg.MaxRetryWait = 5 * time.Millisecond
+	return cfg
+}
+
+func TestRetry(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("User-Agent") != DefaultUserAgent {
+			t.Errorf("User-Agent 错误: %s", r.Header.Get("User-Agent"))
+		}
+		if calls.Add(1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	client, err := New("test", testConfig())
+	if err != nil {
+		t.Fatal(err)
+	}
+	req, _ := http.NewRequestWithContext(context.Background(), "GET", server.URL, nil)
+	body, err := client.Fetch(req)
+	if err != nil {
+		t.Fatalf("请求失败: %v", err)
+	}
+	if string(body) != "ok" || calls.Load() != 3 {
+		t.Errorf("响应 %q，请求次数 %d", body, calls.Load())
+	}
+
+	// 超过重试次数后返回最后一次的状态码
+	calls.Store(-10)
+	req, _ = http.NewRequestWithContext(context.Background(), "GET", server.URL, nil)
+	var statusErr *StatusError
+	if _, err := client.Fetch(req); !errors.As(err, &statusErr) || statusErr.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("应返回状态码错误: %v", err)
+	}
+	if calls.Load() != -6 {
+		t.Errorf("请求次数错误: %d", calls.Load()+10)
+	}
+}
+
+func TestMaxBodySize(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(strings.Repeat("a", 100)))
+	}))
+	defer server.Close()
+
+	cfg := testConfig()
+	cfg.MaxBodySize = 10
+	client, _ := New("test", cfg)
+	req, _ := http.NewRequest("GET", server.URL, nil)
+	if _, err := client.Fetch(req); !errors.Is(err, ErrBodyTooLarge) {
+		t.Errorf("应返回超过大小限制错误: %v", err)
+	}
+
+	cfg.MaxBodySize = 100
+	client, _ = New("test", cfg)
+	req, _ = http.NewRequest("GET", server.URL, nil)
+	if body, err := client.Fetch(req); err != nil || len(body) != 100 {
+		t.Errorf("恰好等于限制时应成功: %d, %v", len(body), err)
+	}
+}
+
+func TestDenyPrivate(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "http://127.0.0.1:1/", http.StatusFound)
+	}))
+	defer server.Close()
+
+	cfg := testConfig()
+	cfg.MaxRetries = 0
+	cfg.NoRedirect = true
+	client, _ := New("test", cfg)
+	req, _ := http.NewRequest("GET", server.URL, nil)
+	var statusErr *StatusError
+	if _, err := client.Fetch(req); !errors.As(err, &statusErr) || statusErr.StatusCode != http.StatusFound {
+		t.Errorf("不跟随重定向时应返回3xx状态码: %v", err)
+	}
+
+	cfg.DenyPrivate = true
+	client, _ = New("test", cfg)
+	req, _ = http.NewRequest("GET", server.URL, nil)
+	if _, err := client.Fetch(req); !errors.Is(err, ErrPrivateAddress) {
+		t.Errorf("应拒绝连接本机地址: %v", err)
+	}
+}
+
+func TestLoadConfig(t *testing.T) {
+	t.Setenv("HTTP_CLIENT_TIMEOUT", "10")
+	t.Setenv("HTTP_CLIENT_TIMEOUT_OPENROUTER", "1m")
+	t.Setenv("HTTP_CLIENT_PROXY", "socks5://127.0.0.1:1080")
+
+	cfg := LoadConfig("openrouter")
+	if cfg.Timeout != time.Minute || cfg.Proxy != "socks5://127.0.0.1:1080" {
+		t.Errorf("来源配置错误: %+v", cfg)
+	}
+	if cfg := LoadConfig("openai"); cfg.Timeout != 10*time.Second {
+		t.Errorf("全局配置错误: %+v", cfg)
+	}
+	if _, err := New("test", Config{Proxy: "ftp://127.0.0.1"}); err == nil {
+		t.Error("应拒绝不支持的代理协议")
+	}
+}
@@ -0,0 +1,91 @@
+package httpclient
+
+import (
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultUserAgent 默认User-Agent
+const DefaultUserAgent = "Mozilla/5.0 (compatible; PriceBot/1.0)"
+
+// Config 出站HTTP客户端配置
+type Config struct {
+	Timeout      time.Duration // 单次请求超时，包括读取响应
+	MaxRetries   int           // 网络错误、429和5xx响应的最大重试次数
+	RetryWait    time.Duration // 首次重试的等待时间，之后按指数退避并加入随机抖动
+	MaxRetryWait time.Duration // 单次重试的最长等待时间
+	Proxy        string        // 代理地址，支持 http://、https://、socks5://，为空时使用 HTTP_PROXY/HTTPS_PROXY 环境变量
+	UserAgent    string        // 请求未设置User-Agent时使用
+	MaxBodySize  int64         // 响应体最大字节数，0 表示不限制
+	NoRedirect   bool          // 不跟随重定向，3xx响应按非2xx处理
+	DenyPrivate  bool          // 拒绝连接本机和内网地址，开启后不使用代理
+}
+
+// DefaultConfig 返回默认配置
+func DefaultConfig() Config {
+	return Config{
+		Timeout:      30 * time.Second,
+		MaxRetries:   3,
+		RetryWait:    time.Second,
+		MaxRetryWait: 30 * time.Second,
+		UserAgent:    DefaultUserAgent,
+		MaxBodySize:  20 << 20,
+	}
+}
+
+// LoadConfig 从环境变量读取指定来源的配置
+// 全局配置使用 HTTP_CLIENT_TIMEOUT、HTTP_CLIENT_RETRIES、HTTP_CLIENT_PROXY、HTTP_CLIENT_USER_AGENT、HTTP_CLIENT_MAX_BODY_SIZE，
+// 在变量名后加上来源名称可单独覆盖，例如 HTTP_CLIENT_TIMEOUT_OPENROUTER=60s、HTTP_CLIENT_PROXY_OPENAI=socks5://127.0.0.1:1080
+func LoadConfig(name string) Config {
+	cfg := DefaultConfig()
+
+	suffix := "_" + strings.ToUpper(strings.NewReplacer("-", "_", ".", "_").Replace(name))
+	lookup := func(key string) string {
+		if value := os.Getenv(key + suffix); name != "" && value != "" {
+			return value
+		}
+		return os.Getenv(key)
+	}
+
+	if value := lookup("HTTP_CLIENT_TIMEOUT"); value != "" {
+		if d, ok := parseDuration(value); ok {
+			cfg.Timeout = d
+		} else {
+			log.Printf("无效的HTTP超时配置: %s", value)
+		}
+	}
+	if value := lookup("HTTP_CLIENT_RETRIES"); value != "" {
+		if n, err := strconv.Atoi(value); err == nil && n >= 0 {
+			cfg.MaxRetries = n
+		} else {
+			log.Printf("无效的HTTP重试次数配置: %s", value)
+		}
+	}
+	if value := lookup("HTTP_CLIENT_PROXY"); value != "" {
+		cfg.Proxy = value
+	}
+	if value := lookup("HTTP_CLIENT_USER_AGENT"); value != "" {
+		cfg.UserAgent = value
+	}
+	if value := lookup("HTTP_CLIENT_MAX_BODY_SIZE"); value != "" {
+		if n, err := strconv.ParseInt(value, 10, 64); err == nil && n >= 0 {
+			cfg.MaxBodySize = n
+		} else {
+			log.Printf("无效的HTTP响应大小限制配置: %s", value)
+		}
+	}
+
+	return cfg
+}
+
+// parseDuration 解析时长，支持 "30s" 格式和纯数字秒数
+func parseDuration(value string) (time.Duration, bool) {
+	if n, err := strconv.Atoi(value); err == nil && n > 0 {
+		return time.Duration(n) * time.Second, true
+	}
+	d, err := time.ParseDuration(value)
+	return d, err == nil && d > 0
+}
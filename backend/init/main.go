@@ -2,6 +2,8 @@ package init
 
 import (
 	"log"
+
+	"aimodels-prices/catalog"
 )
 
 // RunInitTasks 运行所有初始化任务
@@ -11,6 +13,16 @@ func RunInitTasks() {
 		log.Printf("检查重复模型名称时发生错误: %v", err)
 	}
 
+	// 清理重复数据后创建唯一索引
+	if err := EnsurePriceUniqueIndex(); err != nil {
+		log.Printf("创建价格唯一索引时发生错误: %v", err)
+	}
+
+	// 写入内置模型目录
+	if err := catalog.Seed(); err != nil {
+		log.Printf("写入内置模型目录时发生错误: %v", err)
+	}
+
 	// 在此处添加其他初始化任务
 	// ...
 }
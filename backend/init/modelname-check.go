@@ -6,7 +6,8 @@ import (
 	"log"
 )
 
-// CheckDuplicateModelNames 检查数据库中是否存在重复的模型名称，如果有则保留最新的
+// CheckDuplicateModelNames 检查数据库中是否存在重复的模型名称（不区分大小写），如果有则保留最新的
+// 创建唯一索引前需要先清理历史重复数据
 func CheckDuplicateModelNames() error {
 	log.Println("开始检查重复的模型名称...")
 	db := database.DB
@@ -22,9 +23,10 @@ func CheckDuplicateModelNames() error {
 	}
 
 	if err := db.Raw(`
-		SELECT channel_type, model, COUNT(*) as count
+		SELECT channel_type, LOWER(model) as model, COUNT(*) as count
 		FROM price
-		GROUP BY channel_type, model
+		WHERE deleted_at IS NULL
+		GROUP BY channel_type, LOWER(model)
 		HAVING COUNT(*) > 1
 	`).Scan(&duplicates).Error; err != nil {
 		return err
@@ -48,7 +50,7 @@ func CheckDuplicateModelNames() error {
 	for _, dup := range duplicates {
 		// 查找具有相同厂商ID和模型名称的所有记录
 		var prices []models.Price
-		if err := tx.Where("channel_type = ? AND model = ?", dup.ChannelType, dup.Model).Order("updated_at DESC").Find(&prices).Error; err != nil {
+		if err := tx.Where("channel_type = ? AND LOWER(model) = ?", dup.ChannelType, dup.Model).Order("updated_at DESC").Find(&prices).Error; err != nil {
 			tx.Rollback()
 			return err
 		}
@@ -0,0 +1,42 @@
+package init
+
+import (
+	"log"
+
+	"aimodels-prices/database"
+	"aimodels-prices/models"
+)
+
+const (
+	// priceModelKeyColumn 用于唯一约束的生成列：未删除记录为小写模型名称，已软删除记录为NULL
+	priceModelKeyColumn = "model_key"
+	// priceUniqueIndex 同一厂商下模型名称唯一的索引
+	priceUniqueIndex = "idx_price_channel_model_key"
+)
+
+// EnsurePriceUniqueIndex 在数据库层面保证同一厂商下模型名称唯一（不区分大小写，忽略已软删除的记录）
+// MySQL唯一索引允许多个NULL，因此软删除的记录不参与约束
+func EnsurePriceUniqueIndex() error {
+	db := database.DB
+	if db == nil {
+		return nil
+	}
+
+	migrator := db.Migrator()
+	if !migrator.HasColumn(&models.Price{}, priceModelKeyColumn) {
+		log.Println("正在为价格表添加模型唯一键列...")
+		if err := db.Exec(`ALTER TABLE price ADD COLUMN ` + priceModelKeyColumn + ` VARCHAR(191)
+			GENERATED ALWAYS AS (IF(deleted_at IS NULL, LEFT(LOWER(model), 191), NULL)) STORED`).Error; err != nil {
+			return err
+		}
+	}
+
+	if !migrator.HasIndex(&models.Price{}, priceUniqueIndex) {
+		log.Println("正在为价格表创建厂商和模型名称唯一索引...")
+		if err := db.Exec(`CREATE UNIQUE INDEX ` + priceUniqueIndex + ` ON price (channel_type, ` + priceModelKeyColumn + `)`).Error; err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
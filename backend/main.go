@@ -1,6 +1,8 @@
 package main
 
 import (
+	"encoding/json"
+	"flag"
 	"fmt"
 	"log"
 	"net/http"
@@ -12,14 +14,20 @@ import (
 	"aimodels-prices/config"
 	"aimodels-prices/cron"
 	"aimodels-prices/database"
+	"aimodels-prices/feeds"
 	"aimodels-prices/handlers"
+	"aimodels-prices/handlers/jobs"
 	one_hub_handlers "aimodels-prices/handlers/one_hub"
 	initTasks "aimodels-prices/init"
 	"aimodels-prices/middleware"
 	"aimodels-prices/seo"
+	"aimodels-prices/webhooks"
 )
 
 func main() {
+	dryRun := flag.String("dry-run", "", "试运行价格抓取任务并输出将要写入的变化，不修改数据库，all 表示全部来源")
+	flag.Parse()
+
 	// 加载配置
 	cfg, err := config.LoadConfig()
 	if err != nil {
@@ -31,6 +39,20 @@ func main() {
 		log.Fatalf("Failed to initialize database: %v", err)
 	}
 
+	// 试运行模式：输出结果后退出，不启动服务
+	if *dryRun != "" {
+		previews, err := cron.DryRun(*dryRun)
+		if err != nil {
+			log.Fatalf("试运行失败: %v", err)
+		}
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(previews); err != nil {
+			log.Fatalf("输出试运行结果失败: %v", err)
+		}
+		return
+	}
+
 	// 运行初始化任务
 	initTasks.RunInitTasks()
 
@@ -39,6 +61,9 @@ func main() {
 		gin.SetMode(gin.ReleaseMode)
 	}
 
+	// 价格变更事件推送到Webhook订阅
+	webhooks.Start()
+
 	// 初始化并启动定时任务
 	cron.Init()
 	defer cron.StopCronJobs()
@@ -51,7 +76,7 @@ func main() {
 		if origin != "" {
 			c.Writer.Header().Set("Access-Control-Allow-Origin", origin)
 			c.Writer.Header().Set("Access-Control-Allow-Credentials", "true")
-			c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, accept, origin, Cache-Control, X-Requested-With")
+			c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, accept, origin, Cache-Control, X-Requested-With, Last-Event-ID")
 			c.Writer.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS, GET, PUT, DELETE")
 		}
 
@@ -70,6 +95,7 @@ func main() {
 		prices := api.Group("/prices")
 		{
 			prices.GET("", handlers.GetPrices)
+			prices.GET("/stream", handlers.StreamPriceEvents) // 价格变更实时推送(SSE)
 
 			prices.GET("/rates", one_hub_handlers.GetPriceRates) //one_hub 价格倍率, 旧接口
 
@@ -81,6 +107,31 @@ func main() {
 			prices.PUT("/approve-all", middleware.AuthRequired(), middleware.RequireModerator(), handlers.ApproveAllPrices)
 		}
 
+		// 费用计算
+		api.POST("/calculate", handlers.CalculateCost)
+		// 模型价格对比，名称包含/（如 vendor/model）时使用 /api/compare?model=vendor/model
+		api.GET("/compare", handlers.CompareModelPrices)
+
+		// 模型目录相关路由
+		modelsGroup := api.Group("/models")
+		{
+			modelsGroup.GET("", handlers.GetCatalogModels)
+			modelsGroup.GET("/:name", handlers.GetCatalogModel)
+			modelsGroup.GET("/:name/compare", handlers.CompareModelPrices)
+			modelsGroup.GET("/:name/metadata", handlers.GetModelMetadata)
+			modelsGroup.PUT("/:name/metadata", middleware.AuthRequired(), handlers.SubmitModelMetadata)
+			// 审核模型元数据需要t4或admin权限
+			modelsGroup.PUT("/:name/metadata/status", middleware.AuthRequired(), middleware.RequireModerator(), handlers.UpdateModelMetadataStatus)
+			modelsGroup.POST("", middleware.AuthRequired(), middleware.AdminRequired(), handlers.CreateCatalogModel)
+			modelsGroup.PUT("/:name", middleware.AuthRequired(), middleware.AdminRequired(), handlers.UpdateCatalogModel)
+			modelsGroup.DELETE("/:name", middleware.AuthRequired(), middleware.AdminRequired(), handlers.DeleteCatalogModel)
+			modelsGroup.POST("/:name/aliases", middleware.AuthRequired(), middleware.AdminRequired(), handlers.CreateModelAlias)
+			modelsGroup.DELETE("/:name/aliases/:alias_id", middleware.AuthRequired(), middleware.AdminRequired(), handlers.DeleteModelAlias)
+		}
+
+		// 模型元数据列表
+		api.GET("/metadata", handlers.GetMetadataList)
+
 		//one_hub 路由
 		one_hub := api.Group("/one_hub")
 		{
@@ -97,6 +148,39 @@ func main() {
 			providers.DELETE("/:id", middleware.AuthRequired(), middleware.AdminRequired(), handlers.DeleteProvider)
 		}
 
+		// 价格变更Webhook订阅，登录用户管理自己的订阅，管理员可管理全部
+		webhookGroup := api.Group("/webhooks", middleware.AuthRequired())
+		{
+			webhookGroup.GET("", handlers.GetWebhooks)
+			webhookGroup.POST("", handlers.CreateWebhook)
+			webhookGroup.PUT("/:id", handlers.UpdateWebhook)
+			webhookGroup.DELETE("/:id", handlers.DeleteWebhook)
+			webhookGroup.GET("/:id/deliveries", handlers.GetWebhookDeliveries)
+			webhookGroup.POST("/:id/deliveries/:delivery_id/redeliver", handlers.RedeliverWebhook)
+		}
+
+		// 管理员相关路由
+		admin := api.Group("/admin", middleware.AuthRequired(), middleware.AdminRequired())
+		{
+			admin.GET("/jobs", jobs.GetJobs)
+			admin.GET("/jobs/:name/runs", jobs.GetJobRuns)
+			admin.POST("/jobs/:name/run", jobs.RunJob)
+			admin.POST("/jobs/:name/dry-run", jobs.DryRunJob)
+
+			// 管理员配置的价格来源，按CSS选择器或JSONPath解析页面
+			admin.GET("/source-definitions", jobs.GetSourceDefinitions)
+			admin.POST("/source-definitions", jobs.CreateSourceDefinition)
+			admin.POST("/source-definitions/test", jobs.TestSourceDefinition)
+			admin.PUT("/source-definitions/:id", jobs.UpdateSourceDefinition)
+			admin.DELETE("/source-definitions/:id", jobs.DeleteSourceDefinition)
+
+			// 聚合来源的供应商映射
+			admin.GET("/provider-mappings", handlers.GetProviderMappings)
+			admin.POST("/provider-mappings", handlers.CreateProviderMapping)
+			admin.PUT("/provider-mappings/:id", handlers.UpdateProviderMapping)
+			admin.DELETE("/provider-mappings/:id", handlers.DeleteProviderMapping)
+		}
+
 		// 认证相关路由
 		auth := api.Group("/auth")
 		{
@@ -108,6 +192,10 @@ func main() {
 		}
 	}
 
+	// 价格变更订阅源
+	r.GET("/feeds/changes.atom", feeds.Changes)
+	r.GET("/feeds/providers/:id/changes.atom", feeds.ProviderChanges)
+
 	// 静态文件服务 - 支持 SPA
 	staticDir := "./frontend"
 	if _, err := os.Stat(staticDir); err == nil {
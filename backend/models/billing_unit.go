@@ -0,0 +1,128 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+)
+
+// 计费单位，价格为每 UnitMeta.Quantity 个单位的价格
+const (
+	BillingUnitTokens      = "tokens"       // 每百万token，billing_type为tokens
+	BillingUnitRequest     = "request"      // 每次请求
+	BillingUnitAudioSecond = "audio_second" // 每秒音频，如语音识别
+	BillingUnitCharacter   = "character"    // 每字符，如语音合成
+	BillingUnitByte        = "byte"         // 每字节（UTF-8编码），中文一个字为3字节
+	BillingUnitImage       = "image"        // 每张图片，可按分辨率区分
+	BillingUnitVideoSecond = "video_second" // 每秒视频
+	BillingUnitPixelStep   = "pixel_step"   // 每像素·步数，如按分辨率和步数计费的图片生成
+)
+
+var billingUnits = map[string]bool{
+	BillingUnitTokens:      true,
+	BillingUnitRequest:     true,
+	BillingUnitAudioSecond: true,
+	BillingUnitCharacter:   true,
+	BillingUnitByte:        true,
+	BillingUnitImage:       true,
+	BillingUnitVideoSecond: true,
+	BillingUnitPixelStep:   true,
+}
+
+// UnitMeta 计费单位的补充信息
+type UnitMeta struct {
+	Quantity   float64 `json:"quantity,omitempty"`   // 价格对应的单位数量，如1000000表示每百万字符，默认1
+	Resolution string  `json:"resolution,omitempty"` // 图片或视频分辨率，如1024x1024
+	Raw        string  `json:"raw,omitempty"`        // 上游原始单位描述，如"/ M UTF-8 bytes"
+}
+
+// Value 实现 driver.Valuer 接口
+func (u UnitMeta) Value() (driver.Value, error) {
+	data, err := json.Marshal(u)
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+// Scan 实现 sql.Scanner 接口
+func (u *UnitMeta) Scan(value interface{}) error {
+	var data []byte
+	switch v := value.(type) {
+	case nil:
+		return nil
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		return fmt.Errorf("无法解析计费单位信息: %T", value)
+	}
+	if len(data) == 0 {
+		return nil
+	}
+	return json.Unmarshal(data, u)
+}
+
+// Equal 判断两个计费单位信息是否相同
+func (u *UnitMeta) Equal(other *UnitMeta) bool {
+	if u == nil || other == nil {
+		return u == other
+	}
+	return *u == *other
+}
+
+// IsBillingUnit 判断是否是支持的计费单位
+func IsBillingUnit(unit string) bool {
+	return billingUnits[unit]
+}
+
+// BillingTypeForUnit 返回计费单位对应的billing_type，非token单位均按数量计费
+func BillingTypeForUnit(unit string) string {
+	if unit == "" || unit == BillingUnitTokens {
+		return "tokens"
+	}
+	return "times"
+}
+
+// ValidateBillingUnit 校验计费单位及其补充信息，单位为空时沿用billing_type
+func ValidateBillingUnit(unit string, meta *UnitMeta) error {
+	if unit == "" {
+		if meta != nil {
+			return fmt.Errorf("未指定计费单位时不能设置单位信息")
+		}
+		return nil
+	}
+	if !IsBillingUnit(unit) {
+		return fmt.Errorf("未知的计费单位: %s", unit)
+	}
+	if meta == nil {
+		return nil
+	}
+	if meta.Quantity < 0 {
+		return fmt.Errorf("计费单位数量不能为负数")
+	}
+	if meta.Resolution != "" && unit != BillingUnitImage && unit != BillingUnitVideoSecond {
+		return fmt.Errorf("只有图片和视频计费单位可以设置分辨率")
+	}
+	return nil
+}
+
+// Unit 返回价格的计费单位，未设置时由billing_type推断
+func (p Price) Unit() string {
+	if p.BillingUnit != "" {
+		return p.BillingUnit
+	}
+	if p.BillingType == "times" {
+		return BillingUnitRequest
+	}
+	return BillingUnitTokens
+}
+
+// UnitQuantity 返回价格对应的单位数量，未设置时为1
+func (p Price) UnitQuantity() float64 {
+	if p.UnitMeta != nil && p.UnitMeta.Quantity > 0 {
+		return p.UnitMeta.Quantity
+	}
+	return 1
+}
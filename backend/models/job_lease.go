@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+// JobLease 定时任务租约，多个实例中只有持有未过期租约的实例执行任务
+type JobLease struct {
+	Name      string    `json:"name" gorm:"primaryKey;type:varchar(128)"`
+	Holder    string    `json:"holder" gorm:"type:varchar(191)"` // 持有者实例ID
+	ExpiresAt time.Time `json:"expires_at"`
+	UpdatedAt time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// TableName 指定JobLease表名
+func (JobLease) TableName() string {
+	return "job_lease"
+}
@@ -0,0 +1,38 @@
+package models
+
+import (
+	"time"
+)
+
+// Model 规范模型目录，ID为字符串主键，删除时直接删除记录，以便重新创建同一ID
+type Model struct {
+	ID            string       `json:"id" gorm:"primaryKey;type:varchar(191)"` // 规范模型ID，如 claude-3-5-sonnet
+	DisplayName   string       `json:"display_name"`
+	Family        string       `json:"family" gorm:"index"`          // 模型系列，如 claude-3.5
+	Vendor        string       `json:"vendor" gorm:"not null;index"` // 模型研发厂商，如 anthropic
+	VendorChannel *uint        `json:"vendor_channel,omitempty"`     // 官方厂商ID
+	CreatedAt     time.Time    `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt     time.Time    `json:"updated_at" gorm:"autoUpdateTime"`
+	CreatedBy     string       `json:"created_by" gorm:"not null"`
+	Aliases       []ModelAlias `json:"aliases,omitempty" gorm:"foreignKey:ModelID"`
+}
+
+// ModelAlias 厂商内模型名称到规范模型的映射
+type ModelAlias struct {
+	ID          uint      `json:"id" gorm:"primaryKey"`
+	ChannelType uint      `json:"channel_type" gorm:"not null;uniqueIndex:idx_alias_channel"` // 0 表示适用于所有厂商
+	Alias       string    `json:"alias" gorm:"not null;type:varchar(191);uniqueIndex:idx_alias_channel"`
+	ModelID     string    `json:"model_id" gorm:"not null;type:varchar(191);index"`
+	CreatedAt   time.Time `json:"created_at" gorm:"autoCreateTime"`
+	CreatedBy   string    `json:"created_by" gorm:"not null"`
+}
+
+// TableName 指定Model表名
+func (Model) TableName() string {
+	return "model"
+}
+
+// TableName 指定ModelAlias表名
+func (ModelAlias) TableName() string {
+	return "model_alias"
+}
@@ -0,0 +1,40 @@
+package models
+
+import (
+	"time"
+)
+
+// ModelMetadata 模型能力与上下文窗口信息，按模型目录的键存储
+type ModelMetadata struct {
+	ID               uint      `json:"id" gorm:"primaryKey"`
+	ModelID          string    `json:"model_id" gorm:"not null;type:varchar(191);uniqueIndex"` // 规范模型ID，不在目录中时为规范化名称
+	ContextLength    *int      `json:"context_length,omitempty" gorm:"index"`                  // 上下文长度
+	MaxOutputTokens  *int      `json:"max_output_tokens,omitempty"`                            // 最大输出token数
+	InputModalities  *string   `json:"input_modalities,omitempty"`                             // 输入模态，逗号分隔：text,image,audio,video
+	OutputModalities *string   `json:"output_modalities,omitempty"`                            // 输出模态，逗号分隔
+	SupportsTools    *bool     `json:"supports_tools,omitempty"`                               // 是否支持工具调用
+	SupportsJSONMode *bool     `json:"supports_json_mode,omitempty" gorm:"column:supports_json_mode"`
+	SupportsVision   *bool     `json:"supports_vision,omitempty"`                    // 是否支持图片理解
+	KnowledgeCutoff  *string   `json:"knowledge_cutoff,omitempty"`                   // 知识截止日期，如 2024-04
+	Source           string    `json:"source"`                                       // 数据来源
+	Status           string    `json:"status" gorm:"not null;default:pending;index"` // pending, approved
+	CreatedAt        time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt        time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+	CreatedBy        string    `json:"created_by" gorm:"not null"`
+	UpdatedBy        *string   `json:"updated_by,omitempty"`
+	// 临时字段，用于存储待审核的更新
+	TempContextLength    *int    `json:"temp_context_length,omitempty"`
+	TempMaxOutputTokens  *int    `json:"temp_max_output_tokens,omitempty"`
+	TempInputModalities  *string `json:"temp_input_modalities,omitempty"`
+	TempOutputModalities *string `json:"temp_output_modalities,omitempty"`
+	TempSupportsTools    *bool   `json:"temp_supports_tools,omitempty"`
+	TempSupportsJSONMode *bool   `json:"temp_supports_json_mode,omitempty" gorm:"column:temp_supports_json_mode"`
+	TempSupportsVision   *bool   `json:"temp_supports_vision,omitempty"`
+	TempKnowledgeCutoff  *string `json:"temp_knowledge_cutoff,omitempty"`
+	TempSource           *string `json:"temp_source,omitempty"`
+}
+
+// TableName 指定ModelMetadata表名
+func (ModelMetadata) TableName() string {
+	return "model_metadata"
+}
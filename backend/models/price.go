@@ -10,6 +10,8 @@ type Price struct {
 	ID          uint   `json:"id" gorm:"primaryKey"`
 	Model       string `json:"model" gorm:"not null;index:idx_model_channel"`
 	BillingType string `json:"billing_type" gorm:"not null"` // tokens or times
+	BillingUnit string    `json:"billing_unit,omitempty" gorm:"type:varchar(32)"` // 计费单位，为空时由billing_type推断
+	UnitMeta    *UnitMeta `json:"unit_meta,omitempty" gorm:"type:json"`           // 计费单位补充信息
 	ChannelType uint   `json:"channel_type" gorm:"not null;index:idx_model_channel"`
 	Currency    string `json:"currency" gorm:"not null"` // USD or CNY
 	InputPrice        float64        `json:"input_price" gorm:"not null"`
@@ -24,8 +26,13 @@ type Price struct {
 	OutputTextTokens  *float64       `json:"output_text_tokens,omitempty"`  // 输出文本价格
 	InputImageTokens  *float64       `json:"input_image_tokens,omitempty"`  // 输入图片价格
 	OutputImageTokens *float64       `json:"output_image_tokens,omitempty"` // 输出图片价格
+	RequestPrice      *float64       `json:"request_price,omitempty"`       // 每次请求的固定费用，按token计费时额外收取
+	ImagePrice        *float64       `json:"image_price,omitempty"`         // 每张输入图片的价格，按token计费时额外收取
+	Tiers             PriceTiers     `json:"tiers,omitempty" gorm:"type:json"` // 阶梯价格，基础档位为input_price/output_price
+	Modifiers         PriceModifiers `json:"modifiers,omitempty" gorm:"type:json"` // 价格调整规则，如错峰优惠、批量API折扣
 	PriceSource       string         `json:"price_source" gorm:"not null"`
-	Status            string         `json:"status" gorm:"not null;default:pending;index:idx_status"` // pending, approved, rejected
+	Status            string         `json:"status" gorm:"not null;default:pending;index:idx_status"` // pending, approved, rejected, delisted
+	DelistedAt        *time.Time     `json:"delisted_at,omitempty"` // 来源中不再提供该模型时标记下架的时间
 	CreatedAt         time.Time      `json:"created_at" gorm:"autoCreateTime;index:idx_created_at"`
 	UpdatedAt         time.Time      `json:"updated_at" gorm:"autoUpdateTime"`
 	CreatedBy         string         `json:"created_by" gorm:"not null"`
@@ -33,6 +40,8 @@ type Price struct {
 	// 临时字段，用于存储待审核的更新
 	TempModel       *string `json:"temp_model,omitempty" gorm:"column:temp_model"`
 	TempBillingType *string `json:"temp_billing_type,omitempty" gorm:"column:temp_billing_type"`
+	TempBillingUnit *string   `json:"temp_billing_unit,omitempty" gorm:"type:varchar(32)"`
+	TempUnitMeta    *UnitMeta `json:"temp_unit_meta,omitempty" gorm:"type:json"`
 	TempChannelType       *uint    `json:"temp_channel_type,omitempty" gorm:"column:temp_channel_type"`
 	TempCurrency          *string  `json:"temp_currency,omitempty" gorm:"column:temp_currency"`
 	TempInputPrice        *float64 `json:"temp_input_price,omitempty" gorm:"column:temp_input_price"`
@@ -47,6 +56,10 @@ type Price struct {
 	TempOutputTextTokens  *float64 `json:"temp_output_text_tokens,omitempty"`
 	TempInputImageTokens  *float64 `json:"temp_input_image_tokens,omitempty"`
 	TempOutputImageTokens *float64 `json:"temp_output_image_tokens,omitempty"`
+	TempRequestPrice      *float64 `json:"temp_request_price,omitempty"`
+	TempImagePrice        *float64 `json:"temp_image_price,omitempty"`
+	TempTiers             PriceTiers `json:"temp_tiers,omitempty" gorm:"type:json"`
+	TempModifiers         PriceModifiers `json:"temp_modifiers,omitempty" gorm:"type:json"`
 	TempPriceSource       *string  `json:"temp_price_source,omitempty" gorm:"column:temp_price_source"`
 	UpdatedBy             *string  `json:"updated_by,omitempty" gorm:"column:updated_by"`
 }
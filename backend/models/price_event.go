@@ -0,0 +1,66 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"time"
+)
+
+// 价格变更事件类型
+const (
+	PriceEventCreated   = "created"   // 新建价格，待审核的新模型状态为pending
+	PriceEventUpdated   = "updated"   // 已生效的价格被直接修改
+	PriceEventSubmitted = "submitted" // 提交了待审核的修改
+	PriceEventApproved  = "approved"  // 审核通过
+	PriceEventRejected  = "rejected"  // 审核拒绝，新建的价格会被删除
+	PriceEventDelisted  = "delisted"  // 来源中不再提供，标记下架
+	PriceEventRelisted  = "relisted"  // 已下架的模型重新出现在来源中
+	PriceEventDeleted   = "deleted"   // 价格被删除
+)
+
+// PriceEventTypes 所有价格变更事件类型
+var PriceEventTypes = []string{
+	PriceEventCreated,
+	PriceEventUpdated,
+	PriceEventSubmitted,
+	PriceEventApproved,
+	PriceEventRejected,
+	PriceEventDelisted,
+	PriceEventRelisted,
+	PriceEventDeleted,
+}
+
+// PriceEvent 价格变更事件，由价格写入、审核和抓取任务统一产生
+type PriceEvent struct {
+	ID          uint           `json:"id" gorm:"primaryKey"`
+	Type        string         `json:"type" gorm:"not null;type:varchar(16);index"`
+	PriceID     uint           `json:"price_id" gorm:"index"`
+	Model       string         `json:"model" gorm:"type:varchar(191);index"`
+	ChannelType uint           `json:"channel_type" gorm:"index"`
+	Actor       string         `json:"actor"`                               // 操作人，定时任务为 cron自动任务
+	Price       *PriceSnapshot `json:"price" gorm:"type:json"`              // 事件发生后的价格，删除事件为删除前的价格
+	Previous    *PriceSnapshot `json:"previous,omitempty" gorm:"type:json"` // 事件发生前的价格，新建和删除事件为空
+	CreatedAt   time.Time      `json:"created_at" gorm:"autoCreateTime;index"`
+}
+
+// TableName 指定PriceEvent表名
+func (PriceEvent) TableName() string {
+	return "price_event"
+}
+
+// PriceSnapshot 价格快照，以JSON存储
+type PriceSnapshot Price
+
+// Value 实现 driver.Valuer 接口
+func (s PriceSnapshot) Value() (driver.Value, error) {
+	data, err := json.Marshal(s)
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+// Scan 实现 sql.Scanner 接口
+func (s *PriceSnapshot) Scan(value interface{}) error {
+	return scanJSON(value, s)
+}
@@ -0,0 +1,169 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// 价格调整类型
+const (
+	ModifierTimeWindow = "time_window" // 按时间段调整，如错峰优惠
+	ModifierBatch      = "batch"       // 批量API
+	ModifierFlex       = "flex"        // flex服务等级
+	ModifierPriority   = "priority"    // priority服务等级
+)
+
+// 调用模式，realtime为默认的实时调用
+const (
+	ModeRealtime = "realtime"
+	ModeBatch    = ModifierBatch
+	ModeFlex     = ModifierFlex
+	ModePriority = ModifierPriority
+)
+
+// PriceModifier 价格调整规则，在基础价格上按倍数调整输入、输出价格
+type PriceModifier struct {
+	Kind             string  `json:"kind"`               // time_window, batch, flex, priority
+	Start            string  `json:"start,omitempty"`    // 时间段开始，HH:MM，仅time_window
+	End              string  `json:"end,omitempty"`      // 时间段结束，HH:MM，仅time_window，可跨零点
+	Timezone         string  `json:"timezone,omitempty"` // IANA时区，默认UTC
+	InputMultiplier  float64 `json:"input_multiplier"`   // 输入价格倍数，如0.5表示五折
+	OutputMultiplier float64 `json:"output_multiplier"`  // 输出价格倍数
+	Label            string  `json:"label,omitempty"`    // 说明，如"夜间优惠"
+}
+
+// PriceModifiers 价格调整规则列表，以JSON存储
+type PriceModifiers []PriceModifier
+
+// Value 实现 driver.Valuer 接口
+func (m PriceModifiers) Value() (driver.Value, error) {
+	if len(m) == 0 {
+		return nil, nil
+	}
+	data, err := json.Marshal(m)
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+// Scan 实现 sql.Scanner 接口
+func (m *PriceModifiers) Scan(value interface{}) error {
+	if value == nil {
+		*m = nil
+		return nil
+	}
+
+	var data []byte
+	switch v := value.(type) {
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		return fmt.Errorf("无法解析价格调整规则: %T", value)
+	}
+	if len(data) == 0 {
+		*m = nil
+		return nil
+	}
+	return json.Unmarshal(data, m)
+}
+
+// Validate 校验价格调整规则
+func (m PriceModifiers) Validate() error {
+	for _, modifier := range m {
+		switch modifier.Kind {
+		case ModifierTimeWindow:
+			if _, err := parseClock(modifier.Start); err != nil {
+				return fmt.Errorf("时间段开始时间无效: %s", modifier.Start)
+			}
+			if _, err := parseClock(modifier.End); err != nil {
+				return fmt.Errorf("时间段结束时间无效: %s", modifier.End)
+			}
+			if modifier.Start == modifier.End {
+				return fmt.Errorf("时间段开始和结束时间不能相同")
+			}
+			if _, err := modifier.location(); err != nil {
+				return fmt.Errorf("时区无效: %s", modifier.Timezone)
+			}
+		case ModifierBatch, ModifierFlex, ModifierPriority:
+		default:
+			return fmt.Errorf("未知的价格调整类型: %s", modifier.Kind)
+		}
+
+		// 倍数未填写时为0，会被当作免费计算，必须明确指定
+		if modifier.InputMultiplier <= 0 || modifier.OutputMultiplier <= 0 {
+			return fmt.Errorf("价格调整倍数必须大于0")
+		}
+	}
+	return nil
+}
+
+// Equal 判断两组价格调整规则是否相同
+func (m PriceModifiers) Equal(other PriceModifiers) bool {
+	if len(m) == 0 && len(other) == 0 {
+		return true
+	}
+	a, _ := json.Marshal(m)
+	b, _ := json.Marshal(other)
+	return string(a) == string(b)
+}
+
+// Active 返回在指定时间和调用模式下生效的调整规则
+// at为零值时不匹配时间段规则，mode为空或realtime时只匹配时间段规则
+func (m PriceModifiers) Active(at time.Time, mode string) []PriceModifier {
+	var active []PriceModifier
+	for _, modifier := range m {
+		if modifier.Kind == ModifierTimeWindow {
+			if !at.IsZero() && modifier.InWindow(at) {
+				active = append(active, modifier)
+			}
+		} else if modifier.Kind == mode {
+			active = append(active, modifier)
+		}
+	}
+	return active
+}
+
+// InWindow 判断时间是否落在规则的时间段内，时间段为左闭右开
+func (p PriceModifier) InWindow(at time.Time) bool {
+	if p.Kind != ModifierTimeWindow {
+		return false
+	}
+	loc, err := p.location()
+	if err != nil {
+		return false
+	}
+	start, err1 := parseClock(p.Start)
+	end, err2 := parseClock(p.End)
+	if err1 != nil || err2 != nil {
+		return false
+	}
+
+	local := at.In(loc)
+	minute := local.Hour()*60 + local.Minute()
+	if start < end {
+		return minute >= start && minute < end
+	}
+	// 跨零点，如 16:30-00:30
+	return minute >= start || minute < end
+}
+
+func (p PriceModifier) location() (*time.Location, error) {
+	if p.Timezone == "" {
+		return time.UTC, nil
+	}
+	return time.LoadLocation(p.Timezone)
+}
+
+// parseClock 解析HH:MM，返回当天的分钟数
+func parseClock(value string) (int, error) {
+	t, err := time.Parse("15:04", value)
+	if err != nil {
+		return 0, err
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
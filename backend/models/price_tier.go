@@ -0,0 +1,106 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"sort"
+)
+
+// PriceTier 阶梯价格：提示词token数超过阈值时使用该档价格
+type PriceTier struct {
+	AbovePromptTokens int64    `json:"above_prompt_tokens"` // 提示词token数大于该值时生效，如 200000
+	InputPrice        float64  `json:"input_price"`
+	OutputPrice       float64  `json:"output_price"`
+	CachedTokens      *float64 `json:"cached_tokens,omitempty"`
+	CachedReadTokens  *float64 `json:"cached_read_tokens,omitempty"`
+	CachedWriteTokens *float64 `json:"cached_write_tokens,omitempty"`
+}
+
+// PriceTiers 阶梯价格列表，以JSON存储。基础档位即Price的input_price/output_price
+type PriceTiers []PriceTier
+
+// Value 实现 driver.Valuer 接口
+func (t PriceTiers) Value() (driver.Value, error) {
+	if len(t) == 0 {
+		return nil, nil
+	}
+	data, err := json.Marshal(t)
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+// Scan 实现 sql.Scanner 接口
+func (t *PriceTiers) Scan(value interface{}) error {
+	if value == nil {
+		*t = nil
+		return nil
+	}
+
+	var data []byte
+	switch v := value.(type) {
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		return fmt.Errorf("无法解析阶梯价格: %T", value)
+	}
+	if len(data) == 0 {
+		*t = nil
+		return nil
+	}
+	return json.Unmarshal(data, t)
+}
+
+// Validate 校验阶梯价格并按阈值升序排序
+func (t PriceTiers) Validate() error {
+	seen := make(map[int64]bool, len(t))
+	for _, tier := range t {
+		if tier.AbovePromptTokens <= 0 {
+			return fmt.Errorf("阶梯价格阈值必须大于0")
+		}
+		if seen[tier.AbovePromptTokens] {
+			return fmt.Errorf("阶梯价格阈值重复: %d", tier.AbovePromptTokens)
+		}
+		seen[tier.AbovePromptTokens] = true
+
+		if tier.InputPrice < 0 || tier.OutputPrice < 0 {
+			return fmt.Errorf("阶梯价格不能为负数")
+		}
+		for _, p := range []*float64{tier.CachedTokens, tier.CachedReadTokens, tier.CachedWriteTokens} {
+			if p != nil && *p < 0 {
+				return fmt.Errorf("阶梯缓存价格不能为负数")
+			}
+		}
+	}
+
+	sort.Slice(t, func(i, j int) bool {
+		return t[i].AbovePromptTokens < t[j].AbovePromptTokens
+	})
+	return nil
+}
+
+// Equal 判断两组阶梯价格是否相同
+func (t PriceTiers) Equal(other PriceTiers) bool {
+	if len(t) == 0 && len(other) == 0 {
+		return true
+	}
+	a, _ := json.Marshal(t)
+	b, _ := json.Marshal(other)
+	return string(a) == string(b)
+}
+
+// Match 返回提示词token数对应的档位，未超过任何阈值时返回nil（使用基础价格）
+func (t PriceTiers) Match(promptTokens int64) *PriceTier {
+	var matched *PriceTier
+	for i := range t {
+		if promptTokens > t[i].AbovePromptTokens &&
+			(matched == nil || t[i].AbovePromptTokens > matched.AbovePromptTokens) {
+			matched = &t[i]
+		}
+	}
+	return matched
+}
@@ -0,0 +1,28 @@
+package models
+
+import "time"
+
+// 供应商映射状态
+const (
+	ProviderMappingPending  = "pending"  // 新发现的供应商，尚未指定厂商ID
+	ProviderMappingApproved = "approved" // 已映射，抓取该供应商的价格
+	ProviderMappingIgnored  = "ignored"  // 忽略该供应商
+)
+
+// ProviderMapping 聚合来源（如OpenRouter）中的上游供应商名称到厂商ID的映射
+type ProviderMapping struct {
+	ID         uint      `json:"id" gorm:"primaryKey"`
+	Source     string    `json:"source" gorm:"not null;type:varchar(64);uniqueIndex:idx_provider_mapping_source_name"` // 聚合来源，如 openrouter
+	Name       string    `json:"name" gorm:"not null;type:varchar(128);uniqueIndex:idx_provider_mapping_source_name"`  // 来源中的供应商名称，如 Together
+	ProviderID *uint     `json:"provider_id,omitempty"`                                                                // 映射到的厂商ID，未映射时为空
+	Status     string    `json:"status" gorm:"not null;type:varchar(16);default:pending;index"`                        // pending, approved, ignored
+	CreatedAt  time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt  time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+	CreatedBy  string    `json:"created_by" gorm:"not null"`
+	UpdatedBy  *string   `json:"updated_by,omitempty"`
+}
+
+// TableName 指定ProviderMapping表名
+func (ProviderMapping) TableName() string {
+	return "provider_mapping"
+}
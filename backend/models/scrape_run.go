@@ -0,0 +1,40 @@
+package models
+
+import "time"
+
+// 抓取任务执行状态
+const (
+	ScrapeRunRunning = "running"
+	ScrapeRunSuccess = "success"
+	ScrapeRunFailed  = "failed"
+)
+
+// 抓取任务触发方式
+const (
+	ScrapeTriggerCron    = "cron"
+	ScrapeTriggerStartup = "startup"
+	ScrapeTriggerManual  = "manual"
+)
+
+// ScrapeRun 价格抓取任务的一次执行记录
+type ScrapeRun struct {
+	ID          uint       `json:"id" gorm:"primaryKey"`
+	Job         string     `json:"job" gorm:"not null;type:varchar(64);index:idx_scrape_run_job"`
+	Trigger     string     `json:"trigger" gorm:"type:varchar(16)"` // cron/startup/manual
+	TriggeredBy string     `json:"triggered_by,omitempty"`          // 手动触发的用户名
+	Status      string     `json:"status" gorm:"not null;type:varchar(16)"`
+	Error       string     `json:"error,omitempty" gorm:"type:text"`
+	Fetched     int        `json:"fetched"`
+	Created     int        `json:"created"`
+	Updated     int        `json:"updated"`
+	Pending     int        `json:"pending"` // 新建和更新中进入待审核的数量
+	Skipped     int        `json:"skipped"`
+	Delisted    int        `json:"delisted"` // 本次标记下架的价格数
+	StartedAt   time.Time  `json:"started_at" gorm:"index:idx_scrape_run_job"`
+	FinishedAt  *time.Time `json:"finished_at"`
+}
+
+// TableName 指定ScrapeRun表名
+func (ScrapeRun) TableName() string {
+	return "scrape_run"
+}
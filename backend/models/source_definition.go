@@ -0,0 +1,96 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// 自定义价格来源的页面格式
+const (
+	SourceFormatHTML = "html" // 行选择器和列选择器为CSS选择器
+	SourceFormatJSON = "json" // 行选择器和列选择器为JSONPath
+)
+
+// SourceDefinition 管理员配置的价格来源，无需编写代码即可抓取简单的表格页面或JSON接口
+type SourceDefinition struct {
+	ID          uint      `json:"id" gorm:"primaryKey"`
+	Name        string    `json:"name" gorm:"not null;type:varchar(64);uniqueIndex"`       // 来源名称，也是任务名称，不能与内置来源重名
+	Format      string    `json:"format" gorm:"not null;type:varchar(16)"`                 // html, json
+	URL         string    `json:"url" gorm:"not null;type:varchar(512)"`                   // 价格页面或接口地址
+	Headers     StringMap `json:"headers,omitempty" gorm:"type:json"`                      // 请求头，如 Authorization
+	RowSelector string    `json:"row_selector" gorm:"not null;type:varchar(512)"`          // 选取价格行的CSS选择器或JSONPath
+	Columns     StringMap `json:"columns" gorm:"type:json"`                                // 价格字段（如 model、input_price）到行内选择器的映射
+	Multipliers FloatMap  `json:"multipliers,omitempty" gorm:"type:json"`                  // 价格字段的单位换算倍数，* 表示所有价格字段
+	ChannelType uint      `json:"channel_type" gorm:"not null"`                            // 价格写入的厂商ID
+	Currency    string    `json:"currency" gorm:"not null;type:varchar(8);default:USD"`    // USD or CNY
+	BillingUnit string    `json:"billing_unit,omitempty" gorm:"type:varchar(32)"`          // 计费单位，为空时按token计费
+	Approval    string    `json:"approval" gorm:"not null;type:varchar(16);default:known"` // 审核策略：all, known, none
+	Schedule    string    `json:"schedule,omitempty" gorm:"type:varchar(64)"`              // 秒级cron表达式，为空时使用默认计划，off 表示禁用
+	Enabled     bool      `json:"enabled" gorm:"not null"`
+	CreatedAt   time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt   time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+	CreatedBy   string    `json:"created_by" gorm:"not null"`
+	UpdatedBy   *string   `json:"updated_by,omitempty"`
+}
+
+// TableName 指定SourceDefinition表名
+func (SourceDefinition) TableName() string {
+	return "source_definition"
+}
+
+// StringMap 字符串映射，以JSON存储
+type StringMap map[string]string
+
+// Value 实现 driver.Valuer 接口
+func (m StringMap) Value() (driver.Value, error) {
+	return jsonValue(len(m), m)
+}
+
+// Scan 实现 sql.Scanner 接口
+func (m *StringMap) Scan(value interface{}) error {
+	return scanJSON(value, m)
+}
+
+// FloatMap 数值映射，以JSON存储
+type FloatMap map[string]float64
+
+// Value 实现 driver.Valuer 接口
+func (m FloatMap) Value() (driver.Value, error) {
+	return jsonValue(len(m), m)
+}
+
+// Scan 实现 sql.Scanner 接口
+func (m *FloatMap) Scan(value interface{}) error {
+	return scanJSON(value, m)
+}
+
+func jsonValue(size int, v interface{}) (driver.Value, error) {
+	if size == 0 {
+		return nil, nil
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+func scanJSON(value interface{}, dest interface{}) error {
+	var data []byte
+	switch v := value.(type) {
+	case nil:
+		return nil
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		return fmt.Errorf("无法解析JSON字段: %T", value)
+	}
+	if len(data) == 0 {
+		return nil
+	}
+	return json.Unmarshal(data, dest)
+}
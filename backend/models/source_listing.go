@@ -0,0 +1,19 @@
+package models
+
+import "time"
+
+// SourceListing 价格来源中出现过的模型，用于检测上游已下架的模型
+type SourceListing struct {
+	ID          uint       `json:"id" gorm:"primaryKey"`
+	Source      string     `json:"source" gorm:"not null;type:varchar(64);uniqueIndex:idx_listing_source_model"`
+	Model       string     `json:"model" gorm:"not null;type:varchar(191);uniqueIndex:idx_listing_source_model"`
+	ChannelType uint       `json:"channel_type" gorm:"not null"`
+	MissedRuns  int        `json:"missed_runs" gorm:"not null;default:0"` // 连续未出现在成功运行结果中的次数
+	LastSeenAt  time.Time  `json:"last_seen_at"`
+	DelistedAt  *time.Time `json:"delisted_at,omitempty"`
+}
+
+// TableName 指定SourceListing表名
+func (SourceListing) TableName() string {
+	return "source_listing"
+}
@@ -0,0 +1,81 @@
+package models
+
+import (
+	"database/sql/driver"
+	"time"
+)
+
+// Webhook投递状态
+const (
+	WebhookDeliveryPending = "pending" // 等待投递或重试
+	WebhookDeliverySending = "sending" // 正在投递
+	WebhookDeliverySuccess = "success"
+	WebhookDeliveryDead    = "dead" // 重试次数用尽，需手动重新投递
+)
+
+// Webhook 价格变更事件订阅，事件以带HMAC签名的POST请求推送到URL
+type Webhook struct {
+	ID           uint       `json:"id" gorm:"primaryKey"`
+	URL          string     `json:"url" gorm:"not null;type:varchar(512)"`
+	Secret       string     `json:"secret,omitempty" gorm:"not null;type:varchar(128)"` // 签名密钥，只在创建时返回
+	ChannelTypes UintList   `json:"channel_types,omitempty" gorm:"type:json"`           // 只推送这些厂商的事件，为空表示全部
+	Models       StringList `json:"models,omitempty" gorm:"type:json"`                  // 只推送这些模型的事件，支持 gpt-4* 前缀匹配，为空表示全部
+	EventTypes   StringList `json:"event_types,omitempty" gorm:"type:json"`             // 只推送这些类型的事件，为空表示全部
+	Enabled      bool       `json:"enabled" gorm:"not null"`
+	UserID       uint       `json:"user_id" gorm:"not null;index"` // 创建者，普通用户只能管理自己的订阅
+	CreatedAt    time.Time  `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt    time.Time  `json:"updated_at" gorm:"autoUpdateTime"`
+	CreatedBy    string     `json:"created_by" gorm:"not null"`
+}
+
+// TableName 指定Webhook表名
+func (Webhook) TableName() string {
+	return "webhook"
+}
+
+// WebhookDelivery 单个事件到单个订阅的投递记录，重试次数用尽后状态为dead
+type WebhookDelivery struct {
+	ID             uint       `json:"id" gorm:"primaryKey"`
+	WebhookID      uint       `json:"webhook_id" gorm:"not null;uniqueIndex:idx_webhook_delivery_event"`
+	EventID        uint       `json:"event_id" gorm:"not null;uniqueIndex:idx_webhook_delivery_event"`
+	EventType      string     `json:"event_type" gorm:"type:varchar(16)"`
+	Status         string     `json:"status" gorm:"not null;type:varchar(16);index:idx_webhook_delivery_due"`
+	Attempts       int        `json:"attempts"`
+	NextAttemptAt  time.Time  `json:"next_attempt_at" gorm:"index:idx_webhook_delivery_due"`
+	ResponseStatus int        `json:"response_status,omitempty"` // 最近一次投递的HTTP状态码
+	Error          string     `json:"error,omitempty" gorm:"type:text"`
+	DeliveredAt    *time.Time `json:"delivered_at,omitempty"`
+	CreatedAt      time.Time  `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt      time.Time  `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// TableName 指定WebhookDelivery表名
+func (WebhookDelivery) TableName() string {
+	return "webhook_delivery"
+}
+
+// StringList 字符串列表，以JSON存储
+type StringList []string
+
+// Value 实现 driver.Valuer 接口
+func (l StringList) Value() (driver.Value, error) {
+	return jsonValue(len(l), l)
+}
+
+// Scan 实现 sql.Scanner 接口
+func (l *StringList) Scan(value interface{}) error {
+	return scanJSON(value, l)
+}
+
+// UintList 整数列表，以JSON存储
+type UintList []uint
+
+// Value 实现 driver.Valuer 接口
+func (l UintList) Value() (driver.Value, error) {
+	return jsonValue(len(l), l)
+}
+
+// Scan 实现 sql.Scanner 接口
+func (l *UintList) Scan(value interface{}) error {
+	return scanJSON(value, l)
+}
@@ -6,8 +6,10 @@ import (
 	"fmt"
 	"net/http"
 	"os"
+	"strings"
 	"time"
 
+	"aimodels-prices/httpclient"
 	"aimodels-prices/models"
 )
 
@@ -117,6 +119,9 @@ func (f *FeishuWebhook) SendPendingPriceNotification(price models.Price, provide
 	content += fmt.Sprintf("**模型名称：** %s\n", getDisplayModel(price))
 	content += fmt.Sprintf("**厂商：** %s\n", providerName)
 	content += fmt.Sprintf("**计费类型：** %s\n", getBillingTypeText(getDisplayBillingType(price)))
+	if unit := getDisplayBillingUnit(price); unit != "" && unit != models.BillingUnitTokens {
+		content += fmt.Sprintf("**计费单位：** %s\n", unit)
+	}
 	content += fmt.Sprintf("**输入价格：** %.6f %s/1K tokens\n", getDisplayInputPrice(price), getDisplayCurrency(price))
 	content += fmt.Sprintf("**输出价格：** %.6f %s/1K tokens\n", getDisplayOutputPrice(price), getDisplayCurrency(price))
 	content += fmt.Sprintf("**创建者：** %s\n", price.CreatedBy)
@@ -139,6 +144,27 @@ func (f *FeishuWebhook) SendPendingPriceNotification(price models.Price, provide
 		}
 	}
 
+	// 如果有阶梯价格，也显示出来
+	if tiers := getDisplayTiers(price); len(tiers) > 0 {
+		content += "\n\n**阶梯价格：**\n"
+		for _, tier := range tiers {
+			content += fmt.Sprintf("- 提示词 > %d tokens：输入 %.6f / 输出 %.6f %s\n",
+				tier.AbovePromptTokens, tier.InputPrice, tier.OutputPrice, getDisplayCurrency(price))
+		}
+	}
+
+	// 如果有价格调整规则，也显示出来
+	if modifiers := getDisplayModifiers(price); len(modifiers) > 0 {
+		content += "\n\n**价格调整：**\n"
+		for _, modifier := range modifiers {
+			condition := modifier.Kind
+			if modifier.Kind == models.ModifierTimeWindow {
+				condition = fmt.Sprintf("%s-%s %s", modifier.Start, modifier.End, modifier.Timezone)
+			}
+			content += fmt.Sprintf("- %s：输入 ×%g / 输出 ×%g\n", condition, modifier.InputMultiplier, modifier.OutputMultiplier)
+		}
+	}
+
 	card := CardMessage{
 		MsgType: "interactive",
 		Card: Card{
@@ -215,6 +241,53 @@ func (f *FeishuWebhook) SendBatchNotification(count int) error {
 	return f.sendMessage(card)
 }
 
+// SendDelistedNotification 发送模型下架通知
+func (f *FeishuWebhook) SendDelistedNotification(source string, modelNames []string) error {
+	if f == nil || f.URL == "" {
+		return nil
+	}
+
+	var content strings.Builder
+	content.WriteString(fmt.Sprintf("以下 **%d** 个模型已连续多次未出现在 **%s** 的价格数据中，已标记为下架并从倍率导出中移除：\n\n", len(modelNames), source))
+	for _, name := range modelNames {
+		content.WriteString(fmt.Sprintf("- %s\n", name))
+	}
+	content.WriteString("\n如为误判，请在后台重新审核相应价格。")
+
+	card := CardMessage{
+		MsgType: "interactive",
+		Card: Card{
+			Schema: "2.0",
+			Config: CardConfig{
+				UpdateMulti: true,
+			},
+			Header: CardHeader{
+				Title: Title{
+					Tag:     "plain_text",
+					Content: fmt.Sprintf("📦 模型下架通知 - %s", source),
+				},
+				Template: "orange",
+				Padding:  "12px 12px 12px 12px",
+			},
+			Body: CardBody{
+				Direction: "vertical",
+				Padding:   "12px 12px 12px 12px",
+				Elements: []CardElement{
+					{
+						Tag:       "markdown",
+						Content:   content.String(),
+						TextAlign: "left",
+						TextSize:  "normal",
+						Margin:    "0px 0px 0px 0px",
+					},
+				},
+			},
+		},
+	}
+
+	return f.sendMessage(card)
+}
+
 // SendPendingPricesDetailedNotification 发送详细的待审核价格统计通知
 func (f *FeishuWebhook) SendPendingPricesDetailedNotification(content string, count int) error {
 	if f == nil || f.URL == "" {
@@ -262,14 +335,14 @@ func (f *FeishuWebhook) sendMessage(message interface{}) error {
 		return fmt.Errorf("failed to marshal message: %v", err)
 	}
 
-	resp, err := http.Post(f.URL, "application/json", bytes.NewBuffer(jsonData))
+	req, err := http.NewRequest("POST", f.URL, bytes.NewReader(jsonData))
 	if err != nil {
-		return fmt.Errorf("failed to send webhook: %v", err)
+		return fmt.Errorf("failed to create webhook request: %v", err)
 	}
-	defer resp.Body.Close()
+	req.Header.Set("Content-Type", "application/json")
 
-	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("webhook returned status code: %d", resp.StatusCode)
+	if _, err := httpclient.For("feishu").Fetch(req); err != nil {
+		return fmt.Errorf("failed to send webhook: %v", err)
 	}
 
 	return nil
@@ -347,6 +420,30 @@ func getDisplayReasoningTokens(price models.Price) *float64 {
 	return price.ReasoningTokens
 }
 
+// 辅助函数：获取显示用的计费单位
+func getDisplayBillingUnit(price models.Price) string {
+	if price.TempBillingUnit != nil {
+		return *price.TempBillingUnit
+	}
+	return price.BillingUnit
+}
+
+// 辅助函数：获取显示用的阶梯价格
+func getDisplayTiers(price models.Price) models.PriceTiers {
+	if price.TempTiers != nil {
+		return price.TempTiers
+	}
+	return price.Tiers
+}
+
+// 辅助函数：获取显示用的价格调整规则
+func getDisplayModifiers(price models.Price) models.PriceModifiers {
+	if price.TempModifiers != nil {
+		return price.TempModifiers
+	}
+	return price.Modifiers
+}
+
 // 辅助函数：检查是否有扩展价格字段
 func hasExtendedPrices(price models.Price) bool {
 	return getDisplayInputAudioTokens(price) != nil ||
@@ -0,0 +1,230 @@
+package pricing
+
+import (
+	"math"
+	"strings"
+	"time"
+
+	"aimodels-prices/models"
+)
+
+// 价格单位：tokens计费的价格均为每百万token的价格
+const TokensPerUnit = 1000000
+
+// USDToCNYRate 美元兑人民币汇率，与one_hub倍率计算中 USD/2、CNY/14 的换算保持一致
+const USDToCNYRate = 7.0
+
+// Usage 按 models.Price 的价格维度拆分的用量
+type Usage struct {
+	InputTokens       int64 `json:"input_tokens"`        // 文本输入（不含缓存命中部分）
+	OutputTokens      int64 `json:"output_tokens"`       // 文本输出
+	InputAudioTokens  int64 `json:"input_audio_tokens"`  // 音频输入
+	OutputAudioTokens int64 `json:"output_audio_tokens"` // 音频输出
+	InputImageTokens  int64 `json:"input_image_tokens"`  // 图片输入
+	OutputImageTokens int64 `json:"output_image_tokens"` // 图片输出
+	CachedTokens      int64 `json:"cached_tokens"`       // 缓存命中
+	CachedReadTokens  int64 `json:"cached_read_tokens"`  // 缓存读取
+	CachedWriteTokens int64 `json:"cached_write_tokens"` // 缓存写入
+	ReasoningTokens   int64 `json:"reasoning_tokens"`    // 推理
+	Requests          int64 `json:"requests"`            // 请求次数，用于times计费和token计费的每次请求费用
+
+	// 非token计费单位的用量
+	AudioSeconds float64 `json:"audio_seconds"` // 音频秒数
+	Characters   int64   `json:"characters"`    // 字符数
+	Bytes        int64   `json:"bytes"`         // UTF-8字节数
+	Images       int64   `json:"images"`        // 图片张数，用于按张计费和token计费的每张图片费用
+	VideoSeconds float64 `json:"video_seconds"` // 视频秒数
+	PixelSteps   int64   `json:"pixel_steps"`   // 像素数×步数
+}
+
+// unitAmount 返回非token计费单位对应的用量
+func (u Usage) unitAmount(unit string) float64 {
+	switch unit {
+	case models.BillingUnitAudioSecond:
+		return u.AudioSeconds
+	case models.BillingUnitCharacter:
+		return float64(u.Characters)
+	case models.BillingUnitByte:
+		return float64(u.Bytes)
+	case models.BillingUnitImage:
+		return float64(u.Images)
+	case models.BillingUnitVideoSecond:
+		return u.VideoSeconds
+	case models.BillingUnitPixelStep:
+		return float64(u.PixelSteps)
+	}
+	return 0
+}
+
+// PromptTokens 提示词token总数，用于匹配阶梯价格
+func (u Usage) PromptTokens() int64 {
+	return u.InputTokens + u.InputAudioTokens + u.InputImageTokens +
+		u.CachedTokens + u.CachedReadTokens + u.CachedWriteTokens
+}
+
+// Options 计算选项
+type Options struct {
+	Currency string    // 结果货币，USD或CNY，为空时使用价格自身的货币
+	At       time.Time // 计算时间，用于匹配时间段价格调整，为零值时不应用时间段调整
+	Mode     string    // 调用模式，realtime、batch、flex或priority，为空时按realtime计算
+}
+
+// Cost 单个价格的费用计算结果
+type Cost struct {
+	Currency    string             `json:"currency"`
+	Total       float64            `json:"total"`
+	Breakdown   map[string]float64 `json:"breakdown"`
+	AppliedTier *int64             `json:"applied_tier,omitempty"` // 生效的阶梯价格阈值，为空表示基础价格
+	Modifiers   []string           `json:"modifiers,omitempty"`    // 生效的价格调整规则
+}
+
+// 输出侧的费用项，其余费用项按输入侧调整
+var outputKeys = map[string]bool{
+	"output_tokens":       true,
+	"output_audio_tokens": true,
+	"output_image_tokens": true,
+	"reasoning_tokens":    true,
+}
+
+// NormalizeCurrency 规范化货币代码，未知货币按人民币处理（与one_hub倍率计算一致）
+func NormalizeCurrency(currency string) string {
+	if strings.EqualFold(currency, "USD") {
+		return "USD"
+	}
+	return "CNY"
+}
+
+// ConvertCurrency 在USD和CNY之间换算金额
+func ConvertCurrency(amount float64, from, to string) float64 {
+	from = NormalizeCurrency(from)
+	to = NormalizeCurrency(to)
+	if from == to {
+		return amount
+	}
+	if from == "USD" {
+		return amount * USDToCNYRate
+	}
+	return amount / USDToCNYRate
+}
+
+// Calculate 按用量计算价格的费用
+// 扩展价格字段为空时回退到基础输入/输出价格
+func Calculate(price models.Price, usage Usage, opts Options) Cost {
+	currency := opts.Currency
+	if currency == "" {
+		currency = price.Currency
+	}
+	currency = NormalizeCurrency(currency)
+
+	breakdown := make(map[string]float64)
+
+	// 按提示词token数选择阶梯价格
+	var appliedTier *int64
+	if tier := price.Tiers.Match(usage.PromptTokens()); tier != nil {
+		price = applyTier(price, *tier)
+		appliedTier = &tier.AbovePromptTokens
+	}
+
+	switch unit := price.Unit(); unit {
+	case models.BillingUnitRequest:
+		// 按次计费，input_price为单次价格
+		requests := usage.Requests
+		if requests == 0 {
+			requests = 1
+		}
+		breakdown["requests"] = float64(requests) * price.InputPrice
+	case models.BillingUnitTokens:
+		add := func(key string, tokens int64, unitPrice float64) {
+			if tokens == 0 {
+				return
+			}
+			breakdown[key] = float64(tokens) / TokensPerUnit * unitPrice
+		}
+
+		add("input_tokens", usage.InputTokens, firstOf(price.InputPrice, price.InputTextTokens))
+		add("output_tokens", usage.OutputTokens, firstOf(price.OutputPrice, price.OutputTextTokens))
+		add("input_audio_tokens", usage.InputAudioTokens, firstOf(price.InputPrice, price.InputAudioTokens))
+		add("output_audio_tokens", usage.OutputAudioTokens, firstOf(price.OutputPrice, price.OutputAudioTokens))
+		add("input_image_tokens", usage.InputImageTokens, firstOf(price.InputPrice, price.InputImageTokens))
+		add("output_image_tokens", usage.OutputImageTokens, firstOf(price.OutputPrice, price.OutputImageTokens))
+		add("cached_tokens", usage.CachedTokens, firstOf(price.InputPrice, price.CachedTokens, price.CachedReadTokens))
+		add("cached_read_tokens", usage.CachedReadTokens, firstOf(price.InputPrice, price.CachedReadTokens, price.CachedTokens))
+		add("cached_write_tokens", usage.CachedWriteTokens, firstOf(price.InputPrice, price.CachedWriteTokens))
+		add("reasoning_tokens", usage.ReasoningTokens, firstOf(price.OutputPrice, price.ReasoningTokens))
+		if price.RequestPrice != nil && *price.RequestPrice > 0 {
+			requests := usage.Requests
+			if requests == 0 {
+				requests = 1
+			}
+			breakdown["request_price"] = float64(requests) * *price.RequestPrice
+		}
+		if price.ImagePrice != nil && *price.ImagePrice > 0 && usage.Images > 0 {
+			breakdown["image_price"] = float64(usage.Images) * *price.ImagePrice
+		}
+	default:
+		// 其他计费单位，input_price为每 UnitQuantity 个单位的价格
+		if amount := usage.unitAmount(unit); amount > 0 {
+			breakdown[unit] = amount / price.UnitQuantity() * price.InputPrice
+		}
+	}
+
+	// 应用价格调整规则，多个规则同时生效时倍数相乘
+	var applied []string
+	for _, modifier := range price.Modifiers.Active(opts.At, opts.Mode) {
+		for key, amount := range breakdown {
+			if outputKeys[key] {
+				breakdown[key] = amount * modifier.OutputMultiplier
+			} else {
+				breakdown[key] = amount * modifier.InputMultiplier
+			}
+		}
+		name := modifier.Kind
+		if modifier.Label != "" {
+			name = modifier.Label
+		}
+		applied = append(applied, name)
+	}
+
+	cost := Cost{Currency: currency, Breakdown: make(map[string]float64, len(breakdown)), AppliedTier: appliedTier, Modifiers: applied}
+	for key, amount := range breakdown {
+		converted := round(ConvertCurrency(amount, price.Currency, currency))
+		cost.Breakdown[key] = converted
+		cost.Total += converted
+	}
+	cost.Total = round(cost.Total)
+
+	return cost
+}
+
+// applyTier 使用阶梯价格覆盖基础文本价格和缓存价格
+func applyTier(price models.Price, tier models.PriceTier) models.Price {
+	price.InputPrice = tier.InputPrice
+	price.OutputPrice = tier.OutputPrice
+	price.InputTextTokens = nil
+	price.OutputTextTokens = nil
+	if tier.CachedTokens != nil {
+		price.CachedTokens = tier.CachedTokens
+	}
+	if tier.CachedReadTokens != nil {
+		price.CachedReadTokens = tier.CachedReadTokens
+	}
+	if tier.CachedWriteTokens != nil {
+		price.CachedWriteTokens = tier.CachedWriteTokens
+	}
+	return price
+}
+
+// firstOf 返回第一个非空的扩展价格，都为空时返回默认价格
+func firstOf(fallback float64, candidates ...*float64) float64 {
+	for _, c := range candidates {
+		if c != nil {
+			return *c
+		}
+	}
+	return fallback
+}
+
+// round 四舍五入到8位小数，避免浮点数精度问题
+func round(v float64) float64 {
+	return math.Round(v*100000000) / 100000000
+}
@@ -0,0 +1,134 @@
+package pricing
+
+import (
+	"testing"
+	"time"
+
+	"aimodels-prices/models"
+)
+
+func TestCalculate(t *testing.T) {
+	cached := 0.3
+	price := models.Price{
+		Model:            "test-model",
+		BillingType:      "tokens",
+		Currency:         "USD",
+		InputPrice:       3,
+		OutputPrice:      15,
+		CachedReadTokens: &cached,
+	}
+
+	usage := Usage{
+		InputTokens:      1200000,
+		OutputTokens:     300000,
+		CachedReadTokens: 500000,
+	}
+
+	cost := Calculate(price, usage, Options{})
+	// 1.2*3 + 0.3*15 + 0.5*0.3 = 3.6 + 4.5 + 0.15
+	if cost.Total != 8.25 {
+		t.Fatalf("USD总费用错误: got %v, want 8.25", cost.Total)
+	}
+	if cost.Breakdown["cached_read_tokens"] != 0.15 {
+		t.Fatalf("缓存读取费用错误: got %v", cost.Breakdown["cached_read_tokens"])
+	}
+
+	cost = Calculate(price, usage, Options{Currency: "CNY"})
+	if cost.Total != 57.75 {
+		t.Fatalf("CNY总费用错误: got %v, want 57.75", cost.Total)
+	}
+}
+
+func TestCalculateTimes(t *testing.T) {
+	price := models.Price{BillingType: "times", Currency: "CNY", InputPrice: 0.28}
+
+	cost := Calculate(price, Usage{Requests: 10}, Options{})
+	if cost.Total != 2.8 {
+		t.Fatalf("按次计费费用错误: got %v, want 2.8", cost.Total)
+	}
+
+	// token计费附加每次请求费用
+	fee := 0.005
+	price = models.Price{BillingType: "tokens", Currency: "USD", InputPrice: 1, OutputPrice: 2, RequestPrice: &fee}
+	cost = Calculate(price, Usage{InputTokens: 1000000, Requests: 2}, Options{})
+	if cost.Total != 1.01 || cost.Breakdown["request_price"] != 0.01 {
+		t.Fatalf("每次请求费用错误: got %v", cost)
+	}
+
+	// token计费附加每张图片费用
+	imagePrice := 0.0048
+	price = models.Price{BillingType: "tokens", Currency: "USD", InputPrice: 1, OutputPrice: 2, ImagePrice: &imagePrice}
+	cost = Calculate(price, Usage{InputTokens: 1000000, Images: 10}, Options{})
+	if cost.Total != 1.048 || cost.Breakdown["image_price"] != 0.048 {
+		t.Fatalf("每张图片费用错误: got %v", cost)
+	}
+}
+
+func TestCalculateTiers(t *testing.T) {
+	price := models.Price{
+		BillingType: "tokens",
+		Currency:    "USD",
+		InputPrice:  1.25,
+		OutputPrice: 10,
+		Tiers: models.PriceTiers{
+			{AbovePromptTokens: 200000, InputPrice: 2.5, OutputPrice: 15},
+		},
+	}
+
+	cost := Calculate(price, Usage{InputTokens: 100000, OutputTokens: 100000}, Options{})
+	if cost.AppliedTier != nil || cost.Total != 1.125 {
+		t.Fatalf("基础档位费用错误: got %v, tier %v", cost.Total, cost.AppliedTier)
+	}
+
+	cost = Calculate(price, Usage{InputTokens: 400000, OutputTokens: 100000}, Options{})
+	if cost.AppliedTier == nil || *cost.AppliedTier != 200000 || cost.Total != 2.5 {
+		t.Fatalf("长上下文档位费用错误: got %v, tier %v", cost.Total, cost.AppliedTier)
+	}
+}
+
+func TestCalculateModifiers(t *testing.T) {
+	price := models.Price{
+		BillingType: "tokens",
+		Currency:    "CNY",
+		InputPrice:  2,
+		OutputPrice: 8,
+		Modifiers: models.PriceModifiers{
+			{Kind: models.ModifierTimeWindow, Start: "16:30", End: "00:30", InputMultiplier: 0.5, OutputMultiplier: 0.5},
+			{Kind: models.ModifierBatch, InputMultiplier: 0.5, OutputMultiplier: 0.5},
+		},
+	}
+	usage := Usage{InputTokens: 1000000, OutputTokens: 1000000}
+
+	cost := Calculate(price, usage, Options{})
+	if cost.Total != 10 || len(cost.Modifiers) != 0 {
+		t.Fatalf("未指定时间时不应应用调整: got %v, %v", cost.Total, cost.Modifiers)
+	}
+
+	// 跨零点的错峰时段
+	night := time.Date(2025, 1, 1, 0, 10, 0, 0, time.UTC)
+	cost = Calculate(price, usage, Options{At: night})
+	if cost.Total != 5 {
+		t.Fatalf("错峰费用错误: got %v, want 5", cost.Total)
+	}
+
+	day := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	cost = Calculate(price, usage, Options{At: day, Mode: models.ModeBatch})
+	if cost.Total != 5 || len(cost.Modifiers) != 1 {
+		t.Fatalf("批量费用错误: got %v, %v", cost.Total, cost.Modifiers)
+	}
+}
+
+func TestCalculateUnits(t *testing.T) {
+	price := models.Price{
+		BillingType: "times",
+		BillingUnit: models.BillingUnitCharacter,
+		UnitMeta:    &models.UnitMeta{Quantity: 1000000},
+		Currency:    "CNY",
+		InputPrice:  50,
+	}
+
+	cost := Calculate(price, Usage{Characters: 20000}, Options{})
+	if cost.Total != 1 || cost.Breakdown[models.BillingUnitCharacter] != 1 {
+		t.Fatalf("按字符计费费用错误: got %v", cost.Total)
+	}
+}
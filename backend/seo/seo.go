@@ -9,7 +9,8 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
-const baseURL = "https://ai-prices.sunai.net"
+// BaseURL 站点地址，用于生成canonical链接和订阅源链接
+const BaseURL = "https://ai-prices.sunai.net"
 
 type PageMeta struct {
 	Title       string
@@ -76,7 +77,7 @@ func RenderIndex(c *gin.Context, staticDir string) {
 		meta = defaultMeta
 	}
 
-	canonical := baseURL + path
+	canonical := BaseURL + path
 
 	html := tmpl
 	html = strings.ReplaceAll(html, "{{SEO_TITLE}}", meta.Title)
@@ -0,0 +1,361 @@
+package webhooks
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"aimodels-prices/database"
+	"aimodels-prices/events"
+	"aimodels-prices/httpclient"
+	"aimodels-prices/middleware"
+	"aimodels-prices/models"
+)
+
+// MaxAttempts 单个事件最多投递次数，用尽后标记为dead
+const MaxAttempts = 6
+
+// retryDelays 第n次投递失败后的重试间隔
+var retryDelays = []time.Duration{
+	time.Minute,
+	5 * time.Minute,
+	30 * time.Minute,
+	2 * time.Hour,
+	6 * time.Hour,
+}
+
+// sendingTimeout 投递中的记录超过该时间未更新视为实例中断，重新进入待投递
+const sendingTimeout = 10 * time.Minute
+
+// 请求头
+const (
+	HeaderEvent     = "X-Webhook-Event"
+	HeaderDelivery  = "X-Webhook-Delivery"
+	HeaderTimestamp = "X-Webhook-Timestamp"
+	HeaderSignature = "X-Webhook-Signature" // sha256=HMAC-SHA256(secret, timestamp + "." + body)
+)
+
+const cacheKey = "webhooks:enabled"
+
+// Payload 推送的事件内容
+type Payload struct {
+	ID        uint                  `json:"id"` // 事件ID，重试时不变，可用于去重
+	Type      string                `json:"type"`
+	Actor     string                `json:"actor"`
+	Price     *models.PriceSnapshot `json:"price"`
+	Previous  *models.PriceSnapshot `json:"previous,omitempty"`
+	CreatedAt time.Time             `json:"created_at"`
+}
+
+var (
+	clientOnce    sync.Once
+	publicClient  *httpclient.Client // 普通用户的订阅，拒绝连接内网地址
+	privateClient *httpclient.Client // 管理员的订阅，允许推送到内网服务
+	retrying      sync.Mutex
+)
+
+// httpClient 推送使用的客户端，重试由投递记录控制，不在单次请求内重试，也不跟随重定向
+func httpClient(allowPrivate bool) *httpclient.Client {
+	clientOnce.Do(func() {
+		publicClient = newClient(true)
+		privateClient = newClient(false)
+	})
+	if allowPrivate {
+		return privateClient
+	}
+	return publicClient
+}
+
+func newClient(denyPrivate bool) *httpclient.Client {
+	cfg := httpclient.LoadConfig("webhook")
+	cfg.MaxRetries = 0
+	cfg.NoRedirect = true
+	cfg.DenyPrivate = denyPrivate
+	c, err := httpclient.New("webhook", cfg)
+	if err != nil {
+		log.Printf("Webhook HTTP客户端配置错误，忽略代理设置: %v", err)
+		cfg.Proxy = ""
+		c, _ = httpclient.New("webhook", cfg)
+	}
+	return c
+}
+
+// Start 订阅价格变更事件，为匹配的Webhook创建投递记录并立即投递
+func Start() {
+	events.Subscribe(enqueue)
+}
+
+// ClearCache 清除已启用订阅的缓存，订阅修改后调用
+func ClearCache() {
+	database.GlobalCache.Delete(cacheKey)
+}
+
+func enabledWebhooks() ([]models.Webhook, error) {
+	if cached, found := database.GlobalCache.Get(cacheKey); found {
+		if hooks, ok := cached.([]models.Webhook); ok {
+			return hooks, nil
+		}
+	}
+
+	var hooks []models.Webhook
+	if err := database.DB.Where("enabled = ?", true).Find(&hooks).Error; err != nil {
+		return nil, err
+	}
+	database.GlobalCache.Set(cacheKey, hooks, time.Minute)
+	return hooks, nil
+}
+
+func enqueue(event models.PriceEvent) {
+	hooks, err := enabledWebhooks()
+	if err != nil {
+		log.Printf("查询Webhook订阅失败: %v", err)
+		return
+	}
+
+	now := time.Now()
+	var deliveries []models.WebhookDelivery
+	for _, hook := range hooks {
+		if Matches(hook, event) {
+			deliveries = append(deliveries, models.WebhookDelivery{
+				WebhookID:     hook.ID,
+				EventID:       event.ID,
+				EventType:     event.Type,
+				Status:        models.WebhookDeliveryPending,
+				NextAttemptAt: now,
+			})
+		}
+	}
+	if len(deliveries) == 0 {
+		return
+	}
+	if err := database.DB.Create(&deliveries).Error; err != nil {
+		log.Printf("创建Webhook投递记录失败: %v", err)
+		return
+	}
+
+	go func() {
+		for _, delivery := range deliveries {
+			deliver(delivery.ID)
+		}
+	}()
+}
+
+// Matches 判断事件是否符合订阅的过滤条件，未设置的条件视为全部匹配
+func Matches(hook models.Webhook, event models.PriceEvent) bool {
+	return events.Filter{
+		Types:        hook.EventTypes,
+		ChannelTypes: hook.ChannelTypes,
+		Models:       hook.Models,
+	}.Match(event)
+}
+
+// deliver 投递一次，成功后标记为success，失败按退避间隔安排重试，用尽次数后标记为dead
+func deliver(id uint) {
+	db := database.DB
+
+	// 抢占投递，多实例或重试任务同时处理同一记录时只有一个成功
+	claimed := db.Model(&models.WebhookDelivery{}).
+		Where("id = ? AND status = ? AND next_attempt_at <= ?", id, models.WebhookDeliveryPending, time.Now()).
+		Update("status", models.WebhookDeliverySending)
+	if claimed.Error != nil || claimed.RowsAffected == 0 {
+		return
+	}
+
+	var delivery models.WebhookDelivery
+	if err := db.First(&delivery, id).Error; err != nil {
+		return
+	}
+
+	updates := map[string]interface{}{"attempts": delivery.Attempts + 1}
+	statusCode, err := send(delivery)
+	updates["response_status"] = statusCode
+	switch {
+	case err == nil:
+		now := time.Now()
+		updates["status"] = models.WebhookDeliverySuccess
+		updates["error"] = ""
+		updates["delivered_at"] = &now
+	case delivery.Attempts+1 >= MaxAttempts || errors.Is(err, errGone):
+		updates["status"] = models.WebhookDeliveryDead
+		updates["error"] = deliveryError(err)
+		log.Printf("Webhook投递失败，已转入死信 #%d: %v", id, err)
+	default:
+		updates["status"] = models.WebhookDeliveryPending
+		updates["error"] = deliveryError(err)
+		updates["next_attempt_at"] = time.Now().Add(retryDelays[delivery.Attempts])
+	}
+
+	if err := db.Model(&delivery).Updates(updates).Error; err != nil {
+		log.Printf("更新Webhook投递记录失败 #%d: %v", id, err)
+	}
+}
+
+// errGone 订阅或事件已不存在，无需重试
+var errGone = errors.New("订阅已删除或停用")
+
+// deliveryError 返回记录在投递记录中的错误说明
+// 投递记录对订阅者可见，不记录原始的网络错误，避免被用来探测网络中的主机和端口
+func deliveryError(err error) string {
+	var statusErr *httpclient.StatusError
+	var netErr net.Error
+	switch {
+	case errors.Is(err, errGone):
+		return err.Error()
+	case errors.As(err, &statusErr):
+		return statusErr.Error()
+	case errors.Is(err, httpclient.ErrPrivateAddress):
+		return httpclient.ErrPrivateAddress.Error()
+	case errors.As(err, &netErr) && netErr.Timeout():
+		return "请求超时"
+	default:
+		return "请求失败"
+	}
+}
+
+// send 发送事件，返回响应状态码
+func send(delivery models.WebhookDelivery) (int, error) {
+	var hook models.Webhook
+	if err := database.DB.First(&hook, delivery.WebhookID).Error; err != nil || !hook.Enabled {
+		return 0, errGone
+	}
+	var event models.PriceEvent
+	if err := database.DB.First(&event, delivery.EventID).Error; err != nil {
+		return 0, errGone
+	}
+
+	// 只有管理员的订阅可以推送到内网地址，每次连接时按实际解析的IP检查
+	var owner models.User
+	allowPrivate := database.DB.First(&owner, hook.UserID).Error == nil && middleware.IsAdmin(&owner)
+
+	body, err := json.Marshal(Payload{
+		ID:        event.ID,
+		Type:      event.Type,
+		Actor:     event.Actor,
+		Price:     event.Price,
+		Previous:  event.Previous,
+		CreatedAt: event.CreatedAt,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	req, err := http.NewRequest("POST", hook.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderEvent, event.Type)
+	req.Header.Set(HeaderDelivery, strconv.FormatUint(uint64(delivery.ID), 10))
+	req.Header.Set(HeaderTimestamp, timestamp)
+	req.Header.Set(HeaderSignature, "sha256="+Sign(hook.Secret, timestamp, body))
+
+	if _, err := httpClient(allowPrivate).Fetch(req); err != nil {
+		var statusErr *httpclient.StatusError
+		if errors.As(err, &statusErr) {
+			return statusErr.StatusCode, err
+		}
+		return 0, err
+	}
+	return http.StatusOK, nil
+}
+
+// Sign 计算签名：HMAC-SHA256(secret, timestamp + "." + body)，十六进制编码
+func Sign(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// RetryDue 投递到期的重试，并恢复因实例中断停留在投递中的记录，由定时任务在主实例上调用
+func RetryDue() {
+	// 上一次重试尚未结束时跳过
+	if !retrying.TryLock() {
+		return
+	}
+	defer retrying.Unlock()
+
+	db := database.DB
+	if err := db.Model(&models.WebhookDelivery{}).
+		Where("status = ? AND updated_at < ?", models.WebhookDeliverySending, time.Now().Add(-sendingTimeout)).
+		Update("status", models.WebhookDeliveryPending).Error; err != nil {
+		log.Printf("恢复中断的Webhook投递失败: %v", err)
+	}
+
+	var ids []uint
+	if err := db.Model(&models.WebhookDelivery{}).
+		Where("status = ? AND next_attempt_at <= ?", models.WebhookDeliveryPending, time.Now()).
+		Order("id").Limit(100).Pluck("id", &ids).Error; err != nil {
+		log.Printf("查询待重试的Webhook投递失败: %v", err)
+		return
+	}
+	for _, id := range ids {
+		deliver(id)
+	}
+}
+
+// Redeliver 重新投递死信或已成功的记录，重新计算重试次数
+func Redeliver(delivery models.WebhookDelivery) error {
+	if err := database.DB.Model(&delivery).Updates(map[string]interface{}{
+		"status":          models.WebhookDeliveryPending,
+		"attempts":        0,
+		"next_attempt_at": time.Now(),
+	}).Error; err != nil {
+		return err
+	}
+	go deliver(delivery.ID)
+	return nil
+}
+
+// GenerateSecret 生成签名密钥
+func GenerateSecret() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+// ValidateURL 校验推送地址，allowPrivate 为false时拒绝本机和内网地址，防止普通用户探测内网服务
+// 创建时的检查只用于提示，推送时在建立连接时会再次检查
+func ValidateURL(raw string, allowPrivate bool) error {
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return errors.New("URL must be an absolute http(s) URL")
+	}
+	if allowPrivate {
+		return nil
+	}
+
+	host := u.Hostname()
+	if strings.EqualFold(host, "localhost") {
+		return errors.New("URL must not point to a private address")
+	}
+	ips := []net.IP{net.ParseIP(host)}
+	if ips[0] == nil {
+		if ips, err = net.LookupIP(host); err != nil {
+			return fmt.Errorf("failed to resolve host: %s", host)
+		}
+	}
+	for _, ip := range ips {
+		if httpclient.IsPrivateIP(ip) {
+			return errors.New("URL must not point to a private address")
+		}
+	}
+	return nil
+}
@@ -0,0 +1,37 @@
+package webhooks
+
+import (
+	"testing"
+
+	"aimodels-prices/models"
+)
+
+func TestSign(t *testing.T) {
+	// echo -n '1700000000.{"id":1}' | openssl dgst -sha256 -hmac secret
+	got := Sign("secret", "1700000000", []byte(`{"id":1}`))
+	if want := "3dd1b9aef568d75f6790a84bd2e5dfa1f44409eef3cbdbd3f10b837376100c11"; got != want {
+		t.Errorf("签名错误: got %s, want %s", got, want)
+	}
+}
+
+func TestMatches(t *testing.T) {
+	event := models.PriceEvent{Type: models.PriceEventUpdated, Model: "gpt-4o-mini", ChannelType: 1}
+
+	cases := []struct {
+		name string
+		hook models.Webhook
+		want bool
+	}{
+		{"无过滤条件", models.Webhook{}, true},
+		{"事件类型匹配", models.Webhook{EventTypes: models.StringList{"updated", "approved"}}, true},
+		{"事件类型不匹配", models.Webhook{EventTypes: models.StringList{"delisted"}}, false},
+		{"厂商不匹配", models.Webhook{ChannelTypes: models.UintList{14}}, false},
+		{"模型前缀匹配", models.Webhook{ChannelTypes: models.UintList{1}, Models: models.StringList{"gpt-4o*"}}, true},
+		{"模型精确匹配", models.Webhook{Models: models.StringList{"gpt-4o"}}, false},
+	}
+	for _, tc := range cases {
+		if got := Matches(tc.hook, event); got != tc.want {
+			t.Errorf("%s: got %v, want %v", tc.name, got, tc.want)
+		}
+	}
+}